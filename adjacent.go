@@ -0,0 +1,39 @@
+package arena
+
+import (
+	"fmt"
+	"math"
+)
+
+// AllocAdjacent allocates one slice per size, laid out back to back in
+// the order given with no padding in between, so a packed wire format
+// such as a header followed by its payload can be filled in place, sent
+// with a single write of the whole span and parsed from a single base
+// pointer. Only the first slice is pointer-aligned. Each slice's capacity
+// ends where the next begins. The span is one allocation, so it moves to
+// a fresh chunk if the current one cannot hold it. The slice of pieces is
+// allocated from the arena too. Returns nil if sizes is empty; panics if
+// a size is negative.
+func (a *Arena) AllocAdjacent(sizes ...int) [][]byte {
+	if len(sizes) == 0 {
+		return nil
+	}
+	total := 0
+	for _, n := range sizes {
+		if n < 0 {
+			a.panicWithEvents(fmt.Sprintf("arena: AllocAdjacent called with negative size %d", n))
+		}
+		if n > math.MaxInt-total {
+			a.panicWithEvents(fmt.Sprintf("%v: adjacent sizes overflow int", ErrAllocTooLarge))
+		}
+		total += n
+	}
+	span := a.AllocBytes(total)
+	out := allocSpine[[]byte](a, len(sizes))
+	off := 0
+	for i, n := range sizes {
+		out[i] = span[off : off+n : off+n]
+		off += n
+	}
+	return out
+}
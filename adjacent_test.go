@@ -0,0 +1,45 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestAllocAdjacent(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(1000) // leave too little room in the first chunk
+
+	parts := a.AllocAdjacent(3, 0, 100, 5)
+	if len(parts) != 4 {
+		t.Fatalf("len = %d, want 4", len(parts))
+	}
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(parts[0])))
+	off := uintptr(0)
+	for i, want := range []int{3, 0, 100, 5} {
+		p := parts[i]
+		if len(p) != want || cap(p) != want {
+			t.Errorf("part %d has len %d cap %d, want %d", i, len(p), cap(p), want)
+		}
+		if got := uintptr(unsafe.Pointer(unsafe.SliceData(p))); want > 0 && got != base+off {
+			t.Errorf("part %d at offset %d, want %d", i, got-base, off)
+		}
+		off += uintptr(want)
+	}
+	copy(parts[0], "HDR")
+	copy(parts[3], "TAIL!")
+	whole := unsafe.Slice(unsafe.SliceData(parts[0]), 108)
+	if !strings.HasPrefix(string(whole), "HDR") || string(whole[103:]) != "TAIL!" {
+		t.Errorf("span = %q, want the parts back to back", whole)
+	}
+	if a.NumChunks() != 2 {
+		t.Errorf("NumChunks = %d, want the span moved to a new chunk", a.NumChunks())
+	}
+
+	if a.AllocAdjacent() != nil {
+		t.Error("AllocAdjacent() != nil")
+	}
+	if msg := panicMessage(func() { a.AllocAdjacent(4, -1) }); !strings.Contains(msg, "negative size") {
+		t.Errorf("negative size panicked with %q", msg)
+	}
+}
@@ -0,0 +1,67 @@
+package arena
+
+import (
+	"math"
+	"unsafe"
+)
+
+// ptrAlign is the alignment of every allocation: offsets are rounded up to
+// pointer size. No Go type needs more on current platforms, but
+// AllocBytesAligned and Alloc handle any that do.
+const ptrAlign = unsafe.Alignof(uintptr(0))
+
+// AllocBytesAligned is AllocBytes for memory whose address is a multiple of
+// align, which must be a power of two: use it for cache-line (64-byte)
+// aligned buffers, or for SIMD and hardware interfaces with alignment
+// needs beyond the pointer alignment of AllocBytes. The padding in front
+// of the allocation counts towards SizeInUse. Returns nil if n <= 0.
+func (a *Arena) AllocBytesAligned(n, align int) []byte {
+	if align <= 0 || align&(align-1) != 0 {
+		panic("arena: AllocBytesAligned alignment must be a power of two")
+	}
+	if uintptr(align) <= ptrAlign || n <= 0 {
+		return a.AllocBytes(n)
+	}
+	mask := uintptr(align - 1)
+	if c := a.currentChunk; c != nil && a.debug == nil {
+		off := alignPtr(c.offset)
+		pad := -(uintptr(unsafe.Pointer(unsafe.SliceData(c.buf))) + off) & mask
+		if off+pad+uintptr(n) <= uintptr(len(c.buf)) {
+			c.offset = off + pad
+			return a.AllocBytes(n)
+		}
+	}
+	// Over-allocate so an aligned start fits wherever the block lands.
+	if n > math.MaxInt-align {
+		a.panicWithEvents("arena: AllocBytesAligned size overflow")
+	}
+	b := a.AllocBytes(n + align - 1)
+	a.requested -= align - 1 // the slack is padding
+	pad := int(-uintptr(unsafe.Pointer(&b[0])) & mask)
+	return b[pad : pad+n : pad+n]
+}
+
+// AllocAligned returns a pointer to a zeroed T whose address is a multiple
+// of align, a power of two; the alignment of T is used if it is larger.
+// For example AllocAligned[Counters](a, 64) puts a struct of atomics on a
+// cache line of its own to avoid false sharing.
+func AllocAligned[T any](a *Arena, align int) *T {
+	checkPointers[T]()
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	countType[T](a, size)
+	b := a.AllocBytesAligned(max(size, 1), max(align, int(unsafe.Alignof(zero))))
+	if !a.currentChunk.zeroed {
+		clear(b)
+	}
+	return (*T)(unsafe.Pointer(&b[0]))
+}
+
+// allocFor allocates size bytes for values of type T, aligned for T.
+func allocFor[T any](a *Arena, size int) []byte {
+	var zero T
+	if align := unsafe.Alignof(zero); align > ptrAlign {
+		return a.AllocBytesAligned(size, int(align))
+	}
+	return a.AllocBytes(size)
+}
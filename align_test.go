@@ -0,0 +1,110 @@
+package arena
+
+import (
+	"sync/atomic"
+	"testing"
+	"unsafe"
+)
+
+func TestAllocBytesAligned(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"Default", nil},
+		{"Debug", []Option{WithMemoryTags()}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			a := NewArena(512, tc.opts...)
+			defer a.Release()
+			var bufs [][]byte
+			for i := 0; i < 100; i++ {
+				align := 1 << (i % 8) // 1 to 128
+				n := 1 + i*7%200
+				b := a.AllocBytesAligned(n, align)
+				if len(b) != n || cap(b) != n {
+					t.Fatalf("AllocBytesAligned(%d, %d): len=%d cap=%d", n, align, len(b), cap(b))
+				}
+				if p := uintptr(unsafe.Pointer(&b[0])); p%uintptr(max(align, int(ptrAlign))) != 0 {
+					t.Fatalf("AllocBytesAligned(%d, %d) = %#x, misaligned", n, align, p)
+				}
+				for j := range b {
+					b[j] = byte(i)
+				}
+				bufs = append(bufs, b)
+			}
+			for i, b := range bufs {
+				for _, v := range b {
+					if v != byte(i) {
+						t.Fatalf("allocation %d overwritten", i)
+					}
+				}
+			}
+
+			// Larger than a chunk, and a page-aligned request
+			for _, align := range []int{64, 4096} {
+				b := a.AllocBytesAligned(1000, align)
+				if p := uintptr(unsafe.Pointer(&b[0])); p%uintptr(align) != 0 {
+					t.Errorf("AllocBytesAligned(1000, %d) = %#x, misaligned", align, p)
+				}
+			}
+		})
+	}
+}
+
+func TestAllocBytesAlignedInvalid(t *testing.T) {
+	a := NewArena(512)
+	defer a.Release()
+	if b := a.AllocBytesAligned(0, 64); b != nil {
+		t.Errorf("AllocBytesAligned(0, 64) = %v, want nil", b)
+	}
+	for _, align := range []int{0, -8, 48} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("AllocBytesAligned(8, %d) did not panic", align)
+				}
+			}()
+			a.AllocBytesAligned(8, align)
+		}()
+	}
+}
+
+type cacheLineCounters struct {
+	hits, misses atomic.Uint64
+}
+
+func TestAllocAligned(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+	for i := 0; i < 20; i++ {
+		a.AllocBytes(1 + i) // stray allocations between the aligned ones
+		c := AllocAligned[cacheLineCounters](a, 64)
+		if p := uintptr(unsafe.Pointer(c)); p%64 != 0 {
+			t.Fatalf("AllocAligned(64) = %#x, not cache-line aligned", p)
+		}
+		if c.hits.Load() != 0 || c.misses.Load() != 0 {
+			t.Fatal("AllocAligned returned non-zero memory")
+		}
+		c.hits.Add(1)
+	}
+
+	// The alignment of T is the minimum.
+	p := AllocAligned[uint64](a, 1)
+	if uintptr(unsafe.Pointer(p))%unsafe.Alignof(uint64(0)) != 0 {
+		t.Errorf("AllocAligned[uint64](1) = %p, misaligned", p)
+	}
+
+	// Memory reused after a reset is zeroed again.
+	a.Reset()
+	for i, b := 0, a.AllocBytes(900); i < len(b); i++ {
+		b[i] = 0xff
+	}
+	a.Reset()
+	for i := 0; i < 10; i++ {
+		c := AllocAligned[cacheLineCounters](a, 64)
+		if c.hits.Load() != 0 || c.misses.Load() != 0 {
+			t.Fatal("AllocAligned returned non-zero memory after Reset")
+		}
+	}
+}
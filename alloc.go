@@ -1,18 +1,28 @@
 package arena
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"runtime"
 	"unsafe"
 )
 
+// ErrAllocTooLarge is returned by AllocSliceE when the requested size
+// overflows int or exceeds the arena's WithMaxAllocSize limit.
+var ErrAllocTooLarge = errors.New("arena: allocation too large")
+
 // Alloc returns a pointer to a T stored inside the arena with zeroed memory.
 // The returned pointer is valid as long as the arena hasn't been released.
+// It is aligned for T; see AllocAligned for stricter alignment.
 func Alloc[T any](a *Arena) *T {
+	checkPointers[T]()
 	var zero T
 	size := int(unsafe.Sizeof(zero))
-	b := a.AllocBytes(size)
-	// Zero the memory
-	if len(b) > 0 {
+	countType[T](a, size)
+	b := allocFor[T](a, size)
+	// Zero the memory unless the chunk is known to be clean
+	if len(b) > 0 && !a.currentChunk.zeroed {
 		clear(b)
 	}
 	return (*T)(unsafe.Pointer(&b[0]))
@@ -27,38 +37,84 @@ func AllocZeroed[T any](a *Arena) *T {
 // This is faster than Alloc but the memory contents are undefined.
 // Use with caution - ensure proper initialization before use.
 func AllocUninitialized[T any](a *Arena) *T {
+	checkPointers[T]()
 	var zero T
 	size := int(unsafe.Sizeof(zero))
-	b := a.AllocBytes(size)
+	countType[T](a, size)
+	b := allocFor[T](a, size)
 	return (*T)(unsafe.Pointer(&b[0]))
 }
 
 // AllocSlice allocates a slice of n elements of type T inside the arena.
 // The slice elements are not initialized (contain garbage data).
-// Returns nil if n <= 0.
+// Returns nil if n <= 0, or an empty slice if n == 0 and WithEmptySlices is set.
 func AllocSlice[T any](a *Arena, n int) []T {
 	if n <= 0 {
-		return nil
+		return emptySlice[T](a, n)
+	}
+	checkPointers[T]()
+	total, err := sliceSize[T](a, n)
+	if err != nil {
+		a.panicWithEvents(err.Error())
+	}
+	countType[T](a, total)
+	b := allocFor[T](a, total)
+	return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), n)
+}
+
+// AllocSliceE is AllocSlice for sizes derived from untrusted input: if n
+// elements of T would overflow int or exceed the WithMaxAllocSize limit,
+// it returns an error wrapping ErrAllocTooLarge instead of panicking.
+// Returns nil, nil if n <= 0, or an empty slice if n == 0 and
+// WithEmptySlices is set.
+func AllocSliceE[T any](a *Arena, n int) ([]T, error) {
+	if n <= 0 {
+		return emptySlice[T](a, n), nil
+	}
+	if _, err := sliceSize[T](a, n); err != nil {
+		return nil, err
 	}
+	return AllocSlice[T](a, n), nil
+}
+
+// sliceSize returns the bytes needed for n values of T, or an error if
+// they overflow int or exceed the arena's allocation size limit.
+func sliceSize[T any](a *Arena, n int) (int, error) {
 	var zero T
 	elemSize := int(unsafe.Sizeof(zero))
+	if elemSize > 0 && n > math.MaxInt/elemSize {
+		return 0, fmt.Errorf("%w: %d elements of %d bytes overflow int", ErrAllocTooLarge, n, elemSize)
+	}
 	total := elemSize * n
-	b := a.AllocBytes(total)
-	return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), n)
+	if total > a.maxAlloc {
+		return 0, fmt.Errorf("%w: %d bytes exceeds the limit of %d", ErrAllocTooLarge, total, a.maxAlloc)
+	}
+	return total, nil
+}
+
+// allocTooLarge panics for an n-byte allocation over the size limit.
+//
+//go:noinline
+func (a *Arena) allocTooLarge(n int) {
+	a.panicWithEvents(fmt.Sprintf("%v: %d bytes exceeds the limit of %d", ErrAllocTooLarge, n, a.maxAlloc))
 }
 
 // AllocSliceZeroed allocates a slice of n elements of type T with zeroed memory.
 // This is slower than AllocSlice but ensures clean initialization.
+// Zero-length requests are handled as in AllocSlice.
 func AllocSliceZeroed[T any](a *Arena, n int) []T {
 	if n <= 0 {
-		return nil
+		return emptySlice[T](a, n)
 	}
-	var zero T
-	elemSize := int(unsafe.Sizeof(zero))
-	total := elemSize * n
-	b := a.AllocBytes(total)
-	// Zero the memory
-	if len(b) > 0 {
+	checkPointers[T]()
+	total, err := sliceSize[T](a, n)
+	if err != nil {
+		a.panicWithEvents(err.Error())
+	}
+	countType[T](a, total)
+	b := allocFor[T](a, total)
+	// Zero the memory unless the chunk is known to be clean
+	if len(b) > 0 && !a.currentChunk.zeroed {
 		clear(b)
 	}
 	return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), n)
@@ -1,7 +1,10 @@
 package arena
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"testing"
 	"unsafe"
 )
@@ -199,3 +202,39 @@ func BenchmarkAllocSlice(b *testing.B) {
 		})
 	}
 }
+
+func TestAllocSliceOverflow(t *testing.T) {
+	a := NewArena(1024)
+	n := math.MaxInt/8 + 2 // 8*n wraps to a small positive size
+	if msg := panicMessage(func() { AllocSlice[int64](a, n) }); !strings.Contains(msg, "overflow") {
+		t.Errorf("AllocSlice(overflowing n) panic = %q, want an overflow panic", msg)
+	}
+	if msg := panicMessage(func() { AllocSliceZeroed[int64](a, n) }); !strings.Contains(msg, "overflow") {
+		t.Errorf("AllocSliceZeroed(overflowing n) panic = %q, want an overflow panic", msg)
+	}
+	if s, err := AllocSliceE[int64](a, n); s != nil || !errors.Is(err, ErrAllocTooLarge) {
+		t.Errorf("AllocSliceE(overflowing n) = %v, %v, want ErrAllocTooLarge", s, err)
+	}
+	if s, err := AllocSliceE[int64](a, 4); len(s) != 4 || err != nil {
+		t.Errorf("AllocSliceE(4) = len %d, %v, want 4, nil", len(s), err)
+	}
+}
+
+func TestWithMaxAllocSize(t *testing.T) {
+	a := NewArena(1024, WithMaxAllocSize(100))
+	if b := a.AllocBytes(100); len(b) != 100 {
+		t.Fatalf("AllocBytes(100) len = %d at the limit", len(b))
+	}
+	if msg := panicMessage(func() { a.AllocBytes(101) }); !strings.Contains(msg, "exceeds the limit of 100") {
+		t.Errorf("AllocBytes(101) panic = %q, want a limit panic", msg)
+	}
+	if msg := panicMessage(func() { AllocSlice[int32](a, 26) }); !strings.Contains(msg, "exceeds the limit") {
+		t.Errorf("AllocSlice(26 int32) panic = %q, want a limit panic", msg)
+	}
+	if _, err := AllocSliceE[int32](a, 26); !errors.Is(err, ErrAllocTooLarge) {
+		t.Errorf("AllocSliceE(26 int32) error = %v, want ErrAllocTooLarge", err)
+	}
+	if msg := panicMessage(func() { a.In(Transient).AllocBytes(200) }); !strings.Contains(msg, "exceeds the limit") {
+		t.Errorf("class arena AllocBytes(200) panic = %q, want the limit inherited", msg)
+	}
+}
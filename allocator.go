@@ -0,0 +1,53 @@
+package arena
+
+// Allocator is the byte-allocation interface shared by Arena and SafeArena.
+// Libraries that only need raw memory and lifecycle control should accept an
+// Allocator so callers can pass either arena type, or a test double.
+type Allocator interface {
+	AllocBytes(n int) []byte
+	Reset()
+	Release()
+}
+
+// SyncAllocator is an Allocator that is safe for concurrent use, such as
+// SafeArena. Code that shares an allocator between goroutines should
+// accept a SyncAllocator rather than an Allocator: a plain Arena does not
+// implement it, so passing one is a compile error instead of a silent data
+// race. Other allocators opt in by embedding ConcurrencySafe.
+type SyncAllocator interface {
+	Allocator
+	syncAllocator()
+}
+
+// UnsyncAllocator is an Allocator that must be used by one goroutine at a
+// time, such as Arena. Code that relies on that, for example by keeping
+// memory across calls without synchronization, can accept an
+// UnsyncAllocator so a SafeArena is not passed in by mistake. Other
+// allocators opt in by embedding SingleOwner.
+type UnsyncAllocator interface {
+	Allocator
+	unsyncAllocator()
+}
+
+// ConcurrencySafe is embedded in an Allocator implementation to declare it
+// safe for concurrent use, making it a SyncAllocator.
+type ConcurrencySafe struct{}
+
+func (ConcurrencySafe) syncAllocator() {}
+
+// SingleOwner is embedded in an Allocator implementation to declare it for
+// use by one goroutine at a time, making it an UnsyncAllocator.
+type SingleOwner struct{}
+
+func (SingleOwner) unsyncAllocator() {}
+
+func (a *Arena) unsyncAllocator() {}
+
+func (s *SafeArena) syncAllocator() {}
+
+var (
+	_ Allocator       = (*Arena)(nil)
+	_ Allocator       = (*SafeArena)(nil)
+	_ UnsyncAllocator = (*Arena)(nil)
+	_ SyncAllocator   = (*SafeArena)(nil)
+)
@@ -0,0 +1,36 @@
+package arena
+
+import "testing"
+
+func TestAllocatorConcurrencyModes(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+	s := NewSafeArena(1024)
+	defer s.Release()
+
+	for _, tt := range []struct {
+		name       string
+		al         Allocator
+		sync, sole bool
+	}{
+		{"Arena", a, false, true},
+		{"SafeArena", s, true, false},
+		{"SizeRouter", NewSizeRouter(0, a, a), false, false},
+	} {
+		_, sync := tt.al.(SyncAllocator)
+		_, sole := tt.al.(UnsyncAllocator)
+		if sync != tt.sync || sole != tt.sole {
+			t.Errorf("%s: SyncAllocator %v, UnsyncAllocator %v, want %v and %v", tt.name, sync, sole, tt.sync, tt.sole)
+		}
+	}
+
+	// Embedding a marker opts other allocators in.
+	var custom struct {
+		ConcurrencySafe
+		Allocator
+	}
+	custom.Allocator = NewSizeRouter(0, s, s)
+	if _, ok := Allocator(custom).(SyncAllocator); !ok {
+		t.Error("allocator embedding ConcurrencySafe is not a SyncAllocator")
+	}
+}
@@ -0,0 +1,34 @@
+package arena
+
+import "unsafe"
+
+// AllocMeta describes where an allocation was placed inside the arena.
+type AllocMeta struct {
+	Chunk  int // Index of the chunk holding the allocation
+	Offset int // Byte offset of the allocation within the chunk
+	Padded int // Alignment padding inserted before the allocation
+}
+
+// AllocBytesMeta is like AllocBytes but also reports the allocation's
+// placement, so allocators layered on top can account exactly for padding
+// overhead. Returns a nil slice and zero AllocMeta if n <= 0.
+func (a *Arena) AllocBytesMeta(n int) ([]byte, AllocMeta) {
+	prev := a.currentChunk
+	var prevOff uintptr
+	if prev != nil {
+		prevOff = prev.offset
+	}
+
+	b := a.AllocBytes(n)
+	if b == nil {
+		return nil, AllocMeta{}
+	}
+
+	c := a.currentChunk
+	start := uintptr(unsafe.Pointer(&b[0])) - uintptr(unsafe.Pointer(&c.buf[0]))
+	meta := AllocMeta{Chunk: a.chunkIndex(c), Offset: int(start)}
+	if c == prev {
+		meta.Padded = int(start - prevOff)
+	}
+	return b, meta
+}
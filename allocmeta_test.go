@@ -0,0 +1,27 @@
+package arena
+
+import "testing"
+
+func TestAllocBytesMeta(t *testing.T) {
+	a := NewArena(64)
+
+	_, m := a.AllocBytesMeta(3)
+	if m != (AllocMeta{Chunk: 0, Offset: 0, Padded: 0}) {
+		t.Errorf("first AllocBytesMeta(3) = %+v, want zero placement", m)
+	}
+
+	_, m = a.AllocBytesMeta(8)
+	if m.Chunk != 0 || m.Offset != 8 || m.Padded != 5 {
+		t.Errorf("AllocBytesMeta(8) = %+v, want {Chunk:0 Offset:8 Padded:5}", m)
+	}
+
+	// Overflowing into a new chunk starts at offset 0 without padding
+	b, m := a.AllocBytesMeta(100)
+	if len(b) != 100 || m != (AllocMeta{Chunk: 1, Offset: 0, Padded: 0}) {
+		t.Errorf("AllocBytesMeta(100) = %+v, want {Chunk:1 Offset:0 Padded:0}", m)
+	}
+
+	if b, m := a.AllocBytesMeta(0); b != nil || m != (AllocMeta{}) {
+		t.Errorf("AllocBytesMeta(0) = %v, %+v; want nil, zero", b, m)
+	}
+}
@@ -3,15 +3,22 @@
 // objects from it, then Reset() at the end of the request for O(1) cleanup.
 package arena
 
-import "unsafe"
-
-// DefaultChunkSize is the default chunk size for new arenas (64 KiB).
-const DefaultChunkSize = 1 << 16
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"unsafe"
+)
 
 // chunk represents a single memory chunk within an arena.
 type chunk struct {
-	buf    []byte  // backing memory
-	offset uintptr // allocation offset within buf
+	buf      []byte  // backing memory
+	offset   uintptr // allocation offset within buf
+	lastUsed uint64  // arena generation in which the chunk last held data
+	zeroed   bool    // bytes from offset on are known to be zero
+	poisoned uintptr // bytes below this were poisoned by a reset (WithPoisoning)
+	hits     uint64  // sampled cycles that used the chunk, newest in bit 0 (WithAccessSampling)
 }
 
 // Arena is a chunked bump allocator. Not goroutine-safe by default.
@@ -20,125 +27,421 @@ type Arena struct {
 	chunks       []chunk
 	chunkSize    int
 	currentChunk *chunk
+	debug        *debugState // nil unless debugging features are enabled
+	retired      []chunk     // chunks awaiting ResetCommit
+	spare        []chunk     // recycled chunks reused by grow
+	generation   uint64      // incremented whenever handed-out memory is invalidated
+	interned     map[any]unsafe.Pointer
+	internGen    uint64     // incremented whenever interned values are dropped
+	growth       float64    // chunk size multiplier; <= 1 keeps chunks at chunkSize
+	growthFunc   GrowthFunc // set by WithGrowthFunc; overrides growth
+	minChunkSize int        // lower bound for policy-sized chunks; 0 for none
+	maxChunkSize int        // upper bound for policy-sized chunks; 0 for none
+	nextChunk    int        // size of the next policy-sized chunk
+	template     []byte     // copied to the start of the first chunk on Reset
+	hooks        []resetHook
+	hookSeq      uint64
+	name         string                 // set by WithName; recorded in memory tags
+	chunkBase    uintptr                // bytes reserved at the start of each chunk (memory tags)
+	zeroer       *zeroer                // set by WithBackgroundZeroing
+	maxChunks    int                    // cap on chunks held, including spares; 0 for none
+	lazy         bool                   // set by WithLazyInit; first chunk added on first use
+	emptySlices  bool                   // set by WithEmptySlices
+	classes      [numClasses - 1]*Arena // Transient and SessionScoped chunk sets
+	allocs       uint64                 // allocations served since creation
+	cycleAllocs  uint64                 // allocs when the current cycle began
+	requested    int                    // bytes requested by live allocations this cycle
+	largest      int                    // largest allocation since creation
+	peak         int                    // highest SizeInUse seen at a cycle end, rollback or Metrics
+	retiredBytes uint64                 // bytes used by cycles that ended (see TotalBytes)
+	resets       uint64                 // completed Reset and ResetCommit calls
+	grows        uint64                 // chunks added
+	trims        uint64                 // trims run, for metrics
+	trimmedBytes uint64                 // chunk bytes freed by trims
+	poolTrim     *poolTrim              // last ArenaPool.Trim applied
+	budget       *budget                // set by WithBudget or inherited; nil for none
+	budgetHeld   int64                  // chunk bytes charged to budget by this arena
+	stableAddrs  bool                   // set by WithStableAddresses
+	pinFunc      PinFunc                // set by WithPinFunc; nil for mlock
+	pins         map[*byte]int          // pin count by chunk data pointer
+	pinned       []chunk                // pinned chunks set aside by a reset
+	rt           *prefetcher            // set by WithRealtime
+	retainSet    bool                   // set by WithRetention; Reset shrinks to retainBytes
+	retainBytes  int
+	gov          *governorHold  // chunk bytes charged to the governor; nil if none
+	warm         *cycleHistory  // set by WithWarmStart
+	leak         *leakCheck     // set by WithLeakDetection or DebugFull
+	sampler      *accessSampler // set by WithAccessSampling
+	allocHook    *allocHook     // set by WithAllocBudgetHook
+	allocHookAt  uint64         // allocs value at which allocHook runs next; 0 for none
+	maxAlloc     int            // largest single allocation; set by WithMaxAllocSize
+	maps         *chunkMaps     // set by WithMmap
+	origin       *cloneOrigin   // set for arenas made by Clone
+	parent       *Arena         // set for arenas created by Child
+	children     []*Arena       // live arenas created by Child
+	watch        *watchdog      // set by WatchContext; checked before growing
 }
 
 // NewArena creates a new Arena with the specified chunk size.
 // If chunkSize <= 0, DefaultChunkSize is used.
-func NewArena(chunkSize int) *Arena {
+func NewArena(chunkSize int, opts ...Option) *Arena {
+	a := newArena(chunkSize, opts)
+	a.addFirstChunk()
+	a.armLeakCheck(1)
+	return a
+}
+
+// ErrChunkUnavailable is returned by NewArenaE and ArenaPool.GetE when the
+// arena's first chunk would exceed a configured cap.
+var ErrChunkUnavailable = errors.New("arena: chunk unavailable")
+
+// NewArenaE is NewArena for libraries that must propagate construction
+// failures: if the first chunk cannot be added because of a WithBudget
+// cap, a chunk limit or the governor, it returns an error wrapping
+// ErrChunkUnavailable instead of panicking. Arenas created with
+// WithLazyInit add no chunk here and never fail. The runtime itself still
+// aborts the process if it runs out of memory.
+func NewArenaE(chunkSize int, opts ...Option) (*Arena, error) {
+	a := newArena(chunkSize, opts)
+	if err := a.tryAddFirstChunk(); err != nil {
+		a.Release()
+		return nil, err
+	}
+	a.armLeakCheck(1)
+	return a, nil
+}
+
+// newArena creates an arena with opts applied but no chunk yet.
+func newArena(chunkSize int, opts []Option) *Arena {
 	if chunkSize <= 0 {
 		chunkSize = DefaultChunkSize
 	}
 	a := &Arena{chunkSize: chunkSize}
-	a.grow(chunkSize)
+	if compactBuild {
+		WithCompactProfile(0, 0)(a)
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	if a.maxAlloc <= 0 {
+		a.maxAlloc = math.MaxInt
+	}
+	a.nextChunk = a.clampChunkSize(chunkSize)
+	a.applyDebugLevel(DebugLevelCurrent())
+	if a.rt != nil {
+		a.growth, a.growthFunc = 0, nil
+		a.rt.start(a.nextChunk + int(a.chunkBase))
+	}
+	return a
+}
+
+// addFirstChunk adds the arena's first chunk, or only marks the arena live
+// under WithLazyInit.
+func (a *Arena) addFirstChunk() {
+	if a.lazy {
+		a.chunks = []chunk{} // non-nil: the arena is live, not released
+	} else {
+		a.grow(a.nextChunk)
+	}
 	if len(a.chunks) > 0 {
 		a.currentChunk = &a.chunks[len(a.chunks)-1]
 	}
-	return a
+}
+
+// tryAddFirstChunk is addFirstChunk returning the arena's capacity panics
+// as errors.
+func (a *Arena) tryAddFirstChunk() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg, ok := r.(string)
+			if !ok {
+				panic(r)
+			}
+			a.chunks = []chunk{} // let Release run on the failed arena
+			err = fmt.Errorf("%w: %s", ErrChunkUnavailable, strings.TrimPrefix(msg, "arena: "))
+		}
+	}()
+	a.addFirstChunk()
+	return nil
+}
+
+// NewArenaFor creates an Arena whose chunk size fits countHint values of T.
+// Each value's size is rounded up to pointer alignment, as Alloc does, and
+// 1/8 slack is added so a full cycle fits in one chunk. If countHint <= 0 or
+// T has zero size, DefaultChunkSize is used.
+func NewArenaFor[T any](countHint int, opts ...Option) *Arena {
+	var zero T
+	elemSize := int(alignPtr(unsafe.Sizeof(zero)))
+	if countHint <= 0 || elemSize == 0 {
+		return NewArena(DefaultChunkSize, opts...)
+	}
+	if countHint > math.MaxInt/elemSize/2 {
+		panic("arena: NewArenaFor size overflow")
+	}
+	size := elemSize * countHint
+	return NewArena(size+size/8, opts...)
 }
 
 // AllocBytes returns a []byte slice pointing into the arena's backing chunk.
 // The caller must ensure the arena remains reachable while the returned slice is in use.
-// Returns nil if n <= 0.
+// Returns nil if n <= 0, or an empty slice if n == 0 and WithEmptySlices is set.
 func (a *Arena) AllocBytes(n int) []byte {
-	if n <= 0 {
-		return nil
+	if n > a.maxAlloc {
+		a.allocTooLarge(n)
+	}
+	if b := a.bump(n); b != nil {
+		a.countAlloc(n)
+		return b
 	}
+	return a.allocBytesSlow(n)
+}
 
-	// Fast path: use cached current chunk
-	c := a.currentChunk
-	if c != nil {
-		// Align offset
-		const align = unsafe.Sizeof(uintptr(0))
-		mask := align - 1
-		off := (c.offset + mask) & ^mask
-
-		// Check if we have space
-		if off+uintptr(n) <= uintptr(len(c.buf)) {
-			start := int(off)
-			c.offset = off + uintptr(n)
+// bump is the allocation fast path: an aligned bump within the current
+// chunk. It is a call-free leaf kept under the compiler's inlining budget
+// (guarded by TestBumpInlinable) and returns nil whenever the slow path
+// must take over. Callers count the allocation with countAlloc. AllocBytes
+// itself is over the default budget but inlines into hot call sites under
+// PGO (guarded by TestAllocBytesInlinable).
+func (a *Arena) bump(n int) []byte {
+	if c := a.currentChunk; c != nil && n > 0 && a.debug == nil {
+		off := alignPtr(c.offset)
+		if end := off + uintptr(n); end <= uintptr(len(c.buf)) {
+			c.offset = end
 			// Use unsafe slice creation to avoid bounds checks
-			return unsafe.Slice((*byte)(unsafe.Pointer(&c.buf[start])), n)
+			return unsafe.Slice((*byte)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(c.buf)), off)), n)
 		}
 	}
-
-	// Slow path: need new chunk
-	return a.allocBytesSlow(n)
+	return nil
 }
 
-// allocBytesSlow handles allocation when fast path fails
+// allocBytesSlow handles everything the inlined fast path does not:
+// non-positive sizes, diagnostics, released arenas and chunk growth.
+//
+//go:noinline
 func (a *Arena) allocBytesSlow(n int) []byte {
-	// Check if arena is released
-	if a.chunks == nil {
-		panic("arena: use after Release()")
+	if n <= 0 {
+		return emptySlice[byte](a, n)
+	}
+	if a.debug != nil {
+		return a.allocBytesDebug(n)
 	}
+	// Check if arena is released
+	a.panicIfReleased()
 
-	a.grow(n)
-	a.currentChunk = &a.chunks[len(a.chunks)-1]
+	a.advanceChunk(n)
+	a.countAlloc(n)
 
-	// Allocate from new chunk
+	// Allocate from the next chunk with room
 	c := a.currentChunk
-	const align = unsafe.Sizeof(uintptr(0))
-	mask := align - 1
-	off := (c.offset + mask) & ^mask
-
-	start := int(off)
+	off := alignPtr(c.offset)
 	c.offset = off + uintptr(n)
-	return unsafe.Slice((*byte)(unsafe.Pointer(&c.buf[start])), n)
+	return unsafe.Slice((*byte)(unsafe.Pointer(&c.buf[off])), n)
 }
 
 // EnsureCapacity ensures the current chunk has at least n free bytes.
 // If not, it grows the arena with a new chunk.
+// New code should prefer Grow, which reports the resulting capacity.
 func (a *Arena) EnsureCapacity(n int) {
 	a.panicIfReleased()
-	ci := len(a.chunks) - 1
-	if ci < 0 {
-		a.grow(n)
-		return
+	c := a.currentChunk
+	if c == nil || alignPtr(c.offset)+uintptr(n) > uintptr(len(c.buf)) {
+		a.advanceChunk(n)
 	}
-	c := &a.chunks[ci]
-	off := alignPtr(c.offset)
-	if uintptr(n)+off > uintptr(len(c.buf)) {
-		a.grow(n)
+}
+
+// Grow guarantees that the next allocation of up to n bytes is served without
+// adding a chunk. If the chunk currently being filled lacks room, Grow moves
+// on to the next chunk kept from an earlier cycle that has room, or grows the
+// arena by one chunk of at least n bytes. Like bytes.Buffer.Grow, it returns
+// the resulting total capacity in bytes. Panics if n < 0.
+func (a *Arena) Grow(n int) int {
+	a.panicIfReleased()
+	if n < 0 {
+		panic("arena: Grow called with negative count")
 	}
+	c := a.currentChunk
+	if c == nil || alignPtr(c.offset)+uintptr(n) > uintptr(len(c.buf)) {
+		a.advanceChunk(n)
+	}
+	return a.Capacity()
 }
 
 // Reset resets allocation offsets to zero but keeps allocated chunks for reuse.
 // This provides O(1) cleanup for arena reuse.
 func (a *Arena) Reset() {
-	if a.chunks == nil {
-		panic("arena: use after Release()")
+	a.panicIfReleased()
+	a.retiredBytes += uint64(a.usedBytes(a.chunks))
+	a.recordCycle()
+	a.endCycleMetrics()
+	a.sampleAccess()
+	if a.pins != nil {
+		a.holdPinnedChunks()
 	}
-	for i := range a.chunks {
-		a.chunks[i].offset = 0
+	if a.debug != nil {
+		a.debugReset()
+	}
+	a.resets++
+	a.resetClassArena(Transient)
+	if a.zeroer != nil {
+		a.retireForZeroing()
+	} else {
+		for i := range a.chunks {
+			c := &a.chunks[i]
+			if c.offset > a.chunkBase {
+				c.lastUsed = a.generation
+				c.zeroed = false
+				if a.maps != nil {
+					a.maps.discard(c.buf, int(c.offset))
+				}
+			}
+			c.offset = a.chunkBase
+		}
 	}
 	// Reset cached chunk to first chunk
 	if len(a.chunks) > 0 {
 		a.currentChunk = &a.chunks[0]
 	}
+	if a.template != nil {
+		a.applyTemplate()
+	}
+	a.invalidate()
+	if a.debug != nil && a.debug.tags {
+		a.writeChunkTags()
+	}
+	a.recordEvent(OpReset, 0, a.currentChunk, 0)
+	if a.retainSet {
+		a.shrinkAfterReset(a.retainBytes)
+	}
 }
 
 // Release drops all chunks and makes the arena unusable.
 // Any subsequent operations will panic.
 func (a *Arena) Release() {
+	if a.pins != nil && a.chunks != nil {
+		a.chunks = a.holdPinned(a.chunks)
+	}
+	if a.debug != nil && a.chunks != nil {
+		a.debugReset()
+	}
+	a.recordEvent(OpRelease, 0, nil, 0)
+	a.disarmLeakCheck()
+	for len(a.children) > 0 {
+		a.children[len(a.children)-1].Release()
+	}
+	if a.parent != nil {
+		a.returnChunks()
+	}
+	for _, ca := range a.classes {
+		if ca != nil {
+			ca.Release()
+		}
+	}
+	a.classes = [numClasses - 1]*Arena{}
+	a.invalidate()
+	if a.zeroer != nil {
+		a.zeroer.release()
+	}
+	if a.rt != nil {
+		a.rt.release()
+		a.rt = nil
+	}
+	a.unmapReleased()
 	a.chunks = nil
 	a.currentChunk = nil
+	a.retired = nil
+	a.spare = nil
+	a.pinned = nil
+	a.hooks = nil
+	if a.budget != nil {
+		a.budget.refund(a.budgetHeld)
+		a.budgetHeld = 0
+	}
+	if a.gov != nil {
+		a.gov.refundAll()
+	}
 }
 
-// grow appends a new chunk of at least min bytes.
+// grow appends a chunk of at least min bytes, reusing a spare chunk if one
+// is large enough.
 func (a *Arena) grow(min int) {
-	size := a.chunkSize
-	if min > size {
-		size = min
+	if min > math.MaxInt-int(a.chunkBase) {
+		panic("arena: allocation size overflow")
+	}
+	size := a.nextChunk
+	if need := min + int(a.chunkBase); need > size {
+		size = need
+	}
+	a.advanceChunkSize()
+	if c, ok := a.takeSpare(size); ok {
+		a.chunks = append(a.chunks, c)
+	} else if c, ok := a.borrowChunk(size); ok {
+		a.chunks = append(a.chunks, c)
+	} else if a.rt != nil && a.grows > 0 {
+		buf, ok := a.takePrefetched(size)
+		if !ok {
+			a.panicWithEvents("arena: no pre-provisioned chunk available")
+		}
+		a.chunks = append(a.chunks, chunk{buf: buf, zeroed: true})
+	} else {
+		if a.maxChunks > 0 && len(a.chunks)+len(a.retired)+len(a.spare)+len(a.pinned) >= a.maxChunks {
+			a.panicWithEvents("arena: chunk limit reached")
+		}
+		a.chunks = append(a.chunks, chunk{buf: a.newChunkBuf(size), zeroed: true})
 	}
-	buf := make([]byte, size)
-	a.chunks = append(a.chunks, chunk{buf: buf, offset: 0})
 	a.currentChunk = &a.chunks[len(a.chunks)-1]
+	a.currentChunk.offset = a.chunkBase
+	a.currentChunk.lastUsed = a.generation
+	a.grows++
+	if a.debug != nil && a.debug.tags {
+		a.writeChunkTag(len(a.chunks) - 1)
+	}
+	a.recordEvent(OpGrow, size, a.currentChunk, 0)
+}
+
+// advanceChunk makes the first chunk after the current one with room for
+// n bytes current, so chunks kept across Reset are refilled in order, and
+// grows the arena if none has room.
+func (a *Arena) advanceChunk(n int) {
+	for i := a.chunkIndex(a.currentChunk) + 1; i < len(a.chunks); i++ {
+		c := &a.chunks[i]
+		if alignPtr(c.offset)+uintptr(n) <= uintptr(len(c.buf)) {
+			a.currentChunk = c
+			return
+		}
+	}
+	if w := a.watch; w != nil && w.expired.Load() {
+		w.release()
+		a.panicWithEvents("arena: released by WatchContext after its context deadline")
+	}
+	a.grow(n)
+}
+
+// takeSpare removes and returns a spare chunk of at least size bytes.
+// Under WithBackgroundZeroing it waits for chunks still being cleared
+// before giving up.
+func (a *Arena) takeSpare(size int) (chunk, bool) {
+	for wait := false; ; wait = true {
+		more := false
+		if a.zeroer != nil {
+			a.spare, more = a.zeroer.collect(a.spare, wait)
+		}
+		for i := len(a.spare) - 1; i >= 0; i-- {
+			if len(a.spare[i].buf) >= size {
+				c := a.spare[i]
+				a.spare = append(a.spare[:i], a.spare[i+1:]...)
+				return c, true
+			}
+		}
+		if !more {
+			return chunk{}, false
+		}
+	}
 }
 
 // panicIfReleased panics if the arena has been released.
 func (a *Arena) panicIfReleased() {
 	if a.chunks == nil {
-		panic("arena: use after Release()")
+		a.panicWithEvents("arena: use after Release()")
 	}
 }
 
@@ -1,7 +1,12 @@
 package arena
 
 import (
+	"errors"
 	"fmt"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 	"unsafe"
 )
@@ -30,6 +35,64 @@ func TestNewArena(t *testing.T) {
 	}
 }
 
+func TestNewArenaFor(t *testing.T) {
+	type record struct {
+		id    int64
+		flags uint8
+	}
+
+	a := NewArenaFor[record](100)
+	for i := 0; i < 100; i++ {
+		Alloc[record](a)
+	}
+	if a.NumChunks() != 1 {
+		t.Errorf("NewArenaFor[record](100) needed %d chunks for 100 records, want 1", a.NumChunks())
+	}
+	if a.ChunkSize() < 100*16 {
+		t.Errorf("NewArenaFor[record](100) chunk size = %d, want >= %d", a.ChunkSize(), 100*16)
+	}
+
+	if got := NewArenaFor[record](0).ChunkSize(); got != DefaultChunkSize {
+		t.Errorf("NewArenaFor[record](0) chunk size = %d, want %d", got, DefaultChunkSize)
+	}
+	if got := NewArenaFor[struct{}](10).ChunkSize(); got != DefaultChunkSize {
+		t.Errorf("NewArenaFor[struct{}](10) chunk size = %d, want %d", got, DefaultChunkSize)
+	}
+}
+
+func TestNewArenaE(t *testing.T) {
+	a, err := NewArenaE(1024)
+	if err != nil || a.NumChunks() != 1 {
+		t.Fatalf("NewArenaE(1024) = %v, %v; want an arena with one chunk", a, err)
+	}
+	a.Release()
+
+	parent := NewArena(1024, WithBudget(1500))
+	defer parent.Release()
+	if _, err := NewArenaE(1024, WithBudget(512)); !errors.Is(err, ErrChunkUnavailable) {
+		t.Errorf("NewArenaE over its own budget: error = %v, want ErrChunkUnavailable", err)
+	}
+	_, err = NewArenaE(1024, func(c *Arena) { c.budget = &budget{parent: parent.budget} })
+	if !errors.Is(err, ErrChunkUnavailable) || !strings.Contains(err.Error(), "budget exceeded") {
+		t.Errorf("NewArenaE over an ancestor's budget: error = %v, want ErrChunkUnavailable giving the cause", err)
+	}
+	// The failed arena must not keep any of the budget charged.
+	if n := parent.RemainingBudget(); n != 1500-1024 {
+		t.Errorf("parent RemainingBudget = %d after a failed NewArenaE, want %d", n, 1500-1024)
+	}
+
+	if a, err := NewArenaE(1024, WithBudget(512), WithLazyInit()); err != nil {
+		t.Errorf("NewArenaE with WithLazyInit: %v", err)
+	} else {
+		a.Release()
+	}
+
+	pool := NewArenaPool(1024, WithBudget(512))
+	if _, err := pool.GetE(); !errors.Is(err, ErrChunkUnavailable) {
+		t.Errorf("ArenaPool.GetE over budget: error = %v, want ErrChunkUnavailable", err)
+	}
+}
+
 func TestArenaAllocBytes(t *testing.T) {
 	a := NewArena(1024)
 
@@ -78,6 +141,37 @@ func TestArenaEnsureCapacity(t *testing.T) {
 	}
 }
 
+func TestArenaGrow(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(1000)
+
+	// Room left in the current chunk: capacity unchanged
+	if got := a.Grow(16); got != 1024 {
+		t.Errorf("Grow(16) = %d, want 1024", got)
+	}
+
+	// Not enough room: a new chunk is added and the allocation fits in it
+	if got := a.Grow(500); got != 2048 {
+		t.Errorf("Grow(500) = %d, want 2048", got)
+	}
+	a.AllocBytes(500)
+	if a.NumChunks() != 2 {
+		t.Errorf("NumChunks after Grow(500)+AllocBytes(500) = %d, want 2", a.NumChunks())
+	}
+
+	// Oversized requests get a dedicated chunk
+	if got := a.Grow(4096); got != 2048+4096 {
+		t.Errorf("Grow(4096) = %d, want %d", got, 2048+4096)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on Grow(-1)")
+		}
+	}()
+	a.Grow(-1)
+}
+
 func TestArenaReset(t *testing.T) {
 	a := NewArena(1024)
 
@@ -142,6 +236,81 @@ func TestAlignPtr(t *testing.T) {
 	}
 }
 
+func TestBumpInlinable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiler invocation in short mode")
+	}
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	out, err := exec.Command(goTool, "build", "-gcflags=-m", ".").CombinedOutput()
+	if err != nil {
+		t.Skipf("go build failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "can inline (*Arena).bump") {
+		t.Errorf("(*Arena).bump is no longer inlinable; keep the fast path a small leaf")
+	}
+	if !strings.Contains(string(out), "can inline (*Arena).countAlloc") {
+		t.Errorf("(*Arena).countAlloc is no longer inlinable; it runs on every allocation")
+	}
+}
+
+// TestAllocBytesInlinable checks that AllocBytes inlines into hot call
+// sites under profile-guided optimization. Its slow-path calls put it over
+// the default inlining budget, so it relies on PGO, which raises the budget
+// for hot functions; bump and countAlloc inline everywhere.
+func TestAllocBytesInlinable(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping compiler invocation in short mode")
+	}
+	goTool := filepath.Join(runtime.GOROOT(), "bin", "go")
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "cpu.pprof")
+	out, err := exec.Command(goTool, "test", "-run=^$", "-bench=^BenchmarkArenaAllocBytes$", "-benchtime=100ms",
+		"-cpuprofile="+profile, "-o="+filepath.Join(dir, "arena.test"), ".").CombinedOutput()
+	if err != nil {
+		t.Skipf("profiling AllocBytes failed: %v\n%s", err, out)
+	}
+	// Pass the profile to this package's compilation only; -pgo would
+	// rebuild the standard library with it too.
+	out, err = exec.Command(goTool, "build", "-gcflags=-m -pgoprofile="+profile, ".").CombinedOutput()
+	if err != nil {
+		t.Skipf("go build failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), "can inline (*Arena).AllocBytes\n") {
+		t.Errorf("(*Arena).AllocBytes is not inlinable with a profile; keep the slow path out of line")
+	}
+}
+
+func TestAllocBytesFastPathAllocs(t *testing.T) {
+	a := NewArena(1024 * 1024)
+	allocs := testing.AllocsPerRun(1000, func() {
+		a.AllocBytes(64)
+		if a.SizeInUse() > 512*1024 {
+			a.Reset()
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("AllocBytes allocs/op = %v, want 0", allocs)
+	}
+}
+
+// BenchmarkAllocBytesFastPath fails if the fast path exceeds its time
+// budget. The budget is checked here rather than in a test since wall-clock
+// timings are too noisy for every test run.
+func BenchmarkAllocBytesFastPath(b *testing.B) {
+	const budget = 50 // ns/op; generous to tolerate noisy machines
+	a := NewArena(1024 * 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		a.AllocBytes(64)
+		if i%1000 == 999 {
+			a.Reset()
+		}
+	}
+	if ns := b.Elapsed().Nanoseconds() / int64(b.N); b.N >= 1000 && ns > budget {
+		b.Errorf("AllocBytes = %d ns/op, budget %d ns/op", ns, budget)
+	}
+}
+
 func BenchmarkArenaAllocBytes(b *testing.B) {
 	a := NewArena(1024 * 1024) // 1MB chunks
 	sizes := []int{8, 64, 256, 1024}
@@ -0,0 +1,44 @@
+/*
+ * C API for Go arenas (package github.com/pavanmanishd/arena/arenacgo).
+ *
+ * Memory returned by arena_alloc is pointer-aligned and stays valid until
+ * the next arena_reset or arena_release of the same arena. An arena must
+ * not be used from two threads at once.
+ */
+#ifndef ARENA_H
+#define ARENA_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#define ARENA_ABI_VERSION 1
+
+/* Opaque arena handle; 0 is never a valid handle. */
+typedef uintptr_t arena_t;
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* Creates an arena. chunk_size 0 selects the default chunk size. */
+arena_t arena_new(size_t chunk_size);
+
+/* Allocates n bytes. Returns NULL if n is 0. */
+void *arena_alloc(arena_t a, size_t n);
+
+/* Invalidates all allocations and keeps the chunks for reuse. */
+void arena_reset(arena_t a);
+
+/* Releases an arena from arena_new, or ends C's access to an arena shared
+ * by Go. The handle is invalid afterwards. */
+void arena_release(arena_t a);
+
+/* Returns the ABI version of the linked library; compare it with
+ * ARENA_ABI_VERSION. */
+int arena_abi_version(void);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* ARENA_H */
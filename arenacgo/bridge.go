@@ -0,0 +1,34 @@
+//go:build cgo
+
+package arenacgo
+
+// #include <stddef.h>
+// #include <stdint.h>
+import "C"
+
+import "unsafe"
+
+//export arena_new
+func arena_new(chunkSize C.size_t) C.uintptr_t {
+	return C.uintptr_t(newArena(int(chunkSize)))
+}
+
+//export arena_alloc
+func arena_alloc(h C.uintptr_t, n C.size_t) unsafe.Pointer {
+	return alloc(uintptr(h), int(n))
+}
+
+//export arena_reset
+func arena_reset(h C.uintptr_t) {
+	reset(uintptr(h))
+}
+
+//export arena_release
+func arena_release(h C.uintptr_t) {
+	release(uintptr(h))
+}
+
+//export arena_abi_version
+func arena_abi_version() C.int {
+	return ABIVersion
+}
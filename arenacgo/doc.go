@@ -0,0 +1,28 @@
+// Package arenacgo lets C, C++ and Rust code running in the same process
+// allocate from Go arenas, so a request keeps a single memory lifetime
+// across the FFI boundary.
+//
+// The C API is declared in arena.h:
+//
+//	arena_t arena_new(size_t chunk_size);
+//	void   *arena_alloc(arena_t a, size_t n);
+//	void    arena_reset(arena_t a);
+//	void    arena_release(arena_t a);
+//	int     arena_abi_version(void);
+//
+// Arenas are referred to by opaque handles, never by Go pointers. Go code
+// hands an existing arena to C with Export and takes it back with
+// Unexport. Chunks that C allocations come from are pinned (see
+// runtime.Pinner) until the handle is released, so C may hold pointers
+// into them across calls; the usual arena rules still apply, and memory
+// obtained from arena_alloc is invalid after arena_reset or
+// arena_release.
+//
+// Arenas are not goroutine-safe: Go and C must not use the same arena at
+// the same time.
+//
+// Link the bridge into a Go program by importing this package, or build a
+// standalone library with go build -buildmode=c-shared (or c-archive) from
+// a main package that imports it. The C API requires cgo; without cgo only
+// the Go side of the package is available.
+package arenacgo
@@ -0,0 +1,100 @@
+package arenacgo
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// ABIVersion is the version of the C API declared in arena.h. It changes
+// only when an existing function changes its signature or semantics.
+const ABIVersion = 1
+
+// bridged is an arena reachable from C.
+type bridged struct {
+	a      *arena.Arena
+	owned  bool // created by arena_new; released by arena_release
+	pinner runtime.Pinner
+	pinned map[uintptr]bool // base addresses of pinned chunks
+}
+
+var (
+	handles    sync.Map // uintptr -> *bridged
+	nextHandle atomic.Uintptr
+)
+
+// Export makes a available to C and returns the handle to pass to C code.
+// The arena stays owned by Go: arena_release from C, or Unexport from Go,
+// ends C's access without releasing the arena.
+func Export(a *arena.Arena) uintptr {
+	return register(a, false)
+}
+
+// Unexport ends C's access to the arena behind h and unpins its chunks.
+// It must be called before a is released, once C no longer uses it.
+func Unexport(h uintptr) {
+	if b := take(h); b != nil {
+		b.pinner.Unpin()
+	}
+}
+
+func register(a *arena.Arena, owned bool) uintptr {
+	h := nextHandle.Add(1)
+	handles.Store(h, &bridged{a: a, owned: owned, pinned: make(map[uintptr]bool)})
+	return h
+}
+
+func lookup(h uintptr) *bridged {
+	if v, ok := handles.Load(h); ok {
+		return v.(*bridged)
+	}
+	panic("arenacgo: invalid arena handle")
+}
+
+func take(h uintptr) *bridged {
+	if v, ok := handles.LoadAndDelete(h); ok {
+		return v.(*bridged)
+	}
+	return nil
+}
+
+// newArena implements arena_new.
+func newArena(chunkSize int) uintptr {
+	return register(arena.NewArena(chunkSize), true)
+}
+
+// alloc implements arena_alloc: it allocates n bytes and pins the chunk
+// they come from the first time it serves C.
+func alloc(h uintptr, n int) unsafe.Pointer {
+	b := lookup(h)
+	buf, meta := b.a.AllocBytesMeta(n)
+	if buf == nil {
+		return nil
+	}
+	p := unsafe.Pointer(&buf[0])
+	if base := uintptr(p) - uintptr(meta.Offset); !b.pinned[base] {
+		b.pinner.Pin(p)
+		b.pinned[base] = true
+	}
+	return p
+}
+
+// reset implements arena_reset. Chunks stay pinned for reuse.
+func reset(h uintptr) {
+	lookup(h).a.Reset()
+}
+
+// release implements arena_release.
+func release(h uintptr) {
+	b := take(h)
+	if b == nil {
+		return
+	}
+	b.pinner.Unpin()
+	if b.owned {
+		b.a.Release()
+	}
+}
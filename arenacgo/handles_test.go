@@ -0,0 +1,55 @@
+package arenacgo
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+func TestBridgeLifecycle(t *testing.T) {
+	h := newArena(1024)
+	p := alloc(h, 16)
+	if p == nil {
+		t.Fatal("alloc returned nil")
+	}
+	copy(unsafe.Slice((*byte)(p), 16), "from C")
+	for i := 0; i < 10; i++ {
+		alloc(h, 500)
+	}
+	if got := len(lookup(h).pinned); got != lookup(h).a.NumChunks() {
+		t.Errorf("%d chunks pinned, want %d", got, lookup(h).a.NumChunks())
+	}
+	if alloc(h, 0) != nil {
+		t.Error("alloc(0) != nil")
+	}
+
+	reset(h)
+	release(h)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic on use of a released handle")
+			}
+		}()
+		alloc(h, 8)
+	}()
+}
+
+func TestExport(t *testing.T) {
+	a := arena.NewArena(1024)
+	h := Export(a)
+	alloc(h, 32)
+	release(h) // ends C's access only
+	if a.SizeInUse() == 0 {
+		t.Error("release of an exported handle reset the Go arena")
+	}
+	a.AllocBytes(8) // still usable from Go
+
+	h = Export(a)
+	Unexport(h)
+	if _, ok := handles.Load(h); ok {
+		t.Error("Unexport kept the handle")
+	}
+	a.Release()
+}
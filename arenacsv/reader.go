@@ -0,0 +1,219 @@
+// Package arenacsv reads CSV and TSV records into an arena. Each record's
+// field slice and unescaped field strings are allocated from the arena
+// rather than the Go heap, and ReadBatch reuses the arena for every batch
+// of rows, so ETL jobs stop paying encoding/csv's per-record allocations.
+package arenacsv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// ErrFieldCount is wrapped in a *csv.ParseError when a record has the wrong
+// number of fields. It is the same error encoding/csv reports.
+var ErrFieldCount = csv.ErrFieldCount
+
+var errInvalidDelim = errors.New("arenacsv: invalid field delimiter")
+
+// Reader reads records from CSV or TSV input into an arena. Quoting follows
+// RFC 4180 as in encoding/csv: fields may be enclosed in double quotes, a
+// doubled quote inside a quoted field stands for one quote, and quoted
+// fields may span lines. Empty lines are skipped and a trailing \r is
+// dropped from every line. Syntax errors are reported as *csv.ParseError.
+type Reader struct {
+	// Comma is the field delimiter, ',' by default. Set it to '\t' for TSV.
+	Comma byte
+	// FieldsPerRecord is the number of fields expected per record, with
+	// the semantics of csv.Reader.FieldsPerRecord: if 0 it is set by the
+	// first record, if negative records may vary in length.
+	FieldsPerRecord int
+
+	a       *arena.Arena
+	r       *bufio.Reader
+	line    int    // lines read so far
+	lineBuf []byte // line assembled across bufio buffer refills
+	field   []byte // unescaped fields of the record being parsed
+	ends    []int  // end offset of each field in field
+}
+
+// NewReader returns a Reader allocating records from a.
+func NewReader(a *arena.Arena, r io.Reader) *Reader {
+	return &Reader{Comma: ',', a: a, r: bufio.NewReader(r)}
+}
+
+// Read reads one record. The record and its fields live in the arena and
+// are only valid until it is reset or released. At end of input Read
+// returns nil, io.EOF.
+func (r *Reader) Read() ([]string, error) {
+	if r.Comma == '"' || r.Comma == '\r' || r.Comma == '\n' {
+		return nil, errInvalidDelim
+	}
+	if err := r.parseRecord(); err != nil {
+		return nil, err
+	}
+	if r.FieldsPerRecord > 0 && len(r.ends) != r.FieldsPerRecord {
+		return nil, &csv.ParseError{StartLine: r.line, Line: r.line, Column: 1, Err: ErrFieldCount}
+	}
+	if r.FieldsPerRecord == 0 {
+		r.FieldsPerRecord = len(r.ends)
+	}
+	return r.record(), nil
+}
+
+// ReadBatch resets the arena and reads up to n records into it, so memory
+// is reused from one batch to the next. The batch is only valid until the
+// next ReadBatch; the arena should be dedicated to the Reader. It returns
+// the records read before an error or end of input together with that
+// error, and nil, io.EOF once the input is exhausted.
+func (r *Reader) ReadBatch(n int) ([][]string, error) {
+	r.a.Reset()
+	if n <= 0 {
+		return nil, nil
+	}
+	batch := allocSlice[[]string](r.a, n)[:0]
+	for len(batch) < n {
+		rec, err := r.Read()
+		if err == io.EOF && len(batch) > 0 {
+			return batch, nil
+		}
+		if err != nil {
+			return batch, err
+		}
+		batch = append(batch, rec)
+	}
+	return batch, nil
+}
+
+// parseRecord reads the next non-empty record, leaving its unescaped
+// fields in r.field and r.ends.
+func (r *Reader) parseRecord() error {
+	line, err := r.readLine()
+	for err == nil && len(line) == 0 {
+		line, err = r.readLine()
+	}
+	if err != nil {
+		return err
+	}
+	start := r.line
+	full := line
+	r.field = r.field[:0]
+	r.ends = r.ends[:0]
+	parseErr := func(err error) error {
+		return &csv.ParseError{StartLine: start, Line: r.line, Column: len(full) - len(line) + 1, Err: err}
+	}
+	for {
+		if len(line) == 0 || line[0] != '"' {
+			i := bytes.IndexByte(line, r.Comma)
+			f := line
+			if i >= 0 {
+				f = line[:i]
+			}
+			if j := bytes.IndexByte(f, '"'); j >= 0 {
+				line = line[j:]
+				return parseErr(csv.ErrBareQuote)
+			}
+			r.field = append(r.field, f...)
+			r.ends = append(r.ends, len(r.field))
+			if i < 0 {
+				return nil
+			}
+			line = line[i+1:]
+			continue
+		}
+
+		line = line[1:]
+		for {
+			i := bytes.IndexByte(line, '"')
+			if i < 0 {
+				// The quoted field continues on the next line.
+				r.field = append(r.field, line...)
+				r.field = append(r.field, '\n')
+				if line, err = r.readLine(); err == io.EOF {
+					return parseErr(csv.ErrQuote)
+				} else if err != nil {
+					return err
+				}
+				full = line
+				continue
+			}
+			r.field = append(r.field, line[:i]...)
+			line = line[i+1:]
+			if len(line) > 0 && line[0] == '"' {
+				r.field = append(r.field, '"')
+				line = line[1:]
+				continue
+			}
+			break
+		}
+		r.ends = append(r.ends, len(r.field))
+		switch {
+		case len(line) == 0:
+			return nil
+		case line[0] == r.Comma:
+			line = line[1:]
+		default:
+			return parseErr(csv.ErrQuote)
+		}
+	}
+}
+
+// record copies the parsed fields into the arena as a single block and
+// returns the arena-resident record.
+func (r *Reader) record() []string {
+	rec := allocSlice[string](r.a, len(r.ends))
+	if len(r.field) == 0 {
+		return rec // all fields empty
+	}
+	data := r.a.AllocBytes(len(r.field))
+	copy(data, r.field)
+	prev := 0
+	for i, end := range r.ends {
+		if end > prev {
+			rec[i] = unsafe.String(&data[prev], end-prev)
+		}
+		prev = end
+	}
+	return rec
+}
+
+// allocSlice returns a zeroed slice of n elements allocated from a. Like
+// arena.Queue nodes, records only point into the same arena, so they are
+// allocated as raw bytes rather than subjected to the PointerPolicy.
+func allocSlice[T any](a *arena.Arena, n int) []T {
+	if n == 0 {
+		return nil
+	}
+	var zero T
+	b := a.AllocBytes(n * int(unsafe.Sizeof(zero)))
+	clear(b)
+	return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), n)
+}
+
+// readLine returns the next line without its line terminator. The line is
+// only valid until the next call. The last line need not end in \n.
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadSlice('\n')
+	if err == bufio.ErrBufferFull {
+		r.lineBuf = append(r.lineBuf[:0], line...)
+		for err == bufio.ErrBufferFull {
+			line, err = r.r.ReadSlice('\n')
+			r.lineBuf = append(r.lineBuf, line...)
+		}
+		line = r.lineBuf
+	}
+	if err == io.EOF && len(line) > 0 {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.line++
+	line = bytes.TrimSuffix(line, []byte{'\n'})
+	return bytes.TrimSuffix(line, []byte{'\r'}), nil
+}
@@ -0,0 +1,117 @@
+package arenacsv
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+func TestReadMatchesEncodingCSV(t *testing.T) {
+	inputs := []string{
+		"a,b,c\n1,2,3\n",
+		"a,b\r\n\r\nc,d",
+		`"quoted, comma","say ""hi""",plain` + "\n",
+		"\"multi\nline\",x\n",
+		"a,,\n,b,\n",
+		`"",""` + "\n",
+		strings.Repeat("x", 10000) + ",y\n",
+	}
+	for _, in := range inputs {
+		want, err := csv.NewReader(strings.NewReader(in)).ReadAll()
+		if err != nil {
+			t.Fatalf("csv.ReadAll(%q) error = %v", in, err)
+		}
+
+		a := arena.NewArena(0)
+		r := NewReader(a, strings.NewReader(in))
+		var got [][]string
+		for {
+			rec, err := r.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Read(%q) error = %v", in, err)
+			}
+			got = append(got, rec)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("records of %q = %q, want %q", in, got, want)
+		}
+		a.Release()
+	}
+}
+
+func TestReadTSV(t *testing.T) {
+	a := arena.NewArena(0)
+	defer a.Release()
+	r := NewReader(a, strings.NewReader("id\tname\n1\t\"a\tb\"\n"))
+	r.Comma = '\t'
+	r.Read()
+	rec, err := r.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1", "a\tb"}; !reflect.DeepEqual(rec, want) {
+		t.Errorf("record = %q, want %q", rec, want)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	for in, want := range map[string]error{
+		"a,b\"c\n":       csv.ErrBareQuote,
+		"\"a\"b,c\n":     csv.ErrQuote,
+		"\"unterminated": csv.ErrQuote,
+		"a,b\nc\n":       ErrFieldCount,
+	} {
+		r := NewReader(arena.NewArena(0), strings.NewReader(in))
+		var err error
+		for err == nil {
+			_, err = r.Read()
+		}
+		var pe *csv.ParseError
+		if !errors.As(err, &pe) || !errors.Is(err, want) {
+			t.Errorf("Read(%q) error = %v, want ParseError wrapping %v", in, err, want)
+		}
+	}
+}
+
+func TestReadBatch(t *testing.T) {
+	a := arena.NewArena(1024)
+	defer a.Release()
+	var sb strings.Builder
+	for i := 0; i < 25; i++ {
+		sb.WriteString("alpha,beta,gamma\n")
+	}
+	r := NewReader(a, strings.NewReader(sb.String()))
+
+	total, capacity := 0, 0
+	for {
+		batch, err := r.ReadBatch(10)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, rec := range batch {
+			if rec[2] != "gamma" {
+				t.Fatalf("record = %q", rec)
+			}
+		}
+		total += len(batch)
+		if capacity == 0 {
+			capacity = a.Capacity()
+		} else if a.Capacity() != capacity {
+			t.Errorf("arena grew from %d to %d bytes between batches", capacity, a.Capacity())
+		}
+	}
+	if total != 25 {
+		t.Errorf("read %d records, want 25", total)
+	}
+}
@@ -0,0 +1,74 @@
+package arenahttp
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// MiddlewareOptions configures Middleware.
+type MiddlewareOptions struct {
+	// Pool supplies the request arenas. If nil, a pool of arenas with
+	// arena.DefaultChunkSize chunks is used.
+	Pool *arena.ArenaPool
+	// OnRequest, if set, is called with the arena activity of each request
+	// once its handler has returned.
+	OnRequest func(r *http.Request, m RequestMetrics)
+}
+
+// RequestMetrics describes the arena activity of one request.
+type RequestMetrics struct {
+	Allocs   uint64        // Allocations served
+	Bytes    uint64        // Bytes handed out, including alignment padding
+	Grows    uint64        // Chunks added
+	InUse    int           // SizeInUse of the arena when the handler returned
+	Duration time.Duration // Time spent in the handler
+}
+
+// Middleware returns middleware that gives every request an arena from
+// opts.Pool, attached to the request context so handlers get it with
+// arena.FromContext(r.Context()) or Arena(r). When the handler returns the
+// arena is reset and returned to the pool, so handlers must not retain
+// memory allocated from it, for example in goroutines that outlive the
+// request. If the handler panics the arena is released instead.
+func Middleware(opts MiddlewareOptions) func(http.Handler) http.Handler {
+	pool := opts.Pool
+	if pool == nil {
+		pool = arena.NewArenaPool(0)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a := pool.Get()
+			served := false
+			defer func() {
+				if !served {
+					pool.Discard(a)
+				}
+			}()
+
+			start, begin := a.Metrics(), time.Now()
+			r = r.WithContext(arena.NewContext(r.Context(), a))
+			next.ServeHTTP(w, r)
+			served = true
+
+			if opts.OnRequest != nil {
+				end := a.Metrics()
+				d := end.DeltaSince(start)
+				opts.OnRequest(r, RequestMetrics{
+					Allocs:   d.Allocs,
+					Bytes:    d.Bytes,
+					Grows:    d.Grows,
+					InUse:    end.SizeInUse,
+					Duration: time.Since(begin),
+				})
+			}
+			pool.Put(a)
+		})
+	}
+}
+
+// Arena returns the arena Middleware attached to r, or nil if there is none.
+func Arena(r *http.Request) *arena.Arena {
+	return arena.FromContext(r.Context())
+}
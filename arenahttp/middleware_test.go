@@ -0,0 +1,102 @@
+package arenahttp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+func TestMiddleware(t *testing.T) {
+	var got []RequestMetrics
+	var seen *arena.Arena
+	mw := Middleware(MiddlewareOptions{
+		Pool: arena.NewArenaPool(1024),
+		OnRequest: func(r *http.Request, m RequestMetrics) {
+			if Arena(r) == nil {
+				t.Error("OnRequest request has no arena")
+			}
+			got = append(got, m)
+		},
+	})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a := arena.FromContext(r.Context())
+		if a == nil {
+			t.Fatal("handler request has no arena")
+		}
+		seen = a
+		if a.SizeInUse() != 0 {
+			t.Errorf("request arena SizeInUse = %d at start, want 0", a.SizeInUse())
+		}
+		a.AllocBytes(100)
+		arena.CloneString(a, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	for i := 0; i < 3; i++ {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/items", nil))
+		if rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if n := seen.SizeInUse(); n != 0 {
+			t.Errorf("request %d: arena not reset after the handler, SizeInUse = %d", i, n)
+		}
+	}
+
+	if len(got) != 3 {
+		t.Fatalf("OnRequest called %d times, want 3", len(got))
+	}
+	for i, m := range got {
+		if m.Allocs != 2 || m.Bytes < 106 || m.InUse < 106 {
+			t.Errorf("request %d metrics = %+v, want 2 allocs of at least 106 bytes", i, m)
+		}
+	}
+}
+
+func TestMiddlewarePanic(t *testing.T) {
+	var a *arena.Arena
+	h := Middleware(MiddlewareOptions{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a = Arena(r)
+		panic(http.ErrAbortHandler)
+	}))
+	func() {
+		defer func() {
+			if r := recover(); r != http.ErrAbortHandler {
+				t.Errorf("recovered %v, want http.ErrAbortHandler", r)
+			}
+		}()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	defer func() {
+		if recover() == nil {
+			t.Error("arena of a panicking handler was not released")
+		}
+	}()
+	a.AllocBytes(8)
+}
+
+func TestMiddlewarePanicPoolState(t *testing.T) {
+	pool := arena.NewArenaPool(1024)
+	fail := true
+	h := Middleware(MiddlewareOptions{Pool: pool})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			panic(http.ErrAbortHandler)
+		}
+	}))
+	serve := func() {
+		defer func() { recover() }()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	for i := 0; i < 3; i++ {
+		serve()
+	}
+	fail = false
+	serve()
+
+	st := pool.State()
+	if len(st.Buckets) != 1 || st.Buckets[0].PeakInUse != 1 {
+		t.Errorf("State().Buckets = %+v, want one bucket with PeakInUse 1", st.Buckets)
+	}
+}
@@ -0,0 +1,108 @@
+// Package arenahttp provides net/http helpers that place request data in an arena.
+package arenahttp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// MaxInlinePartSize is the largest part body copied into the arena.
+// Bigger parts are streamed to the LargePartHandler instead.
+const MaxInlinePartSize = 32 << 10
+
+var (
+	// ErrNotMultipart is returned when the request is not multipart/form-data.
+	ErrNotMultipart = errors.New("arenahttp: request Content-Type is not multipart/form-data")
+	// ErrPartTooLarge is returned when a part exceeds MaxInlinePartSize and
+	// no LargePartHandler was given.
+	ErrPartTooLarge = errors.New("arenahttp: multipart part exceeds MaxInlinePartSize")
+)
+
+// Part is a single multipart part. Its strings and Body point into the arena
+// and are only valid until the arena is reset or released.
+type Part struct {
+	FormName    string
+	FileName    string
+	ContentType string
+	Body        []byte // nil if the part is empty or was streamed
+	Streamed    bool   // the body was passed to a LargePartHandler
+}
+
+// LargePartHandler consumes a part whose body is larger than
+// MaxInlinePartSize. body yields the complete part body and must be read
+// before the handler returns.
+type LargePartHandler func(p Part, body io.Reader) error
+
+// ParseMultipart reads a multipart/form-data request body, storing part
+// headers and small part bodies in a. Parts larger than MaxInlinePartSize
+// are passed to onLarge; if onLarge is nil they cause ErrPartTooLarge.
+// The returned slice and the read buffer are allocated from a as well (the
+// buffer from its Transient class), so parsing adds no heap allocations
+// beyond those of mime/multipart.
+func ParseMultipart(a *arena.Arena, r *http.Request, onLarge LargePartHandler) ([]Part, error) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return nil, ErrNotMultipart
+	}
+
+	mr := multipart.NewReader(r.Body, params["boundary"])
+	scratch := arena.AllocSlice[byte](a.In(arena.Transient), MaxInlinePartSize+1)
+	var parts []Part
+	for {
+		mp, err := mr.NextPart()
+		if err == io.EOF {
+			return parts, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		p := Part{
+			FormName:    arena.CloneString(a, mp.FormName()),
+			FileName:    arena.CloneString(a, mp.FileName()),
+			ContentType: arena.CloneString(a, mp.Header.Get("Content-Type")),
+		}
+
+		n, err := io.ReadFull(mp, scratch)
+		switch err {
+		case io.EOF, io.ErrUnexpectedEOF:
+			// Whole body fits inline
+			if n > 0 {
+				p.Body = a.AllocBytes(n)
+				copy(p.Body, scratch[:n])
+			}
+		case nil:
+			if onLarge == nil {
+				return nil, ErrPartTooLarge
+			}
+			p.Streamed = true
+			if err := onLarge(p, io.MultiReader(bytes.NewReader(scratch[:n]), mp)); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, err
+		}
+		parts = appendPart(a, parts, p)
+	}
+}
+
+// appendPart appends p to parts, growing parts inside a as arena.Append
+// does. A Part only ever points into the arena holding it, so its backing
+// array is allocated as raw arena bytes, which the PointerPolicy does not
+// apply to.
+func appendPart(a *arena.Arena, parts []Part, p Part) []Part {
+	if len(parts) == cap(parts) {
+		n := max(4, 2*cap(parts))
+		b := a.AllocBytes(n * int(unsafe.Sizeof(p)))
+		grown := unsafe.Slice((*Part)(unsafe.Pointer(&b[0])), n)
+		parts = append(grown[:0], parts...)
+	}
+	return append(parts, p)
+}
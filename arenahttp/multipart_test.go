@@ -0,0 +1,158 @@
+package arenahttp
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+func TestParseMultipart(t *testing.T) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("title", "hello")
+	w.WriteField("empty", "")
+	fw, _ := w.CreateFormFile("upload", "big.bin")
+	big := bytes.Repeat([]byte("x"), MaxInlinePartSize+100)
+	fw.Write(big)
+	w.Close()
+
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	a := arena.NewArena(0)
+	defer a.Release()
+
+	var streamed int64
+	parts, err := ParseMultipart(a, r, func(p Part, body io.Reader) error {
+		if p.FileName != "big.bin" {
+			t.Errorf("large part FileName = %q, want %q", p.FileName, "big.bin")
+		}
+		n, err := io.Copy(io.Discard, body)
+		streamed = n
+		return err
+	})
+	if err != nil {
+		t.Fatalf("ParseMultipart() error = %v", err)
+	}
+
+	if len(parts) != 3 {
+		t.Fatalf("len(parts) = %d, want 3", len(parts))
+	}
+	if parts[0].FormName != "title" || string(parts[0].Body) != "hello" || parts[0].Streamed {
+		t.Errorf("parts[0] = %+v, want title=hello", parts[0])
+	}
+	if parts[1].FormName != "empty" || len(parts[1].Body) != 0 || parts[1].Streamed {
+		t.Errorf("parts[1] = %+v, want an empty inline part", parts[1])
+	}
+	if parts[2].Body != nil || !parts[2].Streamed {
+		t.Error("large part body should be streamed, not stored inline")
+	}
+	if streamed != int64(len(big)) {
+		t.Errorf("streamed %d bytes, want %d", streamed, len(big))
+	}
+}
+
+func TestParseMultipartPointerPolicy(t *testing.T) {
+	arena.SetPointerPolicy(arena.PointerForbid)
+	defer arena.SetPointerPolicy(arena.PointerAllow)
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for i := 0; i < 10; i++ {
+		w.WriteField("field", "v")
+	}
+	w.Close()
+	r := httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+
+	a := arena.NewArena(0)
+	defer a.Release()
+	parts, err := ParseMultipart(a, r, nil)
+	if err != nil {
+		t.Fatalf("ParseMultipart() error = %v", err)
+	}
+	if len(parts) != 10 {
+		t.Errorf("len(parts) = %d, want 10", len(parts))
+	}
+	if _, registered := arena.LookupType[Part](); registered {
+		t.Error("ParseMultipart registered Part process-wide")
+	}
+}
+
+func TestParseMultipartAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("sync.Pool drops items at random under the race detector")
+	}
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for i := 0; i < 8; i++ {
+		w.WriteField("field", strings.Repeat("v", 100))
+	}
+	w.Close()
+	r := httptest.NewRequest("POST", "/", nil)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	var rd bytes.Reader
+	reset := func() {
+		rd.Reset(body.Bytes())
+		r.Body = io.NopCloser(&rd)
+	}
+
+	// The same walk over the parts without an arena: what mime/multipart
+	// allocates on its own.
+	buf := make([]byte, MaxInlinePartSize+1)
+	baseline := testing.AllocsPerRun(20, func() {
+		reset()
+		_, params, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		mr := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			mp, err := mr.NextPart()
+			if err != nil {
+				break
+			}
+			mp.FormName()
+			mp.FileName()
+			mp.Header.Get("Content-Type")
+			io.ReadFull(mp, buf)
+		}
+	})
+
+	a := arena.NewArena(0)
+	defer a.Release()
+	allocs := testing.AllocsPerRun(20, func() {
+		reset()
+		if _, err := ParseMultipart(a, r, nil); err != nil {
+			t.Fatal(err)
+		}
+		a.Reset()
+	})
+	if allocs > baseline {
+		t.Errorf("ParseMultipart allocs/op = %v, want at most %v (mime/multipart alone)", allocs, baseline)
+	}
+}
+
+func TestParseMultipartErrors(t *testing.T) {
+	a := arena.NewArena(0)
+	defer a.Release()
+
+	r := httptest.NewRequest("POST", "/", strings.NewReader("{}"))
+	r.Header.Set("Content-Type", "application/json")
+	if _, err := ParseMultipart(a, r, nil); err != ErrNotMultipart {
+		t.Errorf("ParseMultipart(json) error = %v, want ErrNotMultipart", err)
+	}
+
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	w.WriteField("blob", strings.Repeat("y", MaxInlinePartSize+1))
+	w.Close()
+	r = httptest.NewRequest("POST", "/", &body)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	if _, err := ParseMultipart(a, r, nil); err != ErrPartTooLarge {
+		t.Errorf("ParseMultipart(large, nil handler) error = %v, want ErrPartTooLarge", err)
+	}
+}
@@ -0,0 +1,5 @@
+//go:build race
+
+package arenahttp
+
+const raceEnabled = true
@@ -0,0 +1,166 @@
+// Package arenamock provides test doubles for code that accepts an
+// arena.Allocator, so it can be unit-tested without real arena memory.
+package arenamock
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// FakeArena is an arena.Allocator that serves every allocation from the Go
+// heap. Reset is a no-op; use after Release panics like a real arena.
+// Like Arena, it is an arena.UnsyncAllocator.
+type FakeArena struct {
+	arena.SingleOwner
+	released bool
+}
+
+// AllocBytes returns a new pointer-aligned heap slice of n bytes, or nil if
+// n <= 0.
+func (f *FakeArena) AllocBytes(n int) []byte {
+	if f.released {
+		panic("arenamock: use after Release()")
+	}
+	if n <= 0 {
+		return nil
+	}
+	words := make([]uintptr, (n+int(unsafe.Sizeof(uintptr(0)))-1)/int(unsafe.Sizeof(uintptr(0))))
+	return unsafe.Slice((*byte)(unsafe.Pointer(&words[0])), n)
+}
+
+// Reset does nothing besides checking the fake has not been released.
+func (f *FakeArena) Reset() {
+	if f.released {
+		panic("arenamock: use after Release()")
+	}
+}
+
+// Release marks the fake as released.
+func (f *FakeArena) Release() {
+	f.released = true
+}
+
+// Op identifies a recorded allocator call.
+type Op string
+
+const (
+	OpAlloc   Op = "alloc"
+	OpReset   Op = "reset"
+	OpRelease Op = "release"
+)
+
+// Call is a single recorded allocator call.
+type Call struct {
+	Op     Op
+	Size   int  // Requested size for OpAlloc
+	Failed bool // Whether an injected failure made AllocBytes return nil
+}
+
+// Recorder is an arena.Allocator that records every call and forwards it to
+// an underlying allocator. It can inject allocation failures and collects
+// lifecycle violations (use after Release, double Release) for Verify
+// instead of panicking. Safe for concurrent use, as calls to the underlying
+// allocator are serialized, so it is an arena.SyncAllocator.
+type Recorder struct {
+	arena.ConcurrencySafe
+	mu         sync.Mutex
+	next       arena.Allocator
+	calls      []Call
+	failAfter  int // allocations allowed before failures start; -1 for never
+	allocs     int
+	released   bool
+	violations []error
+}
+
+// NewRecorder returns a Recorder forwarding to next.
+// If next is nil, a FakeArena is used.
+func NewRecorder(next arena.Allocator) *Recorder {
+	if next == nil {
+		next = &FakeArena{}
+	}
+	return &Recorder{next: next, failAfter: -1}
+}
+
+// FailAllocsAfter makes every AllocBytes call after the next n successful
+// ones return nil. A negative n disables failure injection.
+func (r *Recorder) FailAllocsAfter(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.failAfter = n
+	r.allocs = 0
+}
+
+// AllocBytes records the call and forwards it unless a failure is injected.
+func (r *Recorder) AllocBytes(n int) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.released {
+		r.violate("AllocBytes(%d) after Release", n)
+		r.calls = append(r.calls, Call{Op: OpAlloc, Size: n, Failed: true})
+		return nil
+	}
+	if r.failAfter >= 0 && r.allocs >= r.failAfter {
+		r.calls = append(r.calls, Call{Op: OpAlloc, Size: n, Failed: true})
+		return nil
+	}
+	r.allocs++
+	r.calls = append(r.calls, Call{Op: OpAlloc, Size: n})
+	return r.next.AllocBytes(n)
+}
+
+// Reset records the call and forwards it.
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Op: OpReset})
+	if r.released {
+		r.violate("Reset after Release")
+		return
+	}
+	r.next.Reset()
+}
+
+// Release records the call and forwards it.
+func (r *Recorder) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, Call{Op: OpRelease})
+	if r.released {
+		r.violate("Release called twice")
+		return
+	}
+	r.released = true
+	r.next.Release()
+}
+
+// Calls returns a copy of the recorded calls in order.
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Call(nil), r.calls...)
+}
+
+// Verify reports lifecycle violations seen so far, and an error if the
+// allocator was never released.
+func (r *Recorder) Verify() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	errs := append([]error(nil), r.violations...)
+	if !r.released {
+		errs = append(errs, errors.New("arenamock: allocator was never released"))
+	}
+	return errors.Join(errs...)
+}
+
+func (r *Recorder) violate(format string, args ...any) {
+	r.violations = append(r.violations, fmt.Errorf("arenamock: "+format, args...))
+}
+
+var (
+	_ arena.UnsyncAllocator = (*FakeArena)(nil)
+	_ arena.SyncAllocator   = (*Recorder)(nil)
+)
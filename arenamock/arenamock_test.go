@@ -0,0 +1,92 @@
+package arenamock
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// fill is a stand-in for library code that accepts an allocator.
+func fill(al arena.Allocator, sizes ...int) int {
+	ok := 0
+	for _, n := range sizes {
+		if b := al.AllocBytes(n); b != nil {
+			ok++
+		}
+	}
+	return ok
+}
+
+func TestRecorderCalls(t *testing.T) {
+	r := NewRecorder(nil)
+	fill(r, 8, 16)
+	r.Reset()
+	r.Release()
+
+	want := []Call{{Op: OpAlloc, Size: 8}, {Op: OpAlloc, Size: 16}, {Op: OpReset}, {Op: OpRelease}}
+	got := r.Calls()
+	if len(got) != len(want) {
+		t.Fatalf("Calls() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Calls()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if err := r.Verify(); err != nil {
+		t.Errorf("Verify() = %v, want nil", err)
+	}
+}
+
+func TestRecorderFailureInjection(t *testing.T) {
+	r := NewRecorder(arena.NewArena(1024))
+	r.FailAllocsAfter(2)
+
+	if got := fill(r, 8, 8, 8, 8); got != 2 {
+		t.Errorf("successful allocations = %d, want 2", got)
+	}
+	if calls := r.Calls(); !calls[2].Failed || !calls[3].Failed {
+		t.Errorf("Calls() = %+v, want last two failed", calls)
+	}
+}
+
+func TestRecorderViolations(t *testing.T) {
+	r := NewRecorder(nil)
+	if err := r.Verify(); err == nil || !strings.Contains(err.Error(), "never released") {
+		t.Errorf("Verify() before Release = %v, want never released error", err)
+	}
+
+	r.Release()
+	r.AllocBytes(8)
+	r.Reset()
+	r.Release()
+
+	err := r.Verify()
+	if err == nil {
+		t.Fatal("Verify() = nil, want lifecycle violations")
+	}
+	for _, want := range []string{"AllocBytes(8) after Release", "Reset after Release", "Release called twice"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Verify() = %v, missing %q", err, want)
+		}
+	}
+}
+
+func TestFakeArena(t *testing.T) {
+	f := &FakeArena{}
+	if len(f.AllocBytes(10)) != 10 {
+		t.Error("AllocBytes(10) length != 10")
+	}
+	if f.AllocBytes(0) != nil {
+		t.Error("AllocBytes(0) should return nil")
+	}
+	f.Release()
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on use after Release()")
+		}
+	}()
+	f.AllocBytes(1)
+}
@@ -0,0 +1,188 @@
+package arena
+
+import (
+	"math/bits"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPoolClass bounds the size classes of an ArenaPool: chunk sizes up to
+// 1<<maxPoolClass bytes are pooled by GetSized.
+const maxPoolClass = 40
+
+// ArenaPool is a set of reusable arenas created with the same chunk size
+// and options, for request-scoped reuse: Get an arena at the start of a
+// request and Put it back at the end instead of creating and releasing
+// one. GetSized serves requests that are known to be larger from separate
+// buckets of power-of-two chunk sizes. Pass WithRetention in the options
+// to have Put return the capacity of bursts to the runtime, or call Trim
+// to shed cold capacity on demand. The pool learns how many arenas are in
+// use at once and how large they get; see State and Restore to carry that
+// across restarts. It is safe for concurrent use.
+type ArenaPool struct {
+	p         sync.Pool
+	chunkSize int
+	opts      []Option
+	classes   [maxPoolClass + 1]sync.Pool
+
+	base       poolStats // usage of the chunkSize bucket
+	classStats [maxPoolClass + 1]poolStats
+
+	trim          atomic.Pointer[poolTrim] // latest Trim request
+	trims         atomic.Uint64
+	arenasTrimmed atomic.Uint64
+	trimmedBytes  atomic.Uint64
+}
+
+// poolTrim is a Trim request. Arenas remember the last one they applied.
+type poolTrim struct {
+	minIdleResets uint64
+}
+
+// PoolMetrics reports the trimming done by an ArenaPool.
+type PoolMetrics struct {
+	Trims         uint64 // Trim calls
+	ArenasTrimmed uint64 // Pooled arenas that have applied a Trim
+	TrimmedBytes  uint64 // Chunk bytes those arenas freed
+}
+
+// NewArenaPool creates an ArenaPool whose arenas are created with
+// NewArena(chunkSize, opts...).
+func NewArenaPool(chunkSize int, opts ...Option) *ArenaPool {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	return &ArenaPool{chunkSize: chunkSize, opts: opts}
+}
+
+// Get returns an arena from the pool, creating one if the pool is empty.
+func (p *ArenaPool) Get() *Arena {
+	p.base.take()
+	if a, ok := p.p.Get().(*Arena); ok {
+		return p.reuse(a)
+	}
+	return p.base.warm(NewArena(p.chunkSize, p.opts...))
+}
+
+// GetE is Get returning an error wrapping ErrChunkUnavailable, as
+// NewArenaE does, if a new arena cannot get its first chunk.
+func (p *ArenaPool) GetE() (*Arena, error) {
+	p.base.take()
+	if a, ok := p.p.Get().(*Arena); ok {
+		return p.reuse(a), nil
+	}
+	a, err := NewArenaE(p.chunkSize, p.opts...)
+	if err != nil {
+		p.base.drop()
+		return nil, err
+	}
+	return p.base.warm(a), nil
+}
+
+// GetSized returns an arena whose chunks hold at least sizeHint bytes,
+// from the bucket for the next power of two, or from Get if the pool's
+// chunk size is large enough.
+func (p *ArenaPool) GetSized(sizeHint int) *Arena {
+	if sizeHint <= p.chunkSize {
+		return p.Get()
+	}
+	class := bits.Len(uint(sizeHint - 1))
+	if class > maxPoolClass {
+		return NewArena(sizeHint, p.opts...)
+	}
+	st := &p.classStats[class]
+	st.take()
+	if a, ok := p.classes[class].Get().(*Arena); ok {
+		return p.reuse(a)
+	}
+	return st.warm(NewArena(1<<class, p.opts...))
+}
+
+// Put resets a and returns it to the pool. Memory allocated from a must no
+// longer be in use. Arenas that did not come from the pool are dropped.
+func (p *ArenaPool) Put(a *Arena) {
+	used := a.SizeInUse()
+	a.Reset()
+	a.disarmLeakCheck()
+	pool, st := p.bucket(a.ChunkSize())
+	if pool == nil {
+		a.Release()
+		return
+	}
+	st.give(used)
+	pool.Put(a)
+}
+
+// Discard releases a, which came from the pool, instead of putting it
+// back, for arenas whose memory may still be referenced, such as the arena
+// of a handler that panicked. Unlike calling a.Release directly, it keeps
+// the pool's count of arenas in use (see State) accurate.
+func (p *ArenaPool) Discard(a *Arena) {
+	if _, st := p.bucket(a.ChunkSize()); st != nil {
+		st.drop()
+	}
+	a.Release()
+}
+
+// bucket returns the pool and usage statistics for arenas of the given
+// chunk size, or nil if such arenas are not pooled.
+func (p *ArenaPool) bucket(size int) (*sync.Pool, *poolStats) {
+	switch {
+	case size == p.chunkSize:
+		return &p.p, &p.base
+	case size > p.chunkSize && size&(size-1) == 0 && bits.Len(uint(size-1)) <= maxPoolClass:
+		class := bits.Len(uint(size - 1))
+		return &p.classes[class], &p.classStats[class]
+	}
+	return nil, nil
+}
+
+// Trim asks the pool to free the empty chunks of its arenas that have not
+// held data for at least minIdleResets reset cycles, as Arena.TrimCold
+// does. It does not block: sync.Pool cannot enumerate its arenas, so each
+// pooled arena applies the request the next time Get hands it out, and
+// arenas that are in use are never touched. Arenas the runtime drops from
+// the pool are freed with all their chunks anyway. A later Trim replaces
+// an earlier one that some arenas have not applied yet.
+func (p *ArenaPool) Trim(minIdleResets uint64) {
+	p.trims.Add(1)
+	p.trim.Store(&poolTrim{minIdleResets: minIdleResets})
+}
+
+// Metrics returns the pool's trim counters.
+func (p *ArenaPool) Metrics() PoolMetrics {
+	return PoolMetrics{
+		Trims:         p.trims.Load(),
+		ArenasTrimmed: p.arenasTrimmed.Load(),
+		TrimmedBytes:  p.trimmedBytes.Load(),
+	}
+}
+
+// AppendText appends the metrics to dst as space-separated key=value pairs,
+// like ArenaMetrics.AppendText, and returns the extended buffer.
+func (m PoolMetrics) AppendText(dst []byte) ([]byte, error) {
+	dst = append(dst, "trims="...)
+	dst = strconv.AppendUint(dst, m.Trims, 10)
+	dst = append(dst, " arenas_trimmed="...)
+	dst = strconv.AppendUint(dst, m.ArenasTrimmed, 10)
+	dst = append(dst, " trimmed_bytes="...)
+	dst = strconv.AppendUint(dst, m.TrimmedBytes, 10)
+	return dst, nil
+}
+
+// MarshalText implements encoding.TextMarshaler using AppendText.
+func (m PoolMetrics) MarshalText() ([]byte, error) {
+	return m.AppendText(nil)
+}
+
+// reuse prepares a pooled arena for Get, applying a pending Trim.
+func (p *ArenaPool) reuse(a *Arena) *Arena {
+	if t := p.trim.Load(); t != nil && a.poolTrim != t {
+		a.poolTrim = t
+		p.arenasTrimmed.Add(1)
+		p.trimmedBytes.Add(uint64(a.TrimCold(t.minIdleResets)))
+	}
+	a.armLeakCheck(1)
+	return a
+}
@@ -0,0 +1,103 @@
+package arena
+
+import "testing"
+
+func TestArenaPool(t *testing.T) {
+	p := NewArenaPool(2048, WithName("pooled"))
+	a := p.Get()
+	if a.ChunkSize() != 2048 || a.Name() != "pooled" {
+		t.Errorf("pooled arena has chunk size %d and name %q", a.ChunkSize(), a.Name())
+	}
+	a.AllocBytes(100)
+	p.Put(a)
+	if a.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after Put = %d, want 0", a.SizeInUse())
+	}
+}
+
+func TestArenaPoolSizeClasses(t *testing.T) {
+	p := NewArenaPool(1024, WithRetention(0))
+	if a := p.GetSized(512); a.ChunkSize() != 1024 {
+		t.Errorf("GetSized(512) chunk size = %d, want the pool's 1024", a.ChunkSize())
+	}
+	a := p.GetSized(5000)
+	if a.ChunkSize() != 8192 {
+		t.Fatalf("GetSized(5000) chunk size = %d, want 8192", a.ChunkSize())
+	}
+	a.AllocBytes(5000)
+	p.Put(a)
+	if b := p.GetSized(8000); b.ChunkSize() != 8192 || b.SizeInUse() != 0 {
+		t.Errorf("GetSized(8000) = chunk size %d, in use %d, want a reset 8192 arena", b.ChunkSize(), b.SizeInUse())
+	}
+
+	// WithRetention trims bursts when arenas come back.
+	c := p.Get()
+	for i := 0; i < 10; i++ {
+		c.AllocBytes(1000)
+	}
+	p.Put(c)
+	if c.Capacity() != 1024 {
+		t.Errorf("Capacity after Put = %d, want 1024", c.Capacity())
+	}
+
+	foreign := NewArena(3000)
+	p.Put(foreign)
+	if foreign.chunks != nil {
+		t.Error("Put kept an arena with a foreign chunk size")
+	}
+}
+
+func TestArenaPoolTrim(t *testing.T) {
+	p := NewArenaPool(1024)
+	a := p.Get()
+	for i := 0; i < 5; i++ {
+		a.AllocBytes(1000)
+	}
+	p.Put(a)
+	p.Trim(1)
+	b := p.Get()
+	if b != a {
+		t.Skip("sync.Pool dropped the arena")
+	}
+	if b.Capacity() != 1024 {
+		t.Errorf("Capacity after Trim and Get = %d, want 1024", b.Capacity())
+	}
+	want := PoolMetrics{Trims: 1, ArenasTrimmed: 1, TrimmedBytes: 4 * 1024}
+	if m := p.Metrics(); m != want {
+		t.Errorf("Metrics() = %+v, want %+v", m, want)
+	}
+
+	// The request is applied once per arena.
+	b.AllocBytes(5000)
+	p.Put(b)
+	if c := p.Get(); c == b && c.Capacity() == 1024 {
+		t.Error("Trim applied again without a new request")
+	}
+}
+
+func TestPoolMetricsAppendText(t *testing.T) {
+	m := PoolMetrics{Trims: 2, ArenasTrimmed: 3, TrimmedBytes: 4096}
+	got, err := m.AppendText([]byte("pool: "))
+	want := "pool: trims=2 arenas_trimmed=3 trimmed_bytes=4096"
+	if err != nil || string(got) != want {
+		t.Errorf("AppendText() = %q, %v, want %q, nil", got, err, want)
+	}
+	if text, _ := m.MarshalText(); string(text) != want[len("pool: "):] {
+		t.Errorf("MarshalText() = %q", text)
+	}
+}
+
+func TestArenaPoolDiscard(t *testing.T) {
+	p := NewArenaPool(1024)
+	for i := 0; i < 3; i++ {
+		p.Discard(p.Get())
+	}
+	a := p.Get()
+	p.Put(a)
+	if st := p.State(); len(st.Buckets) != 1 || st.Buckets[0].PeakInUse != 1 {
+		t.Errorf("State().Buckets = %+v, want PeakInUse 1 after discards", st.Buckets)
+	}
+	if msg := panicMessage(func() { a := p.Get(); p.Discard(a); a.AllocBytes(8) }); msg == "" {
+		t.Error("discarded arena was not released")
+	}
+}
@@ -0,0 +1,29 @@
+package arenatest
+
+import (
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// VerifyNoHeapAllocs fails t if fn makes heap allocations while
+// allocating from the arena it is given. fn runs once to warm up, so the
+// arena already holds the chunks it needs, and is then measured with
+// testing.AllocsPerRun, resetting the arena between runs. Use it to guard
+// code built on the arena fast paths against regressions such as values
+// escaping through generic helpers. fn must not retain the arena or its
+// memory across calls.
+func VerifyNoHeapAllocs(t testing.TB, fn func(a *arena.Arena)) {
+	t.Helper()
+	a := arena.NewArena(0)
+	defer a.Release()
+	fn(a)
+	a.Reset()
+	allocs := testing.AllocsPerRun(100, func() {
+		fn(a)
+		a.Reset()
+	})
+	if allocs > 0 {
+		t.Errorf("%.1f heap allocations per run, want 0", allocs)
+	}
+}
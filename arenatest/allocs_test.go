@@ -0,0 +1,80 @@
+package arenatest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+type allocsPoint struct {
+	x, y int64
+	tag  [8]byte
+}
+
+// TestFastPathsNoHeapAllocs is the package's own allocs/op self-test: the
+// arena fast paths must not allocate on the heap.
+func TestFastPathsNoHeapAllocs(t *testing.T) {
+	cases := []struct {
+		name string
+		fn   func(a *arena.Arena)
+	}{
+		{"AllocBytes", func(a *arena.Arena) { a.AllocBytes(64) }},
+		{"Alloc", func(a *arena.Arena) { arena.Alloc[allocsPoint](a).x = 1 }},
+		{"AllocZeroed", func(a *arena.Arena) { arena.AllocZeroed[allocsPoint](a) }},
+		{"AllocUninitialized", func(a *arena.Arena) { arena.AllocUninitialized[allocsPoint](a) }},
+		{"AllocSlice", func(a *arena.Arena) { arena.AllocSlice[allocsPoint](a, 16) }},
+		{"AllocSliceZeroed", func(a *arena.Arena) { arena.AllocSliceZeroed[int32](a, 100) }},
+		{"CloneBytes", func(a *arena.Arena) { arena.CloneBytes(a, []byte("payload")) }},
+		{"CloneString", func(a *arena.Arena) { arena.CloneString(a, "payload") }},
+		{"AllocString", func(a *arena.Arena) { arena.AllocString(a, "payload") }},
+		{"Append", func(a *arena.Arena) {
+			var s []int
+			for i := 0; i < 50; i++ {
+				s = arena.Append(a, s, i)
+			}
+		}},
+		{"EncodeHex", func(a *arena.Arena) { arena.EncodeHex(a, []byte("payload")) }},
+		{"Mixed", func(a *arena.Arena) {
+			for i := 0; i < 100; i++ {
+				arena.Alloc[allocsPoint](a)
+				a.AllocBytes(i + 1)
+			}
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			VerifyNoHeapAllocs(t, tc.fn)
+		})
+	}
+}
+
+func TestVerifyNoHeapAllocsDetects(t *testing.T) {
+	var sink []byte
+	ft := &fakeT{TB: t}
+	VerifyNoHeapAllocs(ft, func(a *arena.Arena) {
+		sink = make([]byte, 64)
+	})
+	_ = sink
+	if !ft.failed {
+		t.Error("VerifyNoHeapAllocs did not report a heap allocation")
+	}
+}
+
+// fakeT records failures instead of failing the test.
+type fakeT struct {
+	testing.TB
+	failed bool
+}
+
+func (f *fakeT) Helper() {}
+
+func (f *fakeT) Errorf(format string, args ...any) { f.failed = true }
+
+// errFakeFatal unwinds a helper that called Fatalf on a fakeT.
+var errFakeFatal = errors.New("fakeT: Fatalf")
+
+func (f *fakeT) Fatalf(format string, args ...any) {
+	f.failed = true
+	panic(errFakeFatal)
+}
@@ -0,0 +1,172 @@
+// Package arenatest provides a conformance suite for arena.Allocator
+// implementations, so alternative allocators are held to the same
+// behavior as Arena and SafeArena, and test helpers for code built on
+// arenas.
+package arenatest
+
+import (
+	"math"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// RunAllocatorConformance runs the single-goroutine conformance suite as
+// subtests of t. newAllocator must return a fresh allocator on every call.
+func RunAllocatorConformance(t *testing.T, newAllocator func() arena.Allocator) {
+	t.Run("ZeroAndNegative", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		if b := al.AllocBytes(0); b != nil {
+			t.Errorf("AllocBytes(0) = %v, want nil", b)
+		}
+		if b := al.AllocBytes(-1); b != nil {
+			t.Errorf("AllocBytes(-1) = %v, want nil", b)
+		}
+	})
+
+	t.Run("LengthAndCapacity", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		for _, n := range []int{1, 7, 8, 64, 1000, 4096} {
+			b := al.AllocBytes(n)
+			if len(b) != n || cap(b) != n {
+				t.Errorf("AllocBytes(%d): len=%d cap=%d, want both %d", n, len(b), cap(b), n)
+			}
+		}
+	})
+
+	t.Run("Alignment", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		const align = unsafe.Alignof(uintptr(0))
+		for _, n := range []int{1, 3, 5, 8, 13, 100} {
+			b := al.AllocBytes(n)
+			if p := uintptr(unsafe.Pointer(&b[0])); p%align != 0 {
+				t.Errorf("AllocBytes(%d) = %#x, not %d-byte aligned", n, p, align)
+			}
+		}
+	})
+
+	t.Run("FreshMemoryZeroed", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		for i, v := range al.AllocBytes(512) {
+			if v != 0 {
+				t.Fatalf("fresh allocation byte %d = %#x, want 0", i, v)
+			}
+		}
+	})
+
+	t.Run("NoOverlap", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		bufs := make([][]byte, 200)
+		for i := range bufs {
+			bufs[i] = al.AllocBytes(1 + i%97)
+			for j := range bufs[i] {
+				bufs[i][j] = byte(i)
+			}
+		}
+		for i, b := range bufs {
+			for j, v := range b {
+				if v != byte(i) {
+					t.Fatalf("allocation %d byte %d = %d, overwritten by another allocation", i, j, v)
+				}
+			}
+		}
+	})
+
+	t.Run("LargeAllocation", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		const n = 4 << 20
+		b := al.AllocBytes(n)
+		if len(b) != n {
+			t.Fatalf("AllocBytes(%d) length = %d", n, len(b))
+		}
+		b[0], b[n-1] = 1, 2
+	})
+
+	t.Run("ResetReuse", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		for cycle := 0; cycle < 3; cycle++ {
+			for i := 0; i < 100; i++ {
+				if b := al.AllocBytes(128); len(b) != 128 {
+					t.Fatalf("cycle %d: AllocBytes(128) length = %d", cycle, len(b))
+				}
+			}
+			al.Reset()
+		}
+	})
+
+	t.Run("Overflow", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		func() {
+			defer func() { recover() }()
+			if b := al.AllocBytes(math.MaxInt); b != nil && len(b) != math.MaxInt {
+				t.Errorf("AllocBytes(MaxInt) returned a short slice of %d bytes", len(b))
+			}
+		}()
+		// The allocator stays usable after a failed huge request
+		if b := al.AllocBytes(16); len(b) != 16 {
+			t.Errorf("AllocBytes(16) after overflow attempt length = %d", len(b))
+		}
+	})
+
+	t.Run("UseAfterRelease", func(t *testing.T) {
+		al := newAllocator()
+		al.Release()
+		defer func() {
+			if recover() == nil {
+				t.Error("AllocBytes after Release did not panic")
+			}
+		}()
+		al.AllocBytes(8)
+	})
+}
+
+// RunConcurrentAllocatorConformance runs RunAllocatorConformance plus checks
+// that allocations made concurrently from several goroutines never overlap.
+// Only allocators that declare themselves goroutine-safe, by implementing
+// arena.SyncAllocator, can be passed.
+func RunConcurrentAllocatorConformance(t *testing.T, newAllocator func() arena.SyncAllocator) {
+	RunAllocatorConformance(t, func() arena.Allocator { return newAllocator() })
+
+	t.Run("ConcurrentNoOverlap", func(t *testing.T) {
+		al := newAllocator()
+		defer al.Release()
+		const workers, perWorker = 8, 200
+
+		var wg sync.WaitGroup
+		bufs := make([][][]byte, workers)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				for i := 0; i < perWorker; i++ {
+					b := al.AllocBytes(24)
+					for j := range b {
+						b[j] = byte(w)
+					}
+					bufs[w] = append(bufs[w], b)
+				}
+			}(w)
+		}
+		wg.Wait()
+
+		for w, list := range bufs {
+			for _, b := range list {
+				for _, v := range b {
+					if v != byte(w) {
+						t.Fatalf("worker %d allocation overwritten by worker %d", w, v)
+					}
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package arenatest
+
+import (
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+	"github.com/pavanmanishd/arena/arenamock"
+)
+
+func TestArenaConformance(t *testing.T) {
+	RunAllocatorConformance(t, func() arena.Allocator { return arena.NewArena(1024) })
+}
+
+func TestSafeArenaConformance(t *testing.T) {
+	RunConcurrentAllocatorConformance(t, func() arena.SyncAllocator { return arena.NewSafeArena(1024) })
+}
+
+func TestSpinLockSafeArenaConformance(t *testing.T) {
+	RunConcurrentAllocatorConformance(t, func() arena.SyncAllocator {
+		return arena.NewSafeArena(1024, arena.WithSpinLock())
+	})
+}
+
+func TestTaggedArenaConformance(t *testing.T) {
+	RunAllocatorConformance(t, func() arena.Allocator {
+		return arena.NewArena(1024, arena.WithMemoryTags(), arena.WithGrowthFactor(2))
+	})
+}
+
+func TestSizeRouterConformance(t *testing.T) {
+	RunAllocatorConformance(t, func() arena.Allocator { return arena.NewSizeRouter(64, nil, nil) })
+}
+
+func TestFakeArenaConformance(t *testing.T) {
+	RunAllocatorConformance(t, func() arena.Allocator { return &arenamock.FakeArena{} })
+}
@@ -0,0 +1,184 @@
+package arenatest
+
+import (
+	"bytes"
+	"testing"
+	"unsafe"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// Op codes decoded by RunOps. Each op is one byte, taken modulo numOps,
+// followed by a two-byte little-endian argument where noted.
+const (
+	OpAlloc    = iota // AllocBytes(1 + arg%MaxOpAlloc)
+	OpReset           // Reset
+	OpGrow            // Grow(arg%MaxOpAlloc), if the allocator is a Grower
+	OpMark            // Snapshot, if the allocator is a Marker
+	OpRollback        // Restore the most recent mark, if any
+	numOps
+)
+
+// MaxOpAlloc bounds the sizes RunOps allocates and grows by, and MaxOps
+// the number of ops it runs, keeping fuzz runs fast while still crossing
+// chunk boundaries of small arenas.
+const (
+	MaxOpAlloc = 1024
+	MaxOps     = 256
+)
+
+// Grower is implemented by allocators that can reserve room ahead of
+// allocation, as Arena.Grow does.
+type Grower interface {
+	Grow(n int) int
+}
+
+// Marker is implemented by allocators that can roll back to an earlier
+// allocation position, as Arena.Snapshot and Arena.Restore do.
+type Marker interface {
+	Snapshot() arena.ArenaMark
+	Restore(m arena.ArenaMark)
+}
+
+// Sizer is implemented by allocators that report their usage, as Arena
+// does. RunOps checks the figures against the allocations it holds.
+type Sizer interface {
+	SizeInUse() int
+	Capacity() int
+	NumChunks() int
+}
+
+// opAlloc is an allocation RunOps holds, filled with a byte pattern.
+type opAlloc struct {
+	b    []byte
+	fill byte
+}
+
+// RunOps decodes data into a sequence of allocator operations, applies
+// them to al, and fails t as soon as an invariant breaks: allocations are
+// pointer-aligned and have the requested length and capacity, live
+// allocations never overlap or get overwritten, Grow reports the capacity
+// and makes room for the next allocation, and the usage reported by a
+// Sizer stays within capacity and covers every live allocation.
+//
+// Input beyond MaxOps ops is ignored. Ops the allocator does not support
+// (see Grower and Marker) are skipped, so the same corpus drives any
+// arena.Allocator. RunOps is the interpreter behind FuzzArenaOperations;
+// allocator variants can run it from fuzz targets of their own:
+//
+//	func FuzzMyAllocator(f *testing.F) {
+//		f.Fuzz(func(t *testing.T, data []byte) {
+//			al := NewMyAllocator()
+//			defer al.Release()
+//			arenatest.RunOps(t, al, data)
+//		})
+//	}
+func RunOps(t testing.TB, al arena.Allocator, data []byte) {
+	t.Helper()
+	grower, _ := al.(Grower)
+	marker, _ := al.(Marker)
+	sizer, _ := al.(Sizer)
+
+	var live []opAlloc
+	var marks []arena.ArenaMark
+	var markLive []int // len(live) when each mark was taken
+	var fill byte
+	for step := 0; len(data) > 0 && step < MaxOps; step++ {
+		op := int(data[0]) % numOps
+		data = data[1:]
+		arg := 0
+		if op == OpAlloc || op == OpGrow {
+			if len(data) >= 2 {
+				arg = int(data[0]) | int(data[1])<<8
+				data = data[2:]
+			} else {
+				data = nil
+			}
+		}
+
+		switch op {
+		case OpAlloc:
+			n := 1 + arg%MaxOpAlloc
+			b := al.AllocBytes(n)
+			if len(b) != n || cap(b) != n {
+				t.Fatalf("step %d: AllocBytes(%d): len=%d cap=%d, want both %d", step, n, len(b), cap(b), n)
+			}
+			if p := uintptr(unsafe.Pointer(&b[0])); p%unsafe.Alignof(uintptr(0)) != 0 {
+				t.Fatalf("step %d: AllocBytes(%d) = %#x, not pointer-aligned", step, n, p)
+			}
+			fill++
+			for i := range b {
+				b[i] = fill
+			}
+			live = append(live, opAlloc{b: b, fill: fill})
+		case OpReset:
+			al.Reset()
+			live, marks, markLive = live[:0], marks[:0], markLive[:0]
+		case OpGrow:
+			if grower == nil {
+				continue
+			}
+			n := arg % MaxOpAlloc
+			capacity := grower.Grow(n)
+			if sizer != nil {
+				if got := sizer.Capacity(); capacity != got {
+					t.Fatalf("step %d: Grow(%d) = %d, Capacity() = %d", step, n, capacity, got)
+				}
+				if n > 0 {
+					chunks := sizer.NumChunks()
+					b := al.AllocBytes(n)
+					if got := sizer.NumChunks(); got != chunks {
+						t.Fatalf("step %d: AllocBytes(%d) after Grow(%d) added a chunk (%d -> %d)", step, n, n, chunks, got)
+					}
+					fill++
+					for i := range b {
+						b[i] = fill
+					}
+					live = append(live, opAlloc{b: b, fill: fill})
+				}
+			}
+		case OpMark:
+			if marker == nil {
+				continue
+			}
+			marks = append(marks, marker.Snapshot())
+			markLive = append(markLive, len(live))
+		case OpRollback:
+			if len(marks) == 0 {
+				continue
+			}
+			last := len(marks) - 1
+			marker.Restore(marks[last])
+			live = live[:markLive[last]]
+			marks, markLive = marks[:last], markLive[:last]
+		}
+		checkOpInvariants(t, step, live, sizer)
+	}
+}
+
+// checkOpInvariants verifies that every live allocation still holds its
+// pattern and that the reported usage accounts for all of them.
+func checkOpInvariants(t testing.TB, step int, live []opAlloc, sizer Sizer) {
+	t.Helper()
+	total := 0
+	for i, l := range live {
+		if bytes.Count(l.b, []byte{l.fill}) != len(l.b) {
+			j := 0
+			for l.b[j] == l.fill {
+				j++
+			}
+			t.Fatalf("step %d: live allocation %d byte %d = %d, want %d: overwritten by another allocation", step, i, j, l.b[j], l.fill)
+		}
+		total += len(l.b)
+	}
+	if sizer == nil {
+		return
+	}
+	inUse, capacity := sizer.SizeInUse(), sizer.Capacity()
+	if inUse < total {
+		t.Fatalf("step %d: SizeInUse() = %d, less than the %d bytes of live allocations", step, inUse, total)
+	}
+	if inUse > capacity {
+		t.Fatalf("step %d: SizeInUse() = %d exceeds Capacity() = %d", step, inUse, capacity)
+	}
+}
@@ -0,0 +1,66 @@
+package arenatest
+
+import (
+	"testing"
+
+	"github.com/pavanmanishd/arena"
+)
+
+// fuzzConfigs are the arena configurations FuzzArenaOperations picks from
+// with the first input byte.
+var fuzzConfigs = []func() *arena.Arena{
+	func() *arena.Arena { return arena.NewArena(256) },
+	func() *arena.Arena {
+		return arena.NewArena(256, arena.WithGrowthFactor(2), arena.WithMaxChunkSize(64<<10))
+	},
+	func() *arena.Arena { return arena.NewArena(256, arena.WithMemoryTags()) },
+	func() *arena.Arena { return arena.NewArena(256, arena.WithRetention(512)) },
+	func() *arena.Arena { return arena.NewArena(256, arena.WithBackgroundZeroing()) },
+	func() *arena.Arena { return arena.NewArena(256, arena.WithLazyInit()) },
+}
+
+func FuzzArenaOperations(f *testing.F) {
+	f.Add([]byte{0, OpAlloc, 8, 0, OpAlloc, 200, 0, OpAlloc, 0, 1, OpReset, OpAlloc, 16, 0})
+	f.Add([]byte{1, OpMark, OpAlloc, 100, 0, OpMark, OpAlloc, 255, 1, OpRollback, OpAlloc, 4, 0, OpRollback, OpAlloc, 4, 0})
+	f.Add([]byte{2, OpGrow, 0, 2, OpAlloc, 0, 2, OpGrow, 0, 16, OpAlloc, 1, 0, OpReset, OpGrow, 50, 0})
+	f.Add([]byte{3, OpAlloc, 0, 4, OpAlloc, 0, 4, OpReset, OpMark, OpAlloc, 10, 0, OpReset, OpRollback})
+	f.Add([]byte{4, OpAlloc, 0, 3, OpReset, OpAlloc, 0, 3, OpReset, OpAlloc, 7, 0})
+	f.Add([]byte{5, OpMark, OpGrow, 0, 1, OpAlloc, 0, 1, OpRollback, OpAlloc, 32, 0})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) == 0 {
+			return
+		}
+		a := fuzzConfigs[int(data[0])%len(fuzzConfigs)]()
+		defer a.Release()
+		RunOps(t, a, data[1:])
+	})
+}
+
+func TestRunOpsAllocator(t *testing.T) {
+	// Allocators without Grow or marks skip those ops.
+	al := arena.NewSafeArena(256)
+	defer al.Release()
+	RunOps(t, al, []byte{OpAlloc, 8, 0, OpGrow, 8, 0, OpMark, OpAlloc, 100, 1, OpRollback, OpReset, OpAlloc, 1, 0})
+}
+
+func TestRunOpsDetectsOverlap(t *testing.T) {
+	ft := &fakeT{TB: t}
+	defer func() {
+		if r := recover(); r != nil && r != errFakeFatal {
+			panic(r)
+		}
+		if !ft.failed {
+			t.Error("RunOps did not detect overlapping allocations")
+		}
+	}()
+	RunOps(ft, overlapping{arena.NewArena(256)}, []byte{OpAlloc, 8, 0, OpAlloc, 8, 0})
+}
+
+// overlapping hands out the same memory for every allocation.
+type overlapping struct{ *arena.Arena }
+
+func (o overlapping) AllocBytes(n int) []byte {
+	b := o.Arena.AllocBytes(n)
+	o.Arena.Reset()
+	return b
+}
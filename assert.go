@@ -0,0 +1,40 @@
+package arena
+
+import "fmt"
+
+// AllocRequest describes an allocation checked by alloc assertions.
+type AllocRequest struct {
+	Size  int    // Requested size in bytes
+	Type  string // Type name for Alloc, AllocSlice and friends; "" for raw bytes
+	Arena string // Arena name set with WithName; "" if unnamed
+}
+
+// WithAllocAssertions checks every allocation against fns, which return an
+// error for requests that break a rule such as a maximum size or a
+// forbidden type. The first error panics with the request's details, so
+// violations fail fast where they happen instead of surfacing later as
+// memory growth. Assertions take the arena off its inlined fast path and
+// are meant for debug and staging builds. Calling it again adds more
+// assertions.
+func WithAllocAssertions(fns ...func(req AllocRequest) error) Option {
+	return func(a *Arena) {
+		d := a.debugState()
+		d.assertions = append(d.assertions, fns...)
+	}
+}
+
+// checkAlloc runs the alloc assertions for an n-byte allocation.
+func (a *Arena) checkAlloc(n int) {
+	d := a.debug
+	req := AllocRequest{Size: n, Type: d.nextType, Arena: a.name}
+	d.nextType = ""
+	for _, fn := range d.assertions {
+		if err := fn(req); err != nil {
+			typ := req.Type
+			if typ == "" {
+				typ = "[]byte"
+			}
+			a.panicWithEvents(fmt.Sprintf("arena: allocation assertion failed: %v (size=%d type=%s arena=%q)", err, n, typ, a.name))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package arena
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestAllocAssertions(t *testing.T) {
+	var seen []AllocRequest
+	a := NewArena(1024, WithName("ingest"), WithAllocAssertions(
+		func(req AllocRequest) error {
+			seen = append(seen, req)
+			return nil
+		},
+		func(req AllocRequest) error {
+			if req.Size > 256 {
+				return errors.New("allocation over 256 bytes")
+			}
+			return nil
+		},
+		func(req AllocRequest) error {
+			if req.Type == "*int" {
+				return errors.New("pointer types are forbidden")
+			}
+			return nil
+		},
+	))
+
+	a.AllocBytes(10)
+	Alloc[int64](a)
+	AllocSlice[uint16](a, 4)
+	want := []AllocRequest{
+		{Size: 10, Arena: "ingest"},
+		{Size: 8, Type: "int64", Arena: "ingest"},
+		{Size: 8, Type: "uint16", Arena: "ingest"},
+	}
+	if len(seen) != len(want) {
+		t.Fatalf("requests = %+v, want %+v", seen, want)
+	}
+	for i := range want {
+		if seen[i] != want[i] {
+			t.Errorf("request %d = %+v, want %+v", i, seen[i], want[i])
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		fn   func()
+		msg  string
+	}{
+		{"size", func() { a.AllocBytes(300) }, "allocation over 256 bytes (size=300 type=[]byte arena=\"ingest\")"},
+		{"type", func() { AllocUninitialized[*int](a) }, "pointer types are forbidden"},
+	} {
+		func() {
+			defer func() {
+				r := recover()
+				if msg, _ := r.(string); !strings.Contains(msg, tc.msg) {
+					t.Errorf("%s: recover() = %v, want message containing %q", tc.name, r, tc.msg)
+				}
+			}()
+			tc.fn()
+		}()
+	}
+}
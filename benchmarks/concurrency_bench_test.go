@@ -85,6 +85,18 @@ func BenchmarkConcurrencyPatterns(b *testing.B) {
 			})
 		})
 
+		b.Run(fmt.Sprintf("SafeArena_SpinLock_Contention_%dB", size), func(b *testing.B) {
+			s := arena.NewSafeArena(2*1024*1024, arena.WithSpinLock())
+			defer s.Release()
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.AllocBytes(size)
+				}
+			})
+		})
+
 		b.Run(fmt.Sprintf("Arena_PerGoroutine_%dB", size), func(b *testing.B) {
 			b.ResetTimer()
 			b.RunParallel(func(pb *testing.PB) {
@@ -218,6 +230,21 @@ func BenchmarkScalability(b *testing.B) {
 			})
 		})
 
+		b.Run(fmt.Sprintf("SafeArena_SpinLock_%dGoroutines", numGoroutines), func(b *testing.B) {
+			s := arena.NewSafeArena(4*1024*1024, arena.WithSpinLock())
+			defer s.Release()
+
+			oldProcs := runtime.GOMAXPROCS(numGoroutines)
+			defer runtime.GOMAXPROCS(oldProcs)
+
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					s.AllocBytes(128)
+				}
+			})
+		})
+
 		b.Run(fmt.Sprintf("Arena_PerGoroutine_%dGoroutines", numGoroutines), func(b *testing.B) {
 			oldProcs := runtime.GOMAXPROCS(numGoroutines)
 			defer runtime.GOMAXPROCS(oldProcs)
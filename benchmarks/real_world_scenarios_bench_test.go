@@ -294,6 +294,41 @@ func BenchmarkJSONProcessingScenarios(b *testing.B) {
 			}
 		})
 
+		b.Run("Arena_SliceOfPtrs", func(b *testing.B) {
+			a := arena.NewArena(256 * 1024)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				// Simulate parsing a complex JSON document
+				root := arena.Alloc[JSONObject](a)
+				root.ID = int64(i)
+				root.Name = "root"
+				root.Value = 3.14159
+				root.Tags = arena.AllocSlice[string](a, 5)
+				root.Children = arena.AllocSliceOfPtrs[JSONObject](a, 10)
+
+				// Create child objects
+				for j, child := range root.Children {
+					child.ID = int64(j)
+					child.Name = fmt.Sprintf("child_%d", j)
+					child.Value = float64(j) * 2.5
+					child.Tags = arena.AllocSlice[string](a, 3)
+
+					for k := range child.Tags {
+						child.Tags[k] = fmt.Sprintf("tag_%d", k)
+					}
+				}
+
+				// Simulate processing the parsed data
+				var sum float64
+				for _, child := range root.Children {
+					sum += child.Value
+				}
+
+				a.Reset()
+			}
+		})
+
 		b.Run("Builtin", func(b *testing.B) {
 			b.ResetTimer()
 
@@ -398,6 +433,51 @@ func BenchmarkGraphAlgorithmScenarios(b *testing.B) {
 			}
 		})
 
+		b.Run("Arena_Deque", func(b *testing.B) {
+			a := arena.NewArena(1024 * 1024) // 1MB arena
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				// Create graph nodes
+				nodes := arena.AllocSlice[*GraphNode](a, numNodes)
+				for j := range nodes {
+					nodes[j] = arena.Alloc[GraphNode](a)
+					nodes[j].ID = j
+					nodes[j].Value = int64(j * 2)
+					nodes[j].Edges = arena.AllocSlice[*GraphNode](a, 5) // 5 edges per node
+				}
+
+				// Connect nodes (create edges)
+				for j, node := range nodes {
+					for k := range node.Edges {
+						targetID := (j + k + 1) % numNodes
+						node.Edges[k] = nodes[targetID]
+					}
+				}
+
+				// Simulate graph traversal (BFS) with a growable queue
+				queue := arena.NewDeque[*GraphNode](a, 0)
+				queue.PushBack(nodes[0])
+				nodes[0].Visited = true
+				nodes[0].Distance = 0
+
+				for queue.Len() > 0 {
+					current, _ := queue.PopFront()
+
+					for _, neighbor := range current.Edges {
+						if neighbor != nil && !neighbor.Visited {
+							neighbor.Visited = true
+							neighbor.Distance = current.Distance + 1
+							neighbor.Parent = current
+							queue.PushBack(neighbor)
+						}
+					}
+				}
+
+				a.Reset()
+			}
+		})
+
 		b.Run("Builtin", func(b *testing.B) {
 			b.ResetTimer()
 
@@ -490,6 +570,26 @@ func BenchmarkConcurrentWorkloadScenarios(b *testing.B) {
 			}
 		})
 
+		b.Run("Arena_ForEach", func(b *testing.B) {
+			pool := arena.NewArenaPool(64 * 1024)
+			jobs := make([]int, numWorkers*jobsPerWorker)
+			for j := range jobs {
+				jobs[j] = j
+			}
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				arena.ForEach(pool, jobs, func(a *arena.Arena, job int) error {
+					buffer := a.AllocBytes(512)
+					result := arena.Alloc[int64](a)
+
+					buffer[0] = byte(job)
+					*result = int64(job)
+					return nil
+				}, arena.WithWorkers(numWorkers))
+			}
+		})
+
 		b.Run("SafeArena_Shared", func(b *testing.B) {
 			s := arena.NewSafeArena(512 * 1024)
 			defer s.Release()
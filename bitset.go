@@ -0,0 +1,98 @@
+package arena
+
+import "math/bits"
+
+// Bitset is a fixed-size set of bits stored in an arena, for dedup and
+// membership checks over a batch of small integer IDs. Its operations do
+// not allocate. Like a Vector, a Bitset used after the arena is reset
+// panics unless ClearAll is called first, which gives it fresh storage
+// from the arena, so one Bitset can serve every batch of a Reset loop. A
+// Bitset is not goroutine-safe.
+type Bitset struct {
+	a     *Arena
+	words []uint64
+	n     int
+	guard GenerationGuard
+}
+
+// NewBitset returns an empty Bitset of n bits allocated from a.
+// Panics if n < 0.
+func NewBitset(a *Arena, n int) *Bitset {
+	if n < 0 {
+		panic("arena: NewBitset called with negative size")
+	}
+	s := &Bitset{a: a, n: n}
+	s.alloc()
+	return s
+}
+
+// alloc gives s fresh zeroed storage from its arena.
+func (s *Bitset) alloc() {
+	s.words = AllocSliceZeroed[uint64](s.a, (s.n+63)/64)
+	s.guard = s.a.Guard()
+}
+
+// Len returns the number of bits in the set.
+func (s *Bitset) Len() int {
+	return s.n
+}
+
+// Set sets bit i. Panics if i is out of range.
+func (s *Bitset) Set(i int) {
+	s.check(i)
+	s.words[i/64] |= 1 << (uint(i) % 64)
+}
+
+// Clear clears bit i. Panics if i is out of range.
+func (s *Bitset) Clear(i int) {
+	s.check(i)
+	s.words[i/64] &^= 1 << (uint(i) % 64)
+}
+
+// Test reports whether bit i is set. Panics if i is out of range.
+func (s *Bitset) Test(i int) bool {
+	s.check(i)
+	return s.words[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// TestAndSet sets bit i and reports whether it was already set, which
+// makes dedup a single call:
+//
+//	if !seen.TestAndSet(id) {
+//		process(id)
+//	}
+func (s *Bitset) TestAndSet(i int) bool {
+	s.check(i)
+	w, mask := &s.words[i/64], uint64(1)<<(uint(i)%64)
+	was := *w&mask != 0
+	*w |= mask
+	return was
+}
+
+// Count returns the number of set bits.
+func (s *Bitset) Count() int {
+	s.guard.Check()
+	n := 0
+	for _, w := range s.words {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// ClearAll clears every bit. After the arena is reset it gives the set
+// fresh storage instead, making it usable again.
+func (s *Bitset) ClearAll() {
+	if !s.guard.Valid() {
+		s.alloc()
+		return
+	}
+	clear(s.words)
+}
+
+// check panics if the arena was reset or i is not a valid bit index.
+func (s *Bitset) check(i int) {
+	s.guard.Check()
+	if uint(i) >= uint(s.n) {
+		panic("arena: Bitset index out of range")
+	}
+}
@@ -0,0 +1,102 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBitset(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+	s := NewBitset(a, 130)
+	if s.Len() != 130 || s.Count() != 0 {
+		t.Fatalf("new Bitset: Len=%d Count=%d, want 130, 0", s.Len(), s.Count())
+	}
+	for _, i := range []int{0, 63, 64, 129} {
+		if s.TestAndSet(i) {
+			t.Errorf("TestAndSet(%d) = true on first set", i)
+		}
+		if !s.TestAndSet(i) || !s.Test(i) {
+			t.Errorf("bit %d not set after TestAndSet", i)
+		}
+	}
+	s.Set(5)
+	s.Clear(63)
+	if s.Test(63) || !s.Test(5) || s.Test(6) {
+		t.Error("Set/Clear did not update the right bits")
+	}
+	if n := s.Count(); n != 4 {
+		t.Errorf("Count = %d, want 4", n)
+	}
+	s.ClearAll()
+	if n := s.Count(); n != 0 {
+		t.Errorf("Count after ClearAll = %d, want 0", n)
+	}
+
+	for _, i := range []int{-1, 130} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Set(%d) did not panic", i)
+				}
+			}()
+			s.Set(i)
+		}()
+	}
+}
+
+func TestBitsetStaleUse(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+	s := NewBitset(a, 100)
+	a.Reset()
+	for name, f := range map[string]func(){
+		"Set":   func() { s.Set(1) },
+		"Test":  func() { s.Test(1) },
+		"Count": func() { s.Count() },
+	} {
+		if msg := panicMessage(f); !strings.Contains(msg, "used after Reset") {
+			t.Errorf("%s after Reset panicked with %q, want use after Reset", name, msg)
+		}
+	}
+}
+
+func TestBitsetAfterReset(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+	s := NewBitset(a, 100)
+	s.Set(10)
+	a.Reset()
+	// Reuse the memory the bitset had before the reset.
+	for i, b := 0, a.AllocBytes(16); i < len(b); i++ {
+		b[i] = 0xff
+	}
+	s.ClearAll()
+	if s.Test(10) || s.Count() != 0 {
+		t.Error("Bitset kept bits across an arena reset")
+	}
+	s.Set(20)
+	if !s.Test(20) || s.Count() != 1 {
+		t.Error("Bitset unusable after an arena reset")
+	}
+}
+
+func TestBitsetNoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable under the race detector")
+	}
+	a := NewArena(1 << 16)
+	defer a.Release()
+	s := NewBitset(a, 10000)
+	allocs := testing.AllocsPerRun(100, func() {
+		for i := 0; i < 10000; i += 7 {
+			if !s.TestAndSet(i) {
+				s.Clear(i)
+			}
+		}
+		_ = s.Count()
+	})
+	if allocs != 0 {
+		t.Errorf("Bitset operations made %.1f heap allocations per run, want 0", allocs)
+	}
+}
@@ -0,0 +1,100 @@
+package arena
+
+import (
+	"hash/maphash"
+	"math"
+)
+
+// Bloom is a Bloom filter stored in an arena: a probabilistic set that
+// answers "possibly present" or "definitely absent" in a fixed amount of
+// memory, for dedup and membership checks over large batches. Adding and
+// checking keys does not allocate. Like Bitset, a Bloom used after the
+// arena is reset panics unless ClearAll is called first. A Bloom is not
+// goroutine-safe.
+type Bloom struct {
+	bits Bitset
+	k    int
+	seed maphash.Seed
+}
+
+// NewBloom returns an empty Bloom filter allocated from a, sized to hold
+// n keys with a false positive rate of about fpRate. Panics unless n > 0
+// and 0 < fpRate < 1.
+func NewBloom(a *Arena, n int, fpRate float64) *Bloom {
+	if n <= 0 || !(fpRate > 0 && fpRate < 1) {
+		panic("arena: NewBloom needs n > 0 and 0 < fpRate < 1")
+	}
+	// m = -n ln p / (ln 2)^2 bits and k = m/n ln 2 hashes
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := max(int(math.Round(m/float64(n)*math.Ln2)), 1)
+	words := int(m+63) / 64
+	b := &Bloom{k: k, seed: maphash.MakeSeed()}
+	b.bits = Bitset{a: a, n: words * 64}
+	b.bits.alloc()
+	return b
+}
+
+// Bits returns the size of the filter in bits.
+func (b *Bloom) Bits() int {
+	return b.bits.n
+}
+
+// Hashes returns the number of bits set per key.
+func (b *Bloom) Hashes() int {
+	return b.k
+}
+
+// Add adds key to the filter.
+func (b *Bloom) Add(key []byte) {
+	b.add(maphash.Bytes(b.seed, key))
+}
+
+// AddString adds key to the filter.
+func (b *Bloom) AddString(key string) {
+	b.add(maphash.String(b.seed, key))
+}
+
+// Contains reports whether key may have been added. False positives occur
+// at about the rate the filter was sized for; false negatives do not.
+func (b *Bloom) Contains(key []byte) bool {
+	return b.contains(maphash.Bytes(b.seed, key))
+}
+
+// ContainsString is Contains for a string key.
+func (b *Bloom) ContainsString(key string) bool {
+	return b.contains(maphash.String(b.seed, key))
+}
+
+// ClearAll removes every key. After the arena is reset it makes the
+// filter usable again.
+func (b *Bloom) ClearAll() {
+	b.bits.ClearAll()
+}
+
+// add sets the k bits of hash h. The bit positions are derived from the
+// one 64-bit hash by double hashing (Kirsch and Mitzenmacher): h1 + i*h2.
+func (b *Bloom) add(h uint64) {
+	s := &b.bits
+	s.guard.Check()
+	h1, h2, m := h, h>>32|h<<32|1, uint64(s.n)
+	for i := 0; i < b.k; i++ {
+		bit := h1 % m
+		s.words[bit/64] |= 1 << (bit % 64)
+		h1 += h2
+	}
+}
+
+// contains reports whether the k bits of hash h are all set.
+func (b *Bloom) contains(h uint64) bool {
+	s := &b.bits
+	s.guard.Check()
+	h1, h2, m := h, h>>32|h<<32|1, uint64(s.n)
+	for i := 0; i < b.k; i++ {
+		bit := h1 % m
+		if s.words[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+		h1 += h2
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+package arena
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestBloom(t *testing.T) {
+	a := NewArena(1 << 16)
+	defer a.Release()
+	const n = 10000
+	b := NewBloom(a, n, 0.01)
+	if b.Bits()%64 != 0 || b.Bits() < 9*n || b.Hashes() != 7 {
+		t.Errorf("NewBloom(%d, 0.01): Bits=%d Hashes=%d, want about %d bits and 7 hashes", n, b.Bits(), b.Hashes(), 96*n/10)
+	}
+	for i := 0; i < n; i++ {
+		b.AddString("key" + strconv.Itoa(i))
+	}
+	for i := 0; i < n; i++ {
+		if !b.Contains([]byte("key" + strconv.Itoa(i))) {
+			t.Fatalf("false negative for key%d", i)
+		}
+	}
+	fp := 0
+	for i := 0; i < n; i++ {
+		if b.ContainsString("other" + strconv.Itoa(i)) {
+			fp++
+		}
+	}
+	if rate := float64(fp) / n; rate > 0.03 {
+		t.Errorf("false positive rate = %.3f, want about 0.01", rate)
+	}
+
+	b.ClearAll()
+	if b.ContainsString("key1") {
+		t.Error("Bloom contains a key after ClearAll")
+	}
+	b.Add([]byte("x"))
+	a.Reset()
+	if msg := panicMessage(func() { b.ContainsString("x") }); !strings.Contains(msg, "used after Reset") {
+		t.Errorf("ContainsString after Reset panicked with %q, want use after Reset", msg)
+	}
+	b.ClearAll()
+	if b.ContainsString("x") {
+		t.Error("Bloom kept keys across an arena reset")
+	}
+}
+
+func TestBloomInvalid(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+	for _, tc := range []struct {
+		n  int
+		fp float64
+	}{{0, 0.01}, {10, 0}, {10, 1}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("NewBloom(%d, %v) did not panic", tc.n, tc.fp)
+				}
+			}()
+			NewBloom(a, tc.n, tc.fp)
+		}()
+	}
+}
+
+func TestBloomNoAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are unreliable under the race detector")
+	}
+	a := NewArena(1 << 16)
+	defer a.Release()
+	b := NewBloom(a, 1000, 0.01)
+	key := []byte("payload")
+	allocs := testing.AllocsPerRun(100, func() {
+		b.Add(key)
+		b.AddString("payload")
+		_ = b.Contains(key) && b.ContainsString("other")
+	})
+	if allocs != 0 {
+		t.Errorf("Bloom operations made %.1f heap allocations per run, want 0", allocs)
+	}
+}
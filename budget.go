@@ -0,0 +1,129 @@
+package arena
+
+import "sync/atomic"
+
+// budget caps the chunk memory held by an arena. Child arenas get a budget
+// of their own whose parent is the one they were created from, so every
+// chunk a child adds is charged against each ancestor as well: a cap on the
+// root cannot be bypassed by allocating from children. Budgets are shared
+// across goroutines when children are handed to them, hence the atomics.
+type budget struct {
+	parent *budget
+	limit  int64        // bytes; 0 for no limit at this level
+	used   atomic.Int64 // chunk bytes held by the owning arena and its children
+}
+
+// charge adds n bytes to b and its ancestors. If any of them would exceed
+// its limit nothing is charged and charge returns false.
+func (b *budget) charge(n int64) bool {
+	for p := b; p != nil; p = p.parent {
+		if used := p.used.Add(n); p.limit > 0 && used > p.limit {
+			for q := b; q != p.parent; q = q.parent {
+				q.used.Add(-n)
+			}
+			return false
+		}
+	}
+	return true
+}
+
+// refund returns n previously charged bytes to b and its ancestors.
+func (b *budget) refund(n int64) {
+	for p := b; p != nil; p = p.parent {
+		p.used.Add(-n)
+	}
+}
+
+// remaining returns the bytes that can still be charged to b, which is the
+// smallest headroom along the chain, or -1 if no level has a limit.
+func (b *budget) remaining() int64 {
+	rem := int64(-1)
+	for p := b; p != nil; p = p.parent {
+		if p.limit <= 0 {
+			continue
+		}
+		if r := max(p.limit-p.used.Load(), 0); rem < 0 || r < rem {
+			rem = r
+		}
+	}
+	return rem
+}
+
+// WithBudget caps the chunk memory the arena may hold at n bytes, counting
+// chunks kept for reuse. Adding a chunk that would exceed the budget
+// panics. The cap also covers child arenas created with NewChild and the
+// chunk sets of other lifetime classes (see In), and containers allocating
+// from the arena draw on it like any other allocation. n <= 0 means no
+// limit at this level.
+func WithBudget(n int) Option {
+	return func(a *Arena) {
+		if a.budget == nil {
+			a.budget = &budget{}
+		}
+		a.budget.limit = int64(max(n, 0))
+	}
+}
+
+// NewChild creates an arena whose chunks are charged against a's budget
+// and, if limit > 0, against a sub-limit of limit bytes of its own. The
+// child is otherwise independent: it has its own chunks and is reset
+// separately. Release the child to return its memory to a's budget.
+// Unless overridden by opts, the child uses a's chunk size and growth
+// policy.
+func (a *Arena) NewChild(limit int, opts ...Option) *Arena {
+	a.panicIfReleased()
+	inherit := func(c *Arena) {
+		c.growth = a.growth
+		c.growthFunc = a.growthFunc
+		c.minChunkSize = a.minChunkSize
+		c.maxChunkSize = a.maxChunkSize
+		if a.budget != nil || limit > 0 {
+			c.budget = &budget{parent: a.budget, limit: int64(max(limit, 0))}
+		}
+	}
+	return NewArena(a.chunkSize, append([]Option{inherit}, opts...)...)
+}
+
+// RemainingBudget returns how many more bytes of chunk memory the arena
+// may add before hitting its own budget or that of any arena it was
+// derived from, whichever is tighter. Returns -1 if none of them is
+// limited.
+func (a *Arena) RemainingBudget() int {
+	if a.budget == nil {
+		return -1
+	}
+	return int(a.budget.remaining())
+}
+
+// newChunkBuf allocates a chunk buffer of size bytes, charging it to the
+// governor and the arena's budget.
+func (a *Arena) newChunkBuf(size int) []byte {
+	if !a.chargeGovernor(size, true) {
+		a.panicWithEvents("arena: governor limit exceeded")
+	}
+	if a.budget != nil {
+		if !a.budget.charge(int64(size)) {
+			a.refundGovernor(size)
+			a.panicWithEvents("arena: budget exceeded")
+		}
+		a.budgetHeld += int64(size)
+	}
+	if buf, ok := a.mapBuf(size); ok {
+		return buf
+	}
+	return make([]byte, size)
+}
+
+// freeChunkBuf returns a dropped chunk buffer to the governor and the
+// arena's budget, unmapping it if it was mapped.
+func (a *Arena) freeChunkBuf(buf []byte) {
+	size := len(buf)
+	a.refundGovernor(size)
+	if a.budget != nil {
+		a.budget.refund(int64(size))
+		a.budgetHeld -= int64(size)
+	}
+	if m := a.chunkMaps(); m != nil {
+		m.unmap(buf)
+	}
+}
@@ -0,0 +1,77 @@
+package arena
+
+import "testing"
+
+func TestBudgetSharedWithChildren(t *testing.T) {
+	a := NewArena(1024, WithBudget(4096))
+	if got := a.RemainingBudget(); got != 3072 {
+		t.Fatalf("RemainingBudget = %d, want 3072", got)
+	}
+
+	child := a.NewChild(2048)
+	if got := child.RemainingBudget(); got != 1024 {
+		t.Errorf("child RemainingBudget = %d, want 1024 (its sub-limit)", got)
+	}
+	if got := a.RemainingBudget(); got != 2048 {
+		t.Errorf("parent RemainingBudget after child = %d, want 2048", got)
+	}
+
+	mustAllocBytes(t, a.In(Transient), 100)
+	if got := child.RemainingBudget(); got != 1024 {
+		t.Errorf("child RemainingBudget = %d, want 1024", got)
+	}
+	if got := a.RemainingBudget(); got != 1024 {
+		t.Errorf("parent RemainingBudget after class chunk = %d, want 1024", got)
+	}
+
+	// The child's sub-limit would allow another chunk, the parent's does not.
+	mustAllocBytes(t, a, 1000)
+	mustAllocBytes(t, a, 1000)
+	if got := a.RemainingBudget(); got != 0 {
+		t.Errorf("parent RemainingBudget when full = %d, want 0", got)
+	}
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Error("Expected panic when a child exceeds the parent's budget")
+			}
+		}()
+		child.AllocBytes(1024)
+		child.AllocBytes(1024)
+	}()
+
+	child.Release()
+	if got := a.RemainingBudget(); got != 1024 {
+		t.Errorf("parent RemainingBudget after child Release = %d, want 1024", got)
+	}
+}
+
+func TestBudgetTrim(t *testing.T) {
+	a := NewArena(1024, WithBudget(2048))
+	mustAllocBytes(t, a, 1000)
+	mustAllocBytes(t, a, 1000)
+	if got := a.RemainingBudget(); got != 0 {
+		t.Fatalf("RemainingBudget = %d, want 0", got)
+	}
+	a.Reset()
+	a.Reset()
+	if freed := a.TrimCold(1); freed != 1024 {
+		t.Fatalf("TrimCold freed %d bytes, want 1024", freed)
+	}
+	if got := a.RemainingBudget(); got != 1024 {
+		t.Errorf("RemainingBudget after TrimCold = %d, want 1024", got)
+	}
+}
+
+func TestBudgetUnlimited(t *testing.T) {
+	a := NewArena(1024)
+	if got := a.RemainingBudget(); got != -1 {
+		t.Errorf("RemainingBudget without budget = %d, want -1", got)
+	}
+	if got := a.NewChild(0).RemainingBudget(); got != -1 {
+		t.Errorf("unlimited child RemainingBudget = %d, want -1", got)
+	}
+	if got := a.NewChild(4096).RemainingBudget(); got != 3072 {
+		t.Errorf("child RemainingBudget = %d, want 3072", got)
+	}
+}
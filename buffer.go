@@ -0,0 +1,238 @@
+package arena
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// minBufferCap is the capacity of a Buffer's first allocation.
+const minBufferCap = 64
+
+// minBufferRead is the smallest free space ReadFrom passes to Read.
+const minBufferRead = 512
+
+// Buffer is a variable-sized byte buffer whose contents live in an arena,
+// for building responses and other output without heap allocations. It
+// implements io.Writer, io.ByteWriter, io.StringWriter and io.ReaderFrom. When the buffer
+// is the most recent allocation in the arena it grows in place; otherwise
+// it moves to a larger allocation, leaving the old one to the next Reset.
+//
+// Like other arena memory, the contents are invalid once the arena is
+// reset or released; a Buffer written to after a Reset starts out empty.
+// Reading contents a reset invalidated yields nothing from Bytes and
+// String, panics instead when diagnostics are enabled (SetDebugLevel,
+// WithPoisoning and the like), and is an error from BytesE and StringE.
+type Buffer struct {
+	a   *Arena
+	buf []byte // len is the content length, cap the allocation
+	gen uint64
+}
+
+// NewBuffer returns an empty Buffer that allocates from a.
+func NewBuffer(a *Arena) *Buffer {
+	return &Buffer{a: a, gen: a.generation}
+}
+
+// Len returns the number of bytes written.
+func (b *Buffer) Len() int {
+	b.sync()
+	return len(b.buf)
+}
+
+// Cap returns the capacity of the buffer's current allocation.
+func (b *Buffer) Cap() int {
+	b.sync()
+	return cap(b.buf)
+}
+
+// Bytes returns the buffer's contents. Writes only append, so the slice
+// keeps its contents until the buffer or the arena is reset.
+func (b *Buffer) Bytes() []byte {
+	b.checkRead("Bytes")
+	b.sync()
+	return b.buf
+}
+
+// BytesE is Bytes, but returns an error wrapping ErrStalePointer if the
+// arena was reset or released since the contents were written.
+func (b *Buffer) BytesE() ([]byte, error) {
+	if err := b.stale(); err != nil {
+		return nil, err
+	}
+	b.sync()
+	return b.buf, nil
+}
+
+// String returns the contents as a string sharing the buffer's memory,
+// without copying. It is valid until the buffer or the arena is reset.
+func (b *Buffer) String() string {
+	b.checkRead("String")
+	b.sync()
+	if len(b.buf) == 0 {
+		return ""
+	}
+	return unsafe.String(&b.buf[0], len(b.buf))
+}
+
+// StringE is String, but returns an error wrapping ErrStalePointer if the
+// arena was reset or released since the contents were written.
+func (b *Buffer) StringE() (string, error) {
+	buf, err := b.BytesE()
+	if err != nil || len(buf) == 0 {
+		return "", err
+	}
+	return unsafe.String(&buf[0], len(buf)), nil
+}
+
+// Reset empties the buffer but keeps its allocation for reuse. Slices and
+// strings returned by Bytes and String must no longer be used.
+func (b *Buffer) Reset() {
+	b.sync()
+	b.buf = b.buf[:0]
+}
+
+// Grow ensures room for another n bytes without another allocation.
+// Panics if n < 0.
+func (b *Buffer) Grow(n int) {
+	if n < 0 {
+		panic("arena: Buffer.Grow called with negative count")
+	}
+	b.sync()
+	b.grow(n)
+}
+
+// Write appends p to the buffer. It always returns len(p), nil.
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.sync()
+	b.grow(len(p))
+	b.buf = append(b.buf, p...)
+	return len(p), nil
+}
+
+// WriteString appends s to the buffer. It always returns len(s), nil.
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.sync()
+	b.grow(len(s))
+	b.buf = append(b.buf, s...)
+	return len(s), nil
+}
+
+// WriteByte appends c to the buffer. It always returns nil.
+func (b *Buffer) WriteByte(c byte) error {
+	b.sync()
+	b.grow(1)
+	b.buf = append(b.buf, c)
+	return nil
+}
+
+// WriteRune appends the UTF-8 encoding of r. It returns the number of
+// bytes written and a nil error.
+func (b *Buffer) WriteRune(r rune) (int, error) {
+	b.sync()
+	b.grow(utf8.UTFMax)
+	n := len(b.buf)
+	b.buf = utf8.AppendRune(b.buf, r)
+	return len(b.buf) - n, nil
+}
+
+// ReadFrom appends data read from r until EOF and returns the number of
+// bytes read. Any error except io.EOF is returned.
+func (b *Buffer) ReadFrom(r io.Reader) (int64, error) {
+	b.sync()
+	var total int64
+	for {
+		b.grow(minBufferRead)
+		n, err := r.Read(b.buf[len(b.buf):cap(b.buf)])
+		if n < 0 {
+			panic("arena: Buffer.ReadFrom: reader returned negative count")
+		}
+		b.buf = b.buf[:len(b.buf)+n]
+		total += int64(n)
+		if err == io.EOF {
+			return total, nil
+		}
+		if err != nil {
+			return total, err
+		}
+	}
+}
+
+// grow makes room for n more bytes, in place if possible.
+func (b *Buffer) grow(n int) {
+	if n <= cap(b.buf)-len(b.buf) {
+		return
+	}
+	need := len(b.buf) + n
+	newCap := max(2*cap(b.buf), need, minBufferCap)
+	if ext, ok := b.a.extendInPlace(b.buf[:cap(b.buf)], newCap-cap(b.buf)); ok {
+		b.buf = ext[:len(b.buf)]
+		return
+	}
+	buf := b.a.AllocBytes(newCap)
+	b.buf = buf[:copy(buf, b.buf)]
+}
+
+// sync drops the contents if the arena was reset since they were written.
+func (b *Buffer) sync() {
+	if b.gen != b.a.generation {
+		b.buf = nil
+		b.gen = b.a.generation
+	}
+}
+
+// stale returns an error if the buffer holds contents that a reset of the
+// arena invalidated.
+func (b *Buffer) stale() error {
+	if b.gen == b.a.generation || len(b.buf) == 0 {
+		return nil
+	}
+	return b.a.staleError(b.gen, true)
+}
+
+// checkRead panics, when diagnostics are enabled, if op would read
+// contents that a reset invalidated.
+func (b *Buffer) checkRead(op string) {
+	if b.a.debug == nil {
+		return
+	}
+	if err := b.stale(); err != nil {
+		b.a.panicWithEvents(fmt.Sprintf("arena: Buffer.%s after the arena was reset: %v", op, err))
+	}
+}
+
+// extendInPlace grows b by n bytes without moving it if b ends at the bump
+// pointer of the current chunk and the chunk has room. Arenas with
+// diagnostics enabled never extend in place, since allocations carry
+// guards and stamps.
+func (a *Arena) extendInPlace(b []byte, n int) ([]byte, bool) {
+	c := a.currentChunk
+	if c == nil || a.debug != nil || len(b) == 0 || n <= 0 {
+		return nil, false
+	}
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+	end := uintptr(unsafe.Pointer(unsafe.SliceData(b))) + uintptr(len(b))
+	if end != base+c.offset || uintptr(n) > uintptr(len(c.buf))-c.offset {
+		return nil, false
+	}
+	c.offset += uintptr(n)
+	return unsafe.Slice(unsafe.SliceData(b), len(b)+n), true
+}
+
+// trimInPlace returns the capacity of b beyond its length to the current
+// chunk if b ends at the bump pointer, so the next allocation starts right
+// after it. It returns b with its capacity cut to its length either way.
+// Like extendInPlace it does nothing on arenas with diagnostics enabled.
+func (a *Arena) trimInPlace(b []byte) []byte {
+	c := a.currentChunk
+	if c == nil || a.debug != nil || cap(b) == len(b) {
+		return b[:len(b):len(b)]
+	}
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+	end := uintptr(unsafe.Pointer(unsafe.SliceData(b))) + uintptr(cap(b))
+	if end == base+c.offset {
+		c.offset -= uintptr(cap(b) - len(b))
+	}
+	return b[:len(b):len(b)]
+}
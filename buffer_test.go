@@ -0,0 +1,126 @@
+package arena
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+var (
+	_ io.Writer       = (*Buffer)(nil)
+	_ io.ByteWriter   = (*Buffer)(nil)
+	_ io.StringWriter = (*Buffer)(nil)
+	_ io.ReaderFrom   = (*Buffer)(nil)
+)
+
+func TestBuffer(t *testing.T) {
+	a := NewArena(4096)
+	b := NewBuffer(a)
+	fmt.Fprintf(b, "status=%d", 200)
+	b.WriteByte(' ')
+	b.WriteString("path=/")
+	b.WriteRune('é')
+	if got, want := b.String(), "status=200 path=/é"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+	s := b.String()
+
+	// Growing as the most recent allocation extends in place.
+	used := a.SizeInUse()
+	start := &b.Bytes()[0]
+	b.WriteString(strings.Repeat("x", 200))
+	if &b.Bytes()[0] != start {
+		t.Error("buffer moved although it was the tail allocation")
+	}
+	if got := a.SizeInUse() - used; got != b.Cap()-minBufferCap {
+		t.Errorf("in-place growth used %d bytes, want %d", got, b.Cap()-minBufferCap)
+	}
+
+	// Once something else is allocated, growth moves the contents.
+	a.AllocBytes(8)
+	n := b.Cap()
+	b.WriteString(strings.Repeat("y", n))
+	if &b.Bytes()[0] == start {
+		t.Error("buffer grew over a later allocation")
+	}
+	if s != "status=200 path=/é" || !strings.HasPrefix(b.String(), s+"xx") || b.Len() != len(s)+200+n {
+		t.Errorf("contents after moving = %q (len %d)", b.String(), b.Len())
+	}
+
+	b.Reset()
+	if b.Len() != 0 || b.Cap() == 0 {
+		t.Errorf("after Reset Len, Cap = %d, %d, want 0 and the old capacity", b.Len(), b.Cap())
+	}
+	b.WriteString("again")
+	a.Reset()
+	if b.Len() != 0 || b.String() != "" {
+		t.Errorf("after arena Reset Len = %d, want 0", b.Len())
+	}
+	b.WriteString("new cycle")
+	if b.String() != "new cycle" {
+		t.Errorf("String() = %q, want %q", b.String(), "new cycle")
+	}
+}
+
+func TestBufferReadAfterReset(t *testing.T) {
+	a := NewArena(4096)
+	b := NewBuffer(a)
+	b.WriteString("response")
+	if got, err := b.StringE(); got != "response" || err != nil {
+		t.Fatalf("StringE() = %q, %v", got, err)
+	}
+	a.Reset()
+	if _, err := b.BytesE(); !errors.Is(err, ErrStalePointer) {
+		t.Errorf("BytesE after arena Reset: err = %v, want ErrStalePointer", err)
+	}
+	if got := b.String(); got != "" {
+		t.Errorf("String() after arena Reset = %q, want empty", got)
+	}
+	// Writing starts a new cycle, so the buffer is no longer stale.
+	b.WriteString("next")
+	if got, err := b.StringE(); got != "next" || err != nil {
+		t.Errorf("StringE() = %q, %v, want the new contents", got, err)
+	}
+
+	// With diagnostics enabled reading stale contents panics.
+	d := NewArena(4096, WithPoisoning())
+	db := NewBuffer(d)
+	db.WriteString("response")
+	d.Reset()
+	msg := panicMessage(func() { db.Bytes() })
+	if !strings.Contains(msg, "Buffer.Bytes after the arena was reset") || !strings.Contains(msg, ErrStalePointer.Error()) {
+		t.Errorf("Bytes after arena Reset panicked with %q", msg)
+	}
+	// An empty buffer has nothing stale.
+	e := NewBuffer(d)
+	d.Reset()
+	if e.String() != "" {
+		t.Error("empty buffer returned contents")
+	}
+}
+
+func TestBufferNoHeapAllocs(t *testing.T) {
+	a := NewArena(1 << 16)
+	b := NewBuffer(a)
+	allocs := testing.AllocsPerRun(100, func() {
+		b.WriteString("header: value\r\n")
+		b.WriteByte('x')
+		b.Write([]byte("body"))
+		a.Reset()
+	})
+	if allocs != 0 {
+		t.Errorf("Buffer writes made %v heap allocations per run, want 0", allocs)
+	}
+}
+
+func TestBufferReadFrom(t *testing.T) {
+	a := NewArena(4096)
+	b := NewBuffer(a)
+	b.WriteString("head:")
+	n, err := b.ReadFrom(strings.NewReader(strings.Repeat("x", 3000)))
+	if n != 3000 || err != nil || b.Len() != 3005 || !strings.HasPrefix(b.String(), "head:xxx") {
+		t.Errorf("ReadFrom = %d, %v with %d bytes buffered", n, err, b.Len())
+	}
+}
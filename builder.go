@@ -0,0 +1,145 @@
+package arena
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// Builder publishes immutable views built in private arenas, the
+// read-copy-update pattern for configuration and routing snapshots. A
+// writer builds the next view in a Draft's arena while readers keep using
+// the current one; Commit swaps the new view in atomically, and the
+// previous view's arena goes back to the pool once a grace period has
+// passed and no reader holds it through Acquire.
+//
+// Readers using Load must be done with a view within the grace period.
+// Readers that may take longer use Acquire, which holds the view until
+// released. Builder is safe for concurrent use.
+type Builder[V any] struct {
+	pool  *ArenaPool
+	grace time.Duration
+	cur   atomic.Pointer[view[V]]
+}
+
+// view is a published value and the arena holding it.
+type view[V any] struct {
+	v       V
+	a       *Arena
+	pool    *ArenaPool
+	refs    atomic.Int64
+	expired atomic.Bool // grace period over
+	freed   atomic.Bool // arena returned to the pool
+}
+
+// NewBuilder returns a Builder drawing arenas from pool and retiring
+// replaced views after grace.
+func NewBuilder[V any](pool *ArenaPool, grace time.Duration) *Builder[V] {
+	return &Builder[V]{pool: pool, grace: grace}
+}
+
+// Draft is a view under construction in a private arena.
+type Draft[V any] struct {
+	b    *Builder[V]
+	a    *Arena
+	done bool
+}
+
+// Begin starts building a view in a fresh arena from the pool.
+func (b *Builder[V]) Begin() *Draft[V] {
+	return &Draft[V]{b: b, a: b.pool.Get()}
+}
+
+// Arena returns the draft's arena. The view passed to Commit must only
+// refer to memory allocated from it or from the heap.
+func (d *Draft[V]) Arena() *Arena {
+	return d.a
+}
+
+// Commit publishes v as the current view and retires the one it replaces.
+// The draft's arena must not be written to afterwards.
+func (d *Draft[V]) Commit(v V) {
+	if d.done {
+		panic("arena: Draft already committed or aborted")
+	}
+	d.done = true
+	d.b.publish(&view[V]{v: v, a: d.a, pool: d.b.pool})
+}
+
+// Abort discards the draft and returns its arena to the pool. It does
+// nothing after Commit, so it can be deferred.
+func (d *Draft[V]) Abort() {
+	if d.done {
+		return
+	}
+	d.done = true
+	d.b.pool.Put(d.a)
+}
+
+// Load returns the current view, or the zero V before the first Commit.
+// The view must not be used after the grace period following the Commit
+// that replaces it.
+func (b *Builder[V]) Load() V {
+	if cur := b.cur.Load(); cur != nil {
+		return cur.v
+	}
+	var zero V
+	return zero
+}
+
+// Acquire returns the current view and a function that releases it. The
+// view's arena is not retired before release is called.
+func (b *Builder[V]) Acquire() (V, func()) {
+	for {
+		cur := b.cur.Load()
+		if cur == nil {
+			var zero V
+			return zero, func() {}
+		}
+		cur.refs.Add(1)
+		if b.cur.Load() == cur {
+			return cur.v, cur.release
+		}
+		// Replaced in between; the reference may be the last one.
+		cur.release()
+	}
+}
+
+// Close retires the current view. Load returns the zero V afterwards.
+func (b *Builder[V]) Close() {
+	b.publish(nil)
+}
+
+// publish swaps in next and schedules the old view's retirement.
+func (b *Builder[V]) publish(next *view[V]) {
+	old := b.cur.Swap(next)
+	if old == nil {
+		return
+	}
+	if b.grace <= 0 {
+		old.expire()
+		return
+	}
+	time.AfterFunc(b.grace, old.expire)
+}
+
+// expire ends the view's grace period, freeing it if no reader holds it.
+func (v *view[V]) expire() {
+	v.expired.Store(true)
+	if v.refs.Load() == 0 {
+		v.free()
+	}
+}
+
+// release drops a reference taken by Acquire.
+func (v *view[V]) release() {
+	if v.refs.Add(-1) == 0 && v.expired.Load() {
+		v.free()
+	}
+}
+
+// free returns the view's arena to the pool exactly once.
+func (v *view[V]) free() {
+	if v.freed.CompareAndSwap(false, true) {
+		v.pool.Put(v.a)
+	}
+}
@@ -0,0 +1,117 @@
+package arena
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+type routeTable struct {
+	version int
+	routes  []string
+}
+
+func buildRoutes(d *Draft[*routeTable], version int) *routeTable {
+	a := d.Arena()
+	t := Alloc[routeTable](a)
+	t.version = version
+	t.routes = AllocSlice[string](a, 3)
+	for i := range t.routes {
+		t.routes[i] = CloneString(a, fmt.Sprintf("/v%d/route%d", version, i))
+	}
+	return t
+}
+
+func TestBuilderPublish(t *testing.T) {
+	b := NewBuilder[*routeTable](NewArenaPool(1024), 0)
+	if b.Load() != nil {
+		t.Fatal("Load() before Commit returned a view")
+	}
+
+	d := b.Begin()
+	d.Commit(buildRoutes(d, 1))
+	v1, release := b.Acquire()
+	if v1.version != 1 || v1.routes[2] != "/v1/route2" {
+		t.Fatalf("Acquire() = %+v", v1)
+	}
+
+	// An aborted draft leaves the current view alone.
+	d = b.Begin()
+	buildRoutes(d, 99)
+	d.Abort()
+	d.Abort()
+	if b.Load().version != 1 {
+		t.Errorf("Load().version after Abort = %d, want 1", b.Load().version)
+	}
+
+	d = b.Begin()
+	d.Commit(buildRoutes(d, 2))
+	if b.Load().version != 2 {
+		t.Errorf("Load().version = %d, want 2", b.Load().version)
+	}
+	// The acquired view outlives the (zero) grace period until released.
+	if v1.routes[0] != "/v1/route0" {
+		t.Errorf("acquired view changed after replacement: %q", v1.routes[0])
+	}
+	old := b.cur.Load()
+	release()
+
+	b.Close()
+	if b.Load() != nil || !old.freed.Load() {
+		t.Error("Close did not retire the current view")
+	}
+}
+
+func TestBuilderGracePeriod(t *testing.T) {
+	b := NewBuilder[*routeTable](NewArenaPool(1024), 20*time.Millisecond)
+	d := b.Begin()
+	d.Commit(buildRoutes(d, 1))
+	first := b.cur.Load()
+	d = b.Begin()
+	d.Commit(buildRoutes(d, 2))
+	if first.freed.Load() {
+		t.Fatal("replaced view freed before the grace period")
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !first.freed.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("replaced view never freed")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBuilderConcurrentReaders(t *testing.T) {
+	b := NewBuilder[*routeTable](NewArenaPool(1024), 0)
+	d := b.Begin()
+	d.Commit(buildRoutes(d, 0))
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for r := 0; r < 4; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				v, release := b.Acquire()
+				want := fmt.Sprintf("/v%d/route1", v.version)
+				if v.routes[1] != want {
+					t.Errorf("reader saw %q, want %q", v.routes[1], want)
+				}
+				release()
+			}
+		}()
+	}
+	for i := 1; i <= 200; i++ {
+		d := b.Begin()
+		d.Commit(buildRoutes(d, i))
+	}
+	close(stop)
+	wg.Wait()
+}
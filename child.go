@@ -0,0 +1,77 @@
+package arena
+
+import "slices"
+
+// Child returns a sub-arena for one stage of a larger lifetime, such as
+// the parse, plan and execute steps of a request. The child is an
+// ordinary Arena that can be Reset on its own, but it draws its chunks
+// from a's spare chunks before allocating new ones, and when it is
+// released its chunks go back to a as spares, so consecutive stages share
+// one set of chunks instead of each keeping its own:
+//
+//	parse := a.Child()
+//	ast := parseRequest(parse, body)
+//	plan := a.Child()
+//	p := buildPlan(plan, ast)
+//	parse.Release() // its chunks are reused by later stages
+//
+// Releasing a releases every child still alive, and their memory with it.
+// Resetting a does not touch its children. Children share a's budget and
+// chunk sizing, and have their own metrics. Like a, they are not
+// goroutine-safe and must be used by a's owner. Use NewChild instead for
+// an arena that only needs a budget of its own.
+func (a *Arena) Child() *Arena {
+	a.panicIfReleased()
+	ca := NewArena(a.chunkSize, WithLazyInit(), a.inherit("child"))
+	ca.parent = a
+	a.children = append(a.children, ca)
+	return ca
+}
+
+// borrowChunk takes a spare chunk of at least size bytes from the parent,
+// moving its accounting along with it.
+func (a *Arena) borrowChunk(size int) (chunk, bool) {
+	p := a.parent
+	if p == nil || p.chunks == nil {
+		return chunk{}, false
+	}
+	c, ok := p.takeSpare(size)
+	if ok {
+		p.moveCharge(a, len(c.buf))
+	}
+	return c, ok
+}
+
+// returnChunks hands the chunks of a released child to its parent as
+// spares and detaches the child.
+func (a *Arena) returnChunks() {
+	p := a.parent
+	a.parent = nil
+	p.children = slices.DeleteFunc(p.children, func(ca *Arena) bool { return ca == a })
+	for _, set := range [][]chunk{a.chunks, a.spare} {
+		for _, c := range set {
+			c.offset = p.chunkBase
+			c.zeroed = false
+			c.lastUsed = p.generation
+			a.moveCharge(p, len(c.buf))
+			p.spare = append(p.spare, c)
+		}
+	}
+	a.chunks = a.chunks[:0]
+	a.spare = nil
+}
+
+// moveCharge transfers what a chunk of size bytes is charged against, in
+// the shared budget and the governor, from a to b.
+func (a *Arena) moveCharge(b *Arena, size int) {
+	n := int64(size)
+	if a.budget != nil && a.budget == b.budget {
+		a.budgetHeld -= n
+		b.budgetHeld += n
+	}
+	if a.gov != nil {
+		n = min(n, a.gov.held.Load())
+		a.gov.held.Add(-n)
+		b.holdGovernor(n)
+	}
+}
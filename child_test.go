@@ -0,0 +1,62 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChildSharesChunks(t *testing.T) {
+	a := NewArena(1024, WithBudget(4096))
+	a.AllocBytes(512)
+
+	parse := a.Child()
+	parse.AllocBytes(900)
+	parse.AllocBytes(900)
+	if got := parse.Metrics().NumChunks; got != 2 {
+		t.Fatalf("child chunks = %d, want 2", got)
+	}
+	parse.Reset()
+	if a.SizeInUse() != 512 {
+		t.Errorf("parent SizeInUse = %d after resetting the child, want 512", a.SizeInUse())
+	}
+	parse.AllocBytes(100)
+	held := 4096 - a.RemainingBudget()
+	parse.Release()
+	if got := 4096 - a.RemainingBudget(); got != held {
+		t.Errorf("budget used = %d after releasing the child, want %d kept as spares", got, held)
+	}
+
+	// A later stage takes the released chunks instead of adding new ones.
+	plan := a.Child()
+	plan.AllocBytes(900)
+	plan.AllocBytes(900)
+	if got := 4096 - a.RemainingBudget(); got != held {
+		t.Errorf("budget used = %d after reusing the chunks, want %d", got, held)
+	}
+	plan.Release()
+
+	a.Release()
+	if got := a.RemainingBudget(); got != 4096 {
+		t.Errorf("RemainingBudget = %d after Release, want 4096", got)
+	}
+}
+
+func TestChildReleaseCascades(t *testing.T) {
+	a := NewArena(1024)
+	c := a.Child()
+	gc := c.Child()
+	gc.AllocBytes(10)
+	a.Reset()
+	gc.AllocBytes(10) // resetting the parent leaves children alone
+
+	a.Release()
+	for _, ca := range []*Arena{c, gc} {
+		msg := panicMessage(func() { ca.AllocBytes(1) })
+		if !strings.Contains(msg, "use after Release") {
+			t.Errorf("allocating from a child of a released arena panicked with %q", msg)
+		}
+	}
+	if msg := panicMessage(func() { a.Child() }); !strings.Contains(msg, "use after Release") {
+		t.Errorf("Child of a released arena panicked with %q", msg)
+	}
+}
@@ -0,0 +1,183 @@
+package arena
+
+import "slices"
+
+// ChunkInfo describes one chunk of an arena. Chunk age is measured in reset
+// cycles rather than wall time so that Reset never has to read the clock.
+type ChunkInfo struct {
+	Index      int    // Position of the chunk in the arena
+	Size       int    // Chunk size in bytes
+	Used       int    // Bytes currently allocated from the chunk
+	LastUsed   uint64 // Arena generation in which the chunk last held data
+	IdleResets uint64 // Reset cycles since the chunk last held data (0 if in use)
+	Hits       int    // Sampled cycles in which the chunk held data (WithAccessSampling)
+}
+
+// ChunkInfos returns a description of every chunk, in allocation order.
+func (a *Arena) ChunkInfos() []ChunkInfo {
+	infos := make([]ChunkInfo, len(a.chunks))
+	for i := range a.chunks {
+		infos[i] = a.chunkInfo(i)
+	}
+	return infos
+}
+
+func (a *Arena) chunkInfo(i int) ChunkInfo {
+	c := &a.chunks[i]
+	info := ChunkInfo{
+		Index:    i,
+		Size:     len(c.buf),
+		Used:     int(c.offset - a.chunkBase),
+		LastUsed: c.lastUsed,
+		Hits:     c.sampledHits(),
+	}
+	if info.Used == 0 {
+		info.IdleResets = a.generation - c.lastUsed
+	}
+	return info
+}
+
+// TrimCold frees empty chunks that have not held data for at least
+// minIdleResets reset cycles, keeping the hot working set while shedding
+// overflow chunks from occasional spikes. Spare chunks recycled by
+// ResetCommit are freed under the same rule. Chunks holding data, the
+// chunk currently being filled and the first chunk are never freed.
+// Returns the number of bytes freed.
+func (a *Arena) TrimCold(minIdleResets uint64) int {
+	a.panicIfReleased()
+	freed := a.dropChunks(func(info ChunkInfo) bool {
+		return info.IdleResets >= minIdleResets
+	})
+	kept := a.spare[:0]
+	for _, c := range a.spare {
+		if a.generation-c.lastUsed >= minIdleResets {
+			freed += len(c.buf)
+			a.freeChunkBuf(c.buf)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	clear(a.spare[len(kept):])
+	a.spare = kept
+	return a.noteTrim(freed)
+}
+
+// ShrinkTo frees empty chunks, coldest first, until the arena's capacity is
+// at most capacity bytes or no more chunks can be freed. With
+// WithAccessSampling, chunks used in fewer sampled cycles count as colder.
+// The same chunks as in TrimCold are exempt. Returns the number of bytes
+// freed.
+func (a *Arena) ShrinkTo(capacity int) int {
+	a.panicIfReleased()
+	return a.noteTrim(a.shrinkTo(capacity))
+}
+
+func (a *Arena) shrinkTo(capacity int) int {
+	excess := a.Capacity() - capacity
+	if excess <= 0 {
+		return 0
+	}
+
+	var candidates []ChunkInfo
+	for i := range a.chunks {
+		if info := a.chunkInfo(i); a.trimmable(info) {
+			candidates = append(candidates, info)
+		}
+	}
+	// Coldest first; among equals, larger chunks first to free fewer chunks.
+	slices.SortFunc(candidates, func(x, y ChunkInfo) int {
+		if x.Hits != y.Hits {
+			return x.Hits - y.Hits
+		}
+		if x.IdleResets != y.IdleResets {
+			if x.IdleResets > y.IdleResets {
+				return -1
+			}
+			return 1
+		}
+		return y.Size - x.Size
+	})
+
+	drop := make(map[int]bool)
+	for _, info := range candidates {
+		if excess <= 0 {
+			break
+		}
+		drop[info.Index] = true
+		excess -= info.Size
+	}
+	return a.dropChunks(func(info ChunkInfo) bool { return drop[info.Index] })
+}
+
+// ResetAndShrink resets the arena and then frees chunks until its capacity
+// is at most keepBytes, so a long-lived pooled arena returns the memory of
+// a burst to the runtime instead of holding it forever. Spare chunks kept
+// for reuse are freed as well. The first chunk is always kept, so
+// keepBytes <= 0 shrinks the arena to one chunk. Returns the number of
+// bytes freed.
+func (a *Arena) ResetAndShrink(keepBytes int) int {
+	a.Reset()
+	return a.shrinkAfterReset(keepBytes)
+}
+
+// WithRetention makes every Reset behave like ResetAndShrink(keepBytes):
+// capacity beyond keepBytes is freed once the cycle ends.
+func WithRetention(keepBytes int) Option {
+	return func(a *Arena) {
+		a.retainSet = true
+		a.retainBytes = keepBytes
+	}
+}
+
+// shrinkAfterReset frees spare chunks and then chunks beyond keepBytes.
+func (a *Arena) shrinkAfterReset(keepBytes int) int {
+	freed := 0
+	for _, c := range a.spare {
+		freed += len(c.buf)
+		a.freeChunkBuf(c.buf)
+	}
+	a.spare = nil
+	return a.noteTrim(freed + a.shrinkTo(max(keepBytes, 0)))
+}
+
+// noteTrim counts a trim that freed the given bytes for the metrics and
+// returns freed.
+func (a *Arena) noteTrim(freed int) int {
+	a.trims++
+	a.trimmedBytes += uint64(freed)
+	return freed
+}
+
+// trimmable reports whether a chunk may be freed by a shrink policy.
+func (a *Arena) trimmable(info ChunkInfo) bool {
+	return info.Index != 0 && info.Used == 0 && &a.chunks[info.Index] != a.currentChunk
+}
+
+// dropChunks removes trimmable chunks selected by pick and returns the
+// number of bytes freed.
+func (a *Arena) dropChunks(pick func(ChunkInfo) bool) int {
+	cur := a.chunkIndex(a.currentChunk)
+	freed := 0
+	kept := a.chunks[:0]
+	for i := range a.chunks {
+		info := a.chunkInfo(i)
+		if a.trimmable(info) && pick(info) {
+			freed += info.Size
+			a.freeChunkBuf(a.chunks[i].buf)
+			continue
+		}
+		if i == cur {
+			cur = len(kept)
+		}
+		kept = append(kept, a.chunks[i])
+	}
+	clear(a.chunks[len(kept):])
+	a.chunks = kept
+	if cur >= 0 {
+		a.currentChunk = &a.chunks[cur]
+	}
+	if a.debug != nil && a.debug.tags {
+		a.writeChunkTags() // chunk indices may have shifted
+	}
+	return freed
+}
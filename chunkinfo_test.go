@@ -0,0 +1,142 @@
+package arena
+
+import "testing"
+
+func TestResetRefillsExistingChunks(t *testing.T) {
+	a := NewArena(1024)
+	for cycle := 0; cycle < 5; cycle++ {
+		for i := 0; i < 3; i++ {
+			a.AllocBytes(1000)
+		}
+		a.Reset()
+	}
+	if a.NumChunks() != 3 {
+		t.Errorf("NumChunks after 5 identical cycles = %d, want 3", a.NumChunks())
+	}
+}
+
+func TestChunkInfos(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(1000)
+	a.AllocBytes(1000) // spike into a second chunk
+	a.Reset()
+	a.AllocBytes(100)
+	a.Reset()
+	a.AllocBytes(100)
+
+	infos := a.ChunkInfos()
+	if len(infos) != 2 {
+		t.Fatalf("len(ChunkInfos()) = %d, want 2", len(infos))
+	}
+	if infos[0].Used != 100 || infos[0].IdleResets != 0 {
+		t.Errorf("hot chunk = %+v, want Used 100 and IdleResets 0", infos[0])
+	}
+	if infos[1].Used != 0 || infos[1].IdleResets != 2 {
+		t.Errorf("cold chunk = %+v, want Used 0 and IdleResets 2", infos[1])
+	}
+}
+
+func TestTrimCold(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(1000)
+	a.AllocBytes(1000)
+	a.AllocBytes(1000)
+	a.Reset()
+	a.AllocBytes(1000)
+	a.AllocBytes(1000) // chunks 0 and 1 stay hot
+	a.Reset()
+
+	if freed := a.TrimCold(3); freed != 0 {
+		t.Errorf("TrimCold(3) freed %d bytes, want 0", freed)
+	}
+	if freed := a.TrimCold(2); freed != 1024 {
+		t.Errorf("TrimCold(2) freed %d bytes, want 1024", freed)
+	}
+	if a.NumChunks() != 2 {
+		t.Errorf("NumChunks after TrimCold = %d, want 2", a.NumChunks())
+	}
+
+	// Arena stays usable after trimming
+	a.AllocBytes(1000)
+	a.AllocBytes(1000)
+	if a.NumChunks() != 2 {
+		t.Errorf("NumChunks after refilling = %d, want 2", a.NumChunks())
+	}
+}
+
+func TestShrinkTo(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(1000)
+	a.AllocBytes(1000)
+	a.AllocBytes(4000) // large spike
+	a.Reset()
+	a.AllocBytes(1000)
+	a.AllocBytes(1000) // chunks 0 and 1 stay hot
+	a.Reset()
+
+	if freed := a.ShrinkTo(2048); freed != 4000 {
+		t.Errorf("ShrinkTo(2048) freed %d bytes, want 4000 (the cold spike chunk)", freed)
+	}
+	if a.Capacity() != 2048 {
+		t.Errorf("Capacity after ShrinkTo(2048) = %d, want 2048", a.Capacity())
+	}
+	if a.ShrinkTo(1<<20) != 0 {
+		t.Error("ShrinkTo above capacity freed memory")
+	}
+
+	// Chunks in use are never freed
+	b := a.AllocBytes(8)
+	a.ShrinkTo(0)
+	if a.NumChunks() != 1 {
+		t.Errorf("NumChunks after ShrinkTo(0) = %d, want 1", a.NumChunks())
+	}
+	copy(b, "still ok")
+}
+
+func TestResetAndShrink(t *testing.T) {
+	a := NewArena(1024)
+	for i := 0; i < 8; i++ {
+		a.AllocBytes(1000) // burst
+	}
+	if freed := a.ResetAndShrink(3000); freed != 6*1024 || a.NumChunks() != 2 {
+		t.Errorf("ResetAndShrink(3000) freed %d, %d chunks left, want %d and 2", freed, a.NumChunks(), 6*1024)
+	}
+	if freed := a.ResetAndShrink(0); freed != 1024 || a.Capacity() != 1024 {
+		t.Errorf("ResetAndShrink(0) freed %d, capacity %d, want 1024 and 1024", freed, a.Capacity())
+	}
+	if a.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after ResetAndShrink = %d, want 0", a.SizeInUse())
+	}
+}
+
+func TestWithRetention(t *testing.T) {
+	a := NewArena(1024, WithRetention(2048))
+	for cycle := 0; cycle < 3; cycle++ {
+		for i := 0; i < 5; i++ {
+			a.AllocBytes(1000)
+		}
+		a.Reset()
+		if a.Capacity() != 2048 {
+			t.Errorf("cycle %d: Capacity after Reset = %d, want 2048", cycle, a.Capacity())
+		}
+	}
+}
+
+func TestTrimColdSpares(t *testing.T) {
+	a := NewArena(1024)
+	for i := 0; i < 3; i++ {
+		a.AllocBytes(1000)
+	}
+	a.ResetPrepare()
+	a.ResetCommit() // the three chunks become spares
+	if freed := a.TrimCold(2); freed != 0 {
+		t.Errorf("TrimCold(2) freed %d bytes, want 0", freed)
+	}
+	if freed := a.TrimCold(1); freed != 3*1024 {
+		t.Errorf("TrimCold(1) freed %d bytes, want %d", freed, 3*1024)
+	}
+	m := a.Metrics()
+	if m.Trims != 2 || m.TrimmedBytes != 3*1024 {
+		t.Errorf("Trims, TrimmedBytes = %d, %d, want 2, %d", m.Trims, m.TrimmedBytes, 3*1024)
+	}
+}
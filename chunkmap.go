@@ -0,0 +1,161 @@
+package arena
+
+import (
+	"os"
+	"runtime"
+	"unsafe"
+)
+
+// WithMmap backs the arena's chunks with anonymous memory mappings (mmap on
+// Linux and macOS, VirtualAlloc on Windows) instead of the Go heap, so
+// that memory can be handed back to the operating system without
+// dropping the chunks. On Linux and Windows, Reset discards the pages the
+// cycle used while keeping the address space: resident memory falls back to zero between cycles
+// instead of staying at the peak for the life of a long-lived or pooled
+// arena, at the cost of a system call per used chunk on Reset and page
+// faults as the next cycle touches the pages again. On all three, Release
+// and the shrink policies unmap chunks right away.
+//
+// Mapped memory is not kept alive by slices into it: using arena memory
+// after Release, or after the arena becomes unreachable, faults instead of
+// reading stale data. Other lifetime classes (see In) and child arenas
+// draw on mappings too. On other platforms, with WithBackgroundZeroing,
+// or if a mapping fails, chunks come from the heap as usual.
+func WithMmap() Option {
+	return func(a *Arena) {
+		if a.maps == nil {
+			a.maps = &chunkMaps{live: make(map[*byte]chunkMapping)}
+			runtime.AddCleanup(a, (*chunkMaps).unmapAll, a.maps)
+		}
+	}
+}
+
+// chunkMaps tracks the chunk buffers an arena has mapped, keyed by their
+// first byte. It is separate from the arena so a cleanup can unmap them if
+// the arena is garbage collected without Release.
+type chunkMaps struct {
+	live map[*byte]chunkMapping
+	huge bool // set by WithHugePages
+}
+
+// chunkMapping is the mapping backing one chunk buffer.
+type chunkMapping struct {
+	mapped []byte // as returned by mapChunk
+	off    int    // where the chunk starts in mapped
+	huge   bool   // advised for huge pages
+}
+
+// option returns the option that maps chunks the same way.
+func (m *chunkMaps) option() Option {
+	if m.huge {
+		return WithHugePages()
+	}
+	return WithMmap()
+}
+
+// chunkMaps returns the mappings a's chunks are tracked in: a's own, or
+// those of the arena a child was created from, since children hand their
+// chunks back to it. It returns nil if chunks are not mapped.
+func (a *Arena) chunkMaps() *chunkMaps {
+	for ; a != nil; a = a.parent {
+		if a.maps != nil {
+			return a.maps
+		}
+	}
+	return nil
+}
+
+// mapBuf returns a mapped chunk buffer of size bytes, or false if chunks
+// are not mapped or the mapping failed.
+func (a *Arena) mapBuf(size int) ([]byte, bool) {
+	m := a.chunkMaps()
+	if m == nil || a.zeroer != nil {
+		return nil, false
+	}
+	gran := m.granule(m.huge)
+	n := (size + gran - 1) &^ (gran - 1)
+	slack := 0
+	if m.huge {
+		slack = hugePageSize // room to align the start
+	}
+	mapped, err := mapChunk(n + slack)
+	if err != nil {
+		return nil, false
+	}
+	cm := chunkMapping{mapped: mapped}
+	if m.huge {
+		cm.off = int(-uintptr(unsafe.Pointer(unsafe.SliceData(mapped))) & (hugePageSize - 1))
+		cm.huge = adviseHugePages(mapped[cm.off : cm.off+n])
+	}
+	buf := mapped[cm.off : cm.off+size : cm.off+size]
+	m.live[unsafe.SliceData(buf)] = cm
+	return buf, true
+}
+
+// granule returns the size mappings are rounded to: the page size, or the
+// huge page size for mappings advised for huge pages.
+func (m *chunkMaps) granule(huge bool) int {
+	if huge {
+		return hugePageSize
+	}
+	return os.Getpagesize()
+}
+
+// unmap unmaps buf if it is a tracked chunk buffer.
+func (m *chunkMaps) unmap(buf []byte) {
+	key := unsafe.SliceData(buf)
+	if cm, ok := m.live[key]; ok {
+		delete(m.live, key)
+		unmapChunk(cm.mapped)
+	}
+}
+
+// discard returns the pages of the first used bytes of buf to the
+// operating system if buf is a tracked chunk buffer. Huge pages are
+// discarded whole, so the kernel does not have to split them.
+func (m *chunkMaps) discard(buf []byte, used int) {
+	cm, ok := m.live[unsafe.SliceData(buf)]
+	if !ok {
+		return
+	}
+	gran := m.granule(cm.huge)
+	region := cm.mapped[cm.off:]
+	discardPages(region[:min((used+gran-1)&^(gran-1), len(region))])
+}
+
+// hugeBytes returns the capacity of the chunks that are backed by
+// mappings advised for huge pages.
+func (m *chunkMaps) hugeBytes(chunks []chunk) int {
+	n := 0
+	for _, c := range chunks {
+		if m.live[unsafe.SliceData(c.buf)].huge {
+			n += len(c.buf)
+		}
+	}
+	return n
+}
+
+// unmapAll unmaps every tracked chunk buffer.
+func (m *chunkMaps) unmapAll() {
+	for key, cm := range m.live {
+		delete(m.live, key)
+		unmapChunk(cm.mapped)
+	}
+}
+
+// unmapReleased unmaps the chunks of a released arena. Pinned chunks stay
+// mapped until the arena is collected, and the background zeroer must be
+// done with retired chunks first.
+func (a *Arena) unmapReleased() {
+	if a.maps == nil {
+		return
+	}
+	if a.zeroer != nil {
+		a.zeroer.wait()
+	}
+	for _, set := range [][]chunk{a.chunks, a.spare, a.retired} {
+		for _, c := range set {
+			a.maps.unmap(c.buf)
+		}
+	}
+}
@@ -0,0 +1,11 @@
+package arena
+
+// discardPages does nothing: the syscall package has no madvise on macOS,
+// so mapped chunks keep their pages until they are unmapped.
+func discardPages(b []byte) {}
+
+// adviseHugePages reports that this platform has no transparent huge
+// pages.
+func adviseHugePages(b []byte) bool {
+	return false
+}
@@ -0,0 +1,26 @@
+package arena
+
+import (
+	"bytes"
+	"os"
+	"syscall"
+)
+
+// discardPages releases the physical pages backing b, which is page
+// aligned. They read as zero afterwards.
+func discardPages(b []byte) {
+	if len(b) > 0 {
+		syscall.Madvise(b, syscall.MADV_DONTNEED)
+	}
+}
+
+// adviseHugePages asks for b, which is huge page aligned, to be backed by
+// transparent huge pages, and reports whether that may happen: the advice
+// was taken and huge pages are not disabled system-wide.
+func adviseHugePages(b []byte) bool {
+	if syscall.Madvise(b, syscall.MADV_HUGEPAGE) != nil {
+		return false
+	}
+	mode, err := os.ReadFile("/sys/kernel/mm/transparent_hugepage/enabled")
+	return err == nil && !bytes.Contains(mode, []byte("[never]"))
+}
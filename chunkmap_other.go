@@ -0,0 +1,21 @@
+//go:build !linux && !darwin && !windows
+
+package arena
+
+import "errors"
+
+// mapChunk reports that this platform has no anonymous mappings, so
+// chunks come from the heap.
+func mapChunk(size int) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func unmapChunk(mapped []byte) {}
+
+func discardPages(b []byte) {}
+
+// adviseHugePages reports that this platform has no transparent huge
+// pages.
+func adviseHugePages(b []byte) bool {
+	return false
+}
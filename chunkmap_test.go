@@ -0,0 +1,69 @@
+package arena
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestMmapChunks(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("page residency is only visible on Linux")
+	}
+	const size = 256 << 10
+	a := NewArena(size, WithMmap())
+	b := a.AllocBytes(size / 2)
+	for i := range b {
+		b[i] = 0xAA
+	}
+	if len(a.maps.live) != 1 {
+		t.Fatalf("%d mapped chunks, want 1", len(a.maps.live))
+	}
+	buf := a.chunks[0].buf
+	if got := residentBytes([][]byte{buf}); got < size/2 {
+		t.Fatalf("resident = %d after filling half the chunk, want at least %d", got, size/2)
+	}
+
+	a.Reset()
+	if got := residentBytes([][]byte{buf}); got != 0 {
+		t.Errorf("resident = %d after Reset, want 0", got)
+	}
+	for i, v := range a.AllocBytes(size / 2) {
+		if v != 0 {
+			t.Fatalf("byte %d = %#x after Reset, want 0", i, v)
+		}
+	}
+
+	// Overflow chunks are unmapped as soon as they are trimmed.
+	a.AllocBytes(size)
+	a.Reset()
+	a.Reset()
+	if freed := a.TrimCold(1); freed == 0 || len(a.maps.live) != 1 {
+		t.Errorf("TrimCold freed %d bytes, %d chunks still mapped, want 1", freed, len(a.maps.live))
+	}
+
+	a.Release()
+	if len(a.maps.live) != 0 {
+		t.Errorf("%d chunks still mapped after Release", len(a.maps.live))
+	}
+}
+
+func TestMmapDerivedArenas(t *testing.T) {
+	a := NewArena(4096, WithMmap())
+	if a.In(SessionScoped).maps == nil {
+		t.Error("lifetime class arena does not map its chunks")
+	}
+	c := a.Child()
+	c.AllocBytes(8192)
+	if c.maps != nil {
+		t.Error("child has mappings of its own")
+	}
+	n := len(a.maps.live)
+	c.Release()
+	if len(a.maps.live) != n {
+		t.Errorf("%d chunks mapped after releasing the child, want %d kept as spares", len(a.maps.live), n)
+	}
+	a.Release()
+	if len(a.maps.live) != 0 {
+		t.Errorf("%d chunks still mapped after Release", len(a.maps.live))
+	}
+}
@@ -0,0 +1,15 @@
+//go:build linux || darwin
+
+package arena
+
+import "syscall"
+
+// mapChunk maps size bytes of zeroed anonymous memory.
+func mapChunk(size int) ([]byte, error) {
+	return syscall.Mmap(-1, 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+}
+
+// unmapChunk unmaps a mapping returned by mapChunk.
+func unmapChunk(mapped []byte) {
+	syscall.Munmap(mapped)
+}
@@ -0,0 +1,48 @@
+package arena
+
+import (
+	"syscall"
+	"unsafe"
+)
+
+const (
+	memCommit     = 0x1000
+	memReserve    = 0x2000
+	memReset      = 0x80000
+	memRelease    = 0x8000
+	pageReadWrite = 0x04
+)
+
+var (
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	procVirtualAlloc = kernel32.NewProc("VirtualAlloc")
+	procVirtualFree  = kernel32.NewProc("VirtualFree")
+)
+
+// mapChunk reserves and commits size bytes of zeroed memory.
+func mapChunk(size int) ([]byte, error) {
+	p, _, err := procVirtualAlloc.Call(0, uintptr(size), memCommit|memReserve, pageReadWrite)
+	if p == 0 {
+		return nil, err
+	}
+	return unsafe.Slice((*byte)(unsafe.Add(nil, p)), size), nil
+}
+
+// unmapChunk frees a mapping returned by mapChunk.
+func unmapChunk(mapped []byte) {
+	procVirtualFree.Call(uintptr(unsafe.Pointer(unsafe.SliceData(mapped))), 0, memRelease)
+}
+
+// discardPages releases the physical pages backing b, which is page
+// aligned. The contents are undefined afterwards.
+func discardPages(b []byte) {
+	if len(b) > 0 {
+		procVirtualAlloc.Call(uintptr(unsafe.Pointer(unsafe.SliceData(b))), uintptr(len(b)), memReset, pageReadWrite)
+	}
+}
+
+// adviseHugePages reports that this platform has no transparent huge
+// pages.
+func adviseHugePages(b []byte) bool {
+	return false
+}
@@ -0,0 +1,114 @@
+package arena
+
+// Class is an allocation lifetime class. An Arena keeps a separate chunk set
+// per class so handlers mixing lifetimes can use one arena instead of
+// juggling several. Classes are ordered from shortest to longest lived, and
+// resetting a class also resets every shorter-lived one.
+type Class uint8
+
+const (
+	// Transient memory lives for a single step of a request, such as
+	// parsing scratch. It is reset with every RequestScoped reset and can be
+	// reset on its own more often.
+	Transient Class = iota
+	// RequestScoped memory lives until Reset. It is the arena's own chunk
+	// set, used by all allocations that don't name a class.
+	RequestScoped
+	// SessionScoped memory survives Reset and is only reclaimed by
+	// ResetClass(SessionScoped) or Release.
+	SessionScoped
+
+	numClasses
+)
+
+// String returns the class name.
+func (c Class) String() string {
+	switch c {
+	case Transient:
+		return "transient"
+	case RequestScoped:
+		return "request"
+	case SessionScoped:
+		return "session"
+	}
+	return "unknown"
+}
+
+// In returns the arena serving allocations of class c, for use with any
+// allocation function:
+//
+//	tmp := arena.AllocSlice[byte](a.In(arena.Transient), 4096)
+//
+// RequestScoped returns a itself. Other classes get their own chunk set,
+// created on first use with a's chunk size and growth policy, and owned by
+// a: do not Reset or Release them directly, use ResetClass. Metrics of a
+// cover RequestScoped only; query a.In(c) for the others.
+func (a *Arena) In(c Class) *Arena {
+	a.panicIfReleased()
+	switch c {
+	case RequestScoped:
+		return a
+	case Transient, SessionScoped:
+	default:
+		panic("arena: invalid allocation class")
+	}
+	i := classSlot(c)
+	if a.classes[i] == nil {
+		opts := []Option{WithLazyInit(), a.inherit(c.String())}
+		if a.maps != nil {
+			opts = append(opts, a.maps.option())
+		}
+		a.classes[i] = NewArena(a.chunkSize, opts...)
+	}
+	return a.classes[i]
+}
+
+// inherit returns an option giving a derived arena a's chunk sizing,
+// limits and budget, and a name under a's.
+func (a *Arena) inherit(suffix string) Option {
+	return func(ca *Arena) {
+		ca.growth = a.growth
+		ca.growthFunc = a.growthFunc
+		ca.minChunkSize = a.minChunkSize
+		ca.maxChunkSize = a.maxChunkSize
+		ca.maxChunks = a.maxChunks
+		ca.maxAlloc = a.maxAlloc
+		ca.emptySlices = a.emptySlices
+		ca.budget = a.budget
+		if a.name != "" {
+			ca.name = a.name + "/" + suffix
+		}
+	}
+}
+
+// ResetClass reclaims the memory of class c and of every shorter-lived
+// class. ResetClass(RequestScoped) is equivalent to Reset.
+func (a *Arena) ResetClass(c Class) {
+	switch c {
+	case Transient:
+		a.panicIfReleased()
+		a.resetClassArena(Transient)
+	case RequestScoped:
+		a.Reset()
+	case SessionScoped:
+		a.Reset()
+		a.resetClassArena(SessionScoped)
+	default:
+		panic("arena: invalid allocation class")
+	}
+}
+
+// classSlot maps a class other than RequestScoped to its index in a.classes.
+func classSlot(c Class) int {
+	if c == Transient {
+		return 0
+	}
+	return 1
+}
+
+// resetClassArena resets the chunk set of class c if it was ever used.
+func (a *Arena) resetClassArena(c Class) {
+	if ca := a.classes[classSlot(c)]; ca != nil {
+		ca.Reset()
+	}
+}
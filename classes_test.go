@@ -0,0 +1,78 @@
+package arena
+
+import "testing"
+
+func TestAllocationClasses(t *testing.T) {
+	a := NewArena(1024)
+	if a.In(RequestScoped) != a {
+		t.Error("In(RequestScoped) is not the arena itself")
+	}
+	tr, se := a.In(Transient), a.In(SessionScoped)
+	if tr == se || tr == a {
+		t.Fatal("classes share a chunk set")
+	}
+	if a.In(Transient) != tr {
+		t.Error("In(Transient) returned a different arena on second call")
+	}
+	if se.NumChunks() != 0 {
+		t.Errorf("class NumChunks before use = %d, want 0", se.NumChunks())
+	}
+
+	sess := Alloc[int64](se)
+	*sess = 7
+	mustAllocBytes(t, tr, 100)
+	mustAllocBytes(t, a, 200)
+
+	a.ResetClass(Transient)
+	if tr.SizeInUse() != 0 || a.SizeInUse() == 0 {
+		t.Errorf("after ResetClass(Transient): transient %d, request %d bytes in use, want 0 and > 0",
+			tr.SizeInUse(), a.SizeInUse())
+	}
+
+	mustAllocBytes(t, tr, 100)
+	a.Reset()
+	if tr.SizeInUse() != 0 || a.SizeInUse() != 0 {
+		t.Error("Reset() did not reset the request and transient classes")
+	}
+	if se.SizeInUse() == 0 || *sess != 7 {
+		t.Error("Reset() reclaimed session memory")
+	}
+
+	a.ResetClass(SessionScoped)
+	if se.SizeInUse() != 0 {
+		t.Errorf("session SizeInUse after ResetClass(SessionScoped) = %d, want 0", se.SizeInUse())
+	}
+}
+
+func mustAllocBytes(t *testing.T, a *Arena, n int) {
+	t.Helper()
+	if b := a.AllocBytes(n); len(b) != n {
+		t.Fatalf("len(AllocBytes(%d)) = %d", n, len(b))
+	}
+}
+
+func TestAllocationClassesRelease(t *testing.T) {
+	a := NewArena(1024, WithName("handler"))
+	se := a.In(SessionScoped)
+	if se.Name() != "handler/session" {
+		t.Errorf("class Name() = %q, want %q", se.Name(), "handler/session")
+	}
+	se.AllocBytes(10)
+	a.Release()
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic on class use after Release()")
+		}
+	}()
+	se.AllocBytes(1)
+}
+
+func TestInvalidClass(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic for invalid class")
+		}
+	}()
+	NewArena(1024).In(numClasses)
+}
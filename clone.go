@@ -0,0 +1,133 @@
+package arena
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// CloneBytes is a drop-in replacement for bytes.Clone that places the copy
+// in the arena. As with bytes.Clone, a nil b yields nil and an empty non-nil
+// b yields an empty non-nil slice.
+func CloneBytes(a *Arena, b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	if len(b) == 0 {
+		return []byte{}
+	}
+	dst := a.AllocBytes(len(b))
+	copy(dst, b)
+	return dst
+}
+
+// CloneString is a drop-in replacement for strings.Clone that places the
+// copy in the arena. The returned string is only valid until the arena is
+// reset or released.
+func CloneString(a *Arena, s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	dst := a.AllocBytes(len(s))
+	copy(dst, s)
+	return BytesToString(dst)
+}
+
+// AllocBytesCopy allocates len(src) bytes and copies src into them, for
+// moving incoming network buffers into the arena in one step. Unlike
+// CloneBytes it follows AllocBytes and returns nil for an empty src.
+func (a *Arena) AllocBytesCopy(src []byte) []byte {
+	dst := a.AllocBytes(len(src))
+	copy(dst, src)
+	return dst
+}
+
+// BytesToString returns a string sharing b's memory, without copying. It
+// is only valid while b is: for arena memory, until the arena is reset or
+// released, after which the string's contents change underneath it. b
+// must not be modified while the string is in use, since Go assumes
+// strings are immutable; maps keyed by such a string break if it changes.
+// Use CloneString for a string that outlives the arena.
+func BytesToString(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	return unsafe.String(&b[0], len(b))
+}
+
+// Clone returns a new arena holding a copy of everything allocated from a
+// since its last reset, laid out the same way, so work can branch: run a
+// speculative step on the clone and release whichever copy loses. The
+// clone has a's chunk sizing, limits and budget, and allocations from
+// either arena afterwards do not affect the other. Pointers into a are
+// translated to the clone with Relocate; values stored in the copied
+// memory still point into a and must be relocated by the caller.
+func (a *Arena) Clone() *Arena {
+	a.panicIfReleased()
+	opts := []Option{WithLazyInit(), a.inherit("clone")}
+	if a.maps != nil {
+		opts = append(opts, a.maps.option())
+	}
+	ca := NewArena(a.chunkSize, opts...)
+	ca.chunkBase = a.chunkBase
+	ca.nextChunk = a.nextChunk
+	if a.template != nil {
+		ca.template = append([]byte(nil), a.template...)
+	}
+	ca.chunks = make([]chunk, len(a.chunks))
+	for i, c := range a.chunks {
+		ca.chunks[i] = chunk{
+			buf:      ca.newChunkBuf(len(c.buf)),
+			offset:   c.offset,
+			lastUsed: ca.generation,
+			zeroed:   true,
+		}
+		copy(ca.chunks[i].buf, c.buf[:c.offset])
+	}
+	if i := a.chunkIndex(a.currentChunk); i >= 0 {
+		ca.currentChunk = &ca.chunks[i]
+	}
+	ca.requested = a.requested
+	origin := ca.layoutOf(a)
+	ca.origin = &origin
+	return ca
+}
+
+// cloneOrigin identifies the chunk layouts of a Clone and its source.
+// Resets and trims change them.
+type cloneOrigin struct {
+	srcGen, srcTrims uint64
+	gen, trims       uint64
+}
+
+// layoutOf returns the cloneOrigin a would have if it were a clone of src
+// made now.
+func (a *Arena) layoutOf(src *Arena) cloneOrigin {
+	return cloneOrigin{src.generation, src.trims, a.generation, a.trims}
+}
+
+// Relocate translates p, a pointer to memory allocated from the arena from
+// before to was created from it by Clone, to the same position in to.
+// Returns nil if p is nil. It panics if to was not made by Clone, if p
+// does not point into from, or if either arena has been reset, trimmed or
+// released since the Clone.
+func Relocate[T any](from, to *Arena, p *T) *T {
+	if p == nil {
+		return nil
+	}
+	if to.origin == nil {
+		panic("arena: Relocate to an arena not made by Clone")
+	}
+	if *to.origin != to.layoutOf(from) {
+		to.panicWithEvents("arena: Relocate after a reset or trim of the clone or its source")
+	}
+	addr := uintptr(unsafe.Pointer(p))
+	for i := range min(len(from.chunks), len(to.chunks)) {
+		c := &from.chunks[i]
+		base := uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+		if addr >= base && addr < base+c.offset && len(to.chunks[i].buf) == len(c.buf) {
+			return (*T)(unsafe.Add(unsafe.Pointer(unsafe.SliceData(to.chunks[i].buf)), addr-base))
+		}
+	}
+	from.panicWithEvents(fmt.Sprintf("arena: Relocate: %v", ErrNotOwned))
+	return nil
+}
@@ -0,0 +1,117 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestCloneBytes(t *testing.T) {
+	a := NewArena(1024)
+	src := []byte("payload")
+
+	dst := CloneBytes(a, src)
+	if string(dst) != "payload" {
+		t.Errorf("CloneBytes() = %q, want %q", dst, "payload")
+	}
+	src[0] = 'P'
+	if dst[0] != 'p' {
+		t.Error("CloneBytes() result aliases the source")
+	}
+
+	if CloneBytes(a, nil) != nil {
+		t.Error("CloneBytes(nil) should return nil")
+	}
+	if empty := CloneBytes(a, []byte{}); empty == nil || len(empty) != 0 {
+		t.Errorf("CloneBytes([]byte{}) = %#v, want empty non-nil", empty)
+	}
+}
+
+func TestCloneString(t *testing.T) {
+	a := NewArena(1024)
+
+	if got := CloneString(a, "hello"); got != "hello" {
+		t.Errorf("CloneString() = %q, want %q", got, "hello")
+	}
+	if got := CloneString(a, ""); got != "" {
+		t.Errorf("CloneString(\"\") = %q, want empty", got)
+	}
+	if a.SizeInUse() != 5 {
+		t.Errorf("SizeInUse = %d, want 5", a.SizeInUse())
+	}
+}
+
+func TestAllocBytesCopy(t *testing.T) {
+	a := NewArena(1024)
+	src := []byte("GET / HTTP/1.1")
+	dst := a.AllocBytesCopy(src)
+	src[0] = 'P'
+	if string(dst) != "GET / HTTP/1.1" {
+		t.Errorf("AllocBytesCopy() = %q, want an independent copy", dst)
+	}
+	if a.AllocBytesCopy(nil) != nil || a.AllocBytesCopy([]byte{}) != nil {
+		t.Error("AllocBytesCopy of an empty slice != nil")
+	}
+
+	s := BytesToString(dst)
+	if s != "GET / HTTP/1.1" || unsafe.StringData(s) != &dst[0] {
+		t.Errorf("BytesToString() = %q, want a view of the arena bytes", s)
+	}
+	if BytesToString(nil) != "" {
+		t.Error("BytesToString(nil) != \"\"")
+	}
+}
+
+func TestArenaClone(t *testing.T) {
+	type node struct {
+		val  int
+		next *node
+	}
+	a := NewArena(256, WithBudget(4096))
+	a.AllocBytes(300) // spill into a second chunk
+	head := Alloc[node](a)
+	head.val = 1
+	head.next = Alloc[node](a)
+	head.next.val = 2
+
+	b := a.Clone()
+	if b.SizeInUse() != a.SizeInUse() || b.NumChunks() != a.NumChunks() {
+		t.Fatalf("clone SizeInUse, NumChunks = %d, %d, want %d, %d", b.SizeInUse(), b.NumChunks(), a.SizeInUse(), a.NumChunks())
+	}
+	if got := a.RemainingBudget(); got != 4096-2*a.Capacity() {
+		t.Errorf("RemainingBudget = %d, want the clone charged to the shared budget", got)
+	}
+
+	// Relocate the list into the clone and let the branches diverge.
+	bh := Relocate(a, b, head)
+	if bh == head || bh.val != 1 {
+		t.Fatalf("relocated head = %p (%+v), want a copy of %p", bh, *bh, head)
+	}
+	bh.next = Relocate(a, b, bh.next)
+	bh.next.val = 20
+	Alloc[node](b).val = 3
+	if head.next.val != 2 {
+		t.Errorf("source value = %d after writing the clone, want 2", head.next.val)
+	}
+	if Relocate[node](a, b, nil) != nil {
+		t.Error("Relocate(nil) != nil")
+	}
+
+	heap := &node{}
+	if msg := panicMessage(func() { Relocate(a, b, heap) }); !strings.Contains(msg, ErrNotOwned.Error()) {
+		t.Errorf("relocating heap memory panicked with %q", msg)
+	}
+	if msg := panicMessage(func() { Relocate(b, a, bh) }); !strings.Contains(msg, "not made by Clone") {
+		t.Errorf("relocating into the source panicked with %q", msg)
+	}
+	a.Reset()
+	if msg := panicMessage(func() { Relocate(a, b, head) }); !strings.Contains(msg, "after a reset") {
+		t.Errorf("relocating after Reset panicked with %q", msg)
+	}
+
+	b.Release()
+	a.Release()
+	if got := a.RemainingBudget(); got != 4096 {
+		t.Errorf("RemainingBudget = %d after releasing both, want 4096", got)
+	}
+}
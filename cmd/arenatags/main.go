@@ -0,0 +1,31 @@
+// Command arenatags scans a raw memory image (for example a core dump) for
+// arena memory tags and prints one line per tagged chunk.
+//
+// Usage:
+//
+//	arenatags core.1234
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pavanmanishd/arena"
+)
+
+func main() {
+	if len(os.Args) != 2 {
+		fmt.Fprintln(os.Stderr, "usage: arenatags <image>")
+		os.Exit(2)
+	}
+	image, err := os.ReadFile(os.Args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "arenatags:", err)
+		os.Exit(1)
+	}
+	for _, t := range arena.ScanChunkTags(image) {
+		fmt.Printf("offset=%#x name=%q chunk=%d generation=%d used=%d/%d (%.1f%%)\n",
+			t.Offset, t.Name, t.Chunk, t.Generation, t.Used, t.Size,
+			100*float64(t.Used)/float64(max(t.Size, 1)))
+	}
+}
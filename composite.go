@@ -0,0 +1,57 @@
+package arena
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// AllocSliceOfPtrs returns n pointers to zeroed values of type T. The
+// values and the slice holding the pointers are both allocated in the
+// arena: the values from one block, so walking them is cache friendly.
+// T is subject to the PointerPolicy; the slice is not, since its
+// pointers refer only to arena memory. Returns nil if n <= 0.
+func AllocSliceOfPtrs[T any](a *Arena, n int) []*T {
+	if n <= 0 {
+		return nil
+	}
+	vals := AllocSliceZeroed[T](a, n)
+	ptrs := allocSpine[*T](a, n)
+	for i := range vals {
+		ptrs[i] = &vals[i]
+	}
+	return ptrs
+}
+
+// AllocMatrix returns a rows x cols matrix of zeroed values of type T.
+// The elements are one contiguous block in row-major order and the row
+// headers are allocated in the arena as well. Each row's capacity ends
+// at the row, so appending to a row never overwrites the next.
+// Returns nil if rows <= 0 or cols < 0.
+func AllocMatrix[T any](a *Arena, rows, cols int) [][]T {
+	if rows <= 0 || cols < 0 {
+		return nil
+	}
+	if cols > 0 && rows > math.MaxInt/cols {
+		a.panicWithEvents(fmt.Sprintf("%v: %d x %d matrix overflows int", ErrAllocTooLarge, rows, cols))
+	}
+	data := AllocSliceZeroed[T](a, rows*cols)
+	m := allocSpine[[]T](a, rows)
+	for i := range m {
+		m[i] = data[i*cols : (i+1)*cols : (i+1)*cols]
+	}
+	return m
+}
+
+// allocSpine allocates an uninitialized slice of n E for composite
+// helpers to fill with references into the arena. It skips the
+// PointerPolicy check that such element types would otherwise trip.
+func allocSpine[E any](a *Arena, n int) []E {
+	total, err := sliceSize[E](a, n)
+	if err != nil {
+		a.panicWithEvents(err.Error())
+	}
+	countType[E](a, total)
+	b := allocFor[E](a, total)
+	return unsafe.Slice((*E)(unsafe.Pointer(&b[0])), n)
+}
@@ -0,0 +1,128 @@
+package arena
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestAllocSliceOfPtrs(t *testing.T) {
+	type node struct{ id, weight int64 }
+	a := NewArena(4096)
+	a.AllocBytes(100) // dirty the chunk so zeroing is exercised
+	a.Reset()
+
+	ptrs := AllocSliceOfPtrs[node](a, 10)
+	if len(ptrs) != 10 {
+		t.Fatalf("len = %d, want 10", len(ptrs))
+	}
+	for i, p := range ptrs {
+		if *p != (node{}) {
+			t.Errorf("element %d = %+v, want zero", i, *p)
+		}
+		p.id = int64(i)
+	}
+	if got, want := a.SizeInUse(), 10*16+10*8; got != want {
+		t.Errorf("SizeInUse = %d, want %d for the values and the pointers", got, want)
+	}
+	for i, p := range ptrs {
+		if p.id != int64(i) {
+			t.Errorf("element %d has id %d", i, p.id)
+		}
+	}
+	if AllocSliceOfPtrs[node](a, 0) != nil {
+		t.Error("AllocSliceOfPtrs(0) != nil")
+	}
+
+	// The pointer slice itself does not trip PointerForbid.
+	SetPointerPolicy(PointerForbid)
+	defer SetPointerPolicy(PointerAllow)
+	AllocSliceOfPtrs[node](a, 3)
+}
+
+func TestAllocMatrix(t *testing.T) {
+	a := NewArena(4096)
+	m := AllocMatrix[int32](a, 3, 4)
+	if len(m) != 3 {
+		t.Fatalf("rows = %d, want 3", len(m))
+	}
+	for i := range m {
+		if len(m[i]) != 4 || cap(m[i]) != 4 {
+			t.Fatalf("row %d has len %d cap %d, want 4 and 4", i, len(m[i]), cap(m[i]))
+		}
+		for j := range m[i] {
+			m[i][j] = int32(i*10 + j)
+		}
+	}
+	// Rows are consecutive in one block.
+	flat := unsafe.Slice(&m[0][0], 12)
+	for k, v := range flat {
+		if want := int32(k/4*10 + k%4); v != want {
+			t.Errorf("flat[%d] = %d, want %d", k, v, want)
+		}
+	}
+	// Appending to a row copies instead of overwriting the next row.
+	m[0] = append(m[0], 99)
+	if m[1][0] != 10 {
+		t.Errorf("m[1][0] = %d after appending to row 0, want 10", m[1][0])
+	}
+
+	if AllocMatrix[int](a, 0, 5) != nil || AllocMatrix[int](a, 2, -1) != nil {
+		t.Error("AllocMatrix with no rows or negative cols != nil")
+	}
+	if e := AllocMatrix[int](a, 2, 0); len(e) != 2 || len(e[0]) != 0 {
+		t.Errorf("AllocMatrix(2, 0) = %v, want two empty rows", e)
+	}
+
+	msg := panicMessage(func() { AllocMatrix[byte](a, math.MaxInt/2, 3) })
+	if !strings.Contains(msg, ErrAllocTooLarge.Error()) {
+		t.Errorf("overflowing AllocMatrix panicked with %q", msg)
+	}
+}
+
+func BenchmarkAllocSliceOfPtrs(b *testing.B) {
+	type node struct{ id, weight int64 }
+	for _, n := range []int{16, 256} {
+		b.Run(fmt.Sprintf("Heap-%d", n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				ptrs := make([]*node, n)
+				for j := range ptrs {
+					ptrs[j] = &node{}
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Arena-%d", n), func(b *testing.B) {
+			a := NewArena(1 << 20)
+			for i := 0; i < b.N; i++ {
+				AllocSliceOfPtrs[node](a, n)
+				if i%100 == 99 {
+					a.Reset()
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkAllocMatrix(b *testing.B) {
+	for _, n := range []int{8, 64} {
+		b.Run(fmt.Sprintf("Heap-%dx%d", n, n), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				m := make([][]float64, n)
+				for j := range m {
+					m[j] = make([]float64, n)
+				}
+			}
+		})
+		b.Run(fmt.Sprintf("Arena-%dx%d", n, n), func(b *testing.B) {
+			a := NewArena(1 << 20)
+			for i := 0; i < b.N; i++ {
+				AllocMatrix[float64](a, n, n)
+				if i%10 == 9 {
+					a.Reset()
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package arena
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// contextKey is the context key for the arena stored by NewContext.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx that carries a, so code deeper in a call
+// chain can allocate from the arena of the request or task it serves
+// without threading it through every signature.
+func NewContext(ctx context.Context, a *Arena) context.Context {
+	return context.WithValue(ctx, contextKey{}, a)
+}
+
+// FromContext returns the arena stored in ctx by NewContext, or nil if
+// there is none.
+func FromContext(ctx context.Context) *Arena {
+	a, _ := ctx.Value(contextKey{}).(*Arena)
+	return a
+}
+
+// WatchContext is a backstop for request handlers that get stuck past
+// their deadline and never reach their deferred Release: once ctx's
+// deadline has passed and grace has elapsed, the watchdog expires. The
+// next time the handler needs a new chunk, the arena logs its name and
+// allocation stats, releases itself and panics instead of growing,
+// bounding the memory a stuck handler can pile up. Memory the handler
+// still references stays valid for the garbage collector. A plain
+// cancellation of ctx does not start the grace period.
+//
+// Arenas are not safe for concurrent use, so the watchdog never touches
+// the arena itself: the release always happens on the goroutine that
+// uses the arena. A handler that finishes calls the returned stop before
+// releasing the arena itself. stop reports whether it disarmed the
+// watchdog in time; if it returns false the watchdog had expired, stop
+// has released the arena, and the arena must not be used or released
+// again.
+func WatchContext(ctx context.Context, a *Arena, grace time.Duration) (stop func() bool) {
+	w := &watchdog{a: a, grace: grace}
+	a.watch = w
+	w.stopCtx = context.AfterFunc(ctx, func() {
+		if ctx.Err() == context.DeadlineExceeded {
+			w.arm()
+		}
+	})
+	return w.stop
+}
+
+// watchdog is the state of one WatchContext call.
+type watchdog struct {
+	mu      sync.Mutex
+	a       *Arena
+	grace   time.Duration
+	stopCtx func() bool
+	timer   *time.Timer // set once the deadline has passed
+	done    bool        // stopped or expired
+	expired atomic.Bool // set when grace has elapsed; read by the arena's owner
+}
+
+// arm starts the grace period once the deadline has passed.
+func (w *watchdog) arm() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.timer = time.AfterFunc(w.grace, w.fire)
+	}
+}
+
+// fire marks the watchdog expired unless it was stopped. It runs on the
+// timer's goroutine and leaves the arena to its owner.
+func (w *watchdog) fire() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.done {
+		w.done = true
+		w.expired.Store(true)
+	}
+}
+
+// release logs the arena's stats and releases it on behalf of an expired
+// watchdog. It runs on the arena's owning goroutine.
+func (w *watchdog) release() {
+	a := w.a
+	if a.watch == w {
+		a.watch = nil
+	}
+	if !w.expired.CompareAndSwap(true, false) || a.chunks == nil {
+		return // already released
+	}
+	m := a.Metrics()
+	log.Printf("arena: watchdog releasing arena %q %v after its context deadline: %d bytes in use, %d allocations this cycle, capacity %d in %d chunks",
+		a.name, w.grace, m.SizeInUse, m.NumAllocations, m.Capacity, m.NumChunks)
+	a.Release()
+}
+
+// stop disarms the watchdog and reports whether it had not expired. If it
+// had, stop releases the arena.
+func (w *watchdog) stop() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.done {
+		w.release()
+		return false
+	}
+	w.done = true
+	if w.a.watch == w {
+		w.a.watch = nil
+	}
+	w.stopCtx()
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	return true
+}
@@ -0,0 +1,138 @@
+package arena
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContext(t *testing.T) {
+	if a := FromContext(context.Background()); a != nil {
+		t.Errorf("FromContext(Background) = %p, want nil", a)
+	}
+
+	a := NewArena(1024)
+	defer a.Release()
+	ctx, cancel := context.WithCancel(NewContext(context.Background(), a))
+	defer cancel()
+	if got := FromContext(ctx); got != a {
+		t.Errorf("FromContext = %p, want %p", got, a)
+	}
+
+	b := NewArena(1024)
+	defer b.Release()
+	if got := FromContext(NewContext(ctx, b)); got != b {
+		t.Errorf("FromContext of inner context = %p, want the inner arena %p", got, b)
+	}
+}
+
+// logWriter sends each log line to a channel.
+type logWriter chan string
+
+func (w logWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+// waitExpired waits for a's watchdog to expire.
+func waitExpired(t *testing.T, a *Arena) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !a.watch.expired.Load() {
+		if time.Now().After(deadline) {
+			t.Fatal("watchdog did not expire")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWatchContext(t *testing.T) {
+	lines := make(logWriter, 1)
+	log.SetOutput(lines)
+	defer log.SetOutput(os.Stderr)
+
+	// The owner releases the arena when it next needs a chunk.
+	a := NewArena(1024, WithName("stuck-handler"))
+	a.AllocBytes(96)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	stop := WatchContext(ctx, a, 10*time.Millisecond)
+	waitExpired(t, a)
+	if a.chunks == nil {
+		t.Fatal("watchdog released the arena from its own goroutine")
+	}
+	a.AllocBytes(96) // no new chunk needed
+	if msg := panicMessage(func() { a.AllocBytes(2000) }); !strings.Contains(msg, "released by WatchContext") {
+		t.Errorf("growing an expired arena panicked with %q", msg)
+	}
+	select {
+	case line := <-lines:
+		if !strings.Contains(line, `"stuck-handler"`) || !strings.Contains(line, "192 bytes in use") {
+			t.Errorf("watchdog logged %q, want the arena name and its usage", line)
+		}
+	default:
+		t.Fatal("watchdog did not log the release")
+	}
+	if a.chunks != nil {
+		t.Error("arena not released by the watchdog")
+	}
+	if stop() {
+		t.Error("stop() = true after the watchdog expired")
+	}
+
+	// A handler that finishes late has stop release the arena.
+	b := NewArena(1024)
+	ctx, cancel = context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	stop = WatchContext(ctx, b, 0)
+	waitExpired(t, b)
+	if stop() {
+		t.Error("stop() = true after the watchdog expired")
+	}
+	if b.chunks != nil {
+		t.Error("arena not released by stop")
+	}
+	<-lines
+}
+
+func TestWatchContextStopped(t *testing.T) {
+	lines := make(logWriter, 1)
+	log.SetOutput(lines)
+	defer log.SetOutput(os.Stderr)
+
+	// A handler that finishes in time disarms the watchdog.
+	a := NewArena(1024)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	stop := WatchContext(ctx, a, 0)
+	if !stop() {
+		t.Error("stop() = false before the deadline")
+	}
+	<-ctx.Done()
+	cancel()
+
+	// Canceling the context does not start the grace period.
+	b := NewArena(1024)
+	ctx, cancel = context.WithCancel(context.Background())
+	stopB := WatchContext(ctx, b, 0)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	if b.watch.expired.Load() {
+		t.Error("watchdog expired after a plain cancellation")
+	}
+	b.AllocBytes(2000) // still usable and may grow
+	if !stopB() {
+		t.Error("stop() = false after a plain cancellation")
+	}
+
+	select {
+	case line := <-lines:
+		t.Errorf("disarmed watchdog logged %q", line)
+	default:
+	}
+	a.AllocBytes(2000)
+	a.Release()
+	b.Release()
+}
@@ -0,0 +1,107 @@
+package arena
+
+// Ref identifies an allocation inside a ContiguousArena by offset and length.
+// Unlike slices returned by AllocBytes, a Ref stays valid when the arena grows.
+type Ref struct {
+	Off int // byte offset from the start of the arena buffer
+	Len int // length of the allocation in bytes
+}
+
+// ContiguousArena is a bump allocator that guarantees all memory comes from a
+// single contiguous buffer. When the buffer is full it grows by reallocating
+// and copying, so allocations are addressed by Ref rather than by pointer.
+// Use it when a consumer needs one flat buffer (e.g. a kernel or device).
+// Not goroutine-safe.
+type ContiguousArena struct {
+	buf    []byte
+	offset uintptr
+}
+
+// NewContiguousArena creates a ContiguousArena with the given initial size.
+// If size <= 0, DefaultChunkSize is used.
+func NewContiguousArena(size int) *ContiguousArena {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	return &ContiguousArena{buf: make([]byte, size)}
+}
+
+// Alloc reserves n bytes and returns a Ref to them.
+// Returns the zero Ref if n <= 0.
+func (c *ContiguousArena) Alloc(n int) Ref {
+	if c.buf == nil {
+		panic("arena: use after Release()")
+	}
+	if n <= 0 {
+		return Ref{}
+	}
+	off := alignPtr(c.offset)
+	if off+uintptr(n) > uintptr(len(c.buf)) {
+		c.grow(int(off) + n)
+	}
+	c.offset = off + uintptr(n)
+	return Ref{Off: int(off), Len: n}
+}
+
+// AllocBytes reserves n bytes and returns a slice pointing to them.
+// The slice is only valid until the next allocation that grows the buffer;
+// use Alloc and Bytes when the memory must be addressed across growth.
+func (c *ContiguousArena) AllocBytes(n int) []byte {
+	return c.Bytes(c.Alloc(n))
+}
+
+// Bytes resolves r to a slice of the current backing buffer.
+// Returns nil for the zero Ref.
+func (c *ContiguousArena) Bytes(r Ref) []byte {
+	if c.buf == nil {
+		panic("arena: use after Release()")
+	}
+	if r.Len == 0 {
+		return nil
+	}
+	return c.buf[r.Off : r.Off+r.Len : r.Off+r.Len]
+}
+
+// Buffer returns the used portion of the backing buffer as one slice.
+func (c *ContiguousArena) Buffer() []byte {
+	if c.buf == nil {
+		panic("arena: use after Release()")
+	}
+	return c.buf[:c.offset]
+}
+
+// Reset rewinds the allocation offset to zero, keeping the buffer for reuse.
+func (c *ContiguousArena) Reset() {
+	if c.buf == nil {
+		panic("arena: use after Release()")
+	}
+	c.offset = 0
+}
+
+// Release drops the backing buffer and makes the arena unusable.
+func (c *ContiguousArena) Release() {
+	c.buf = nil
+	c.offset = 0
+}
+
+// SizeInUse returns the number of bytes allocated, including alignment padding.
+func (c *ContiguousArena) SizeInUse() int {
+	return int(c.offset)
+}
+
+// Capacity returns the size of the backing buffer in bytes.
+func (c *ContiguousArena) Capacity() int {
+	return len(c.buf)
+}
+
+// grow reallocates the buffer to hold at least min bytes, doubling its size,
+// and copies the used region across.
+func (c *ContiguousArena) grow(min int) {
+	size := len(c.buf) * 2
+	if size < min {
+		size = min
+	}
+	buf := make([]byte, size)
+	copy(buf, c.buf[:c.offset])
+	c.buf = buf
+}
@@ -0,0 +1,64 @@
+package arena
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestContiguousArenaAlloc(t *testing.T) {
+	c := NewContiguousArena(64)
+
+	r1 := c.Alloc(10)
+	copy(c.Bytes(r1), "0123456789")
+
+	// Force growth past the initial buffer
+	r2 := c.Alloc(200)
+	if c.Capacity() < 200 {
+		t.Errorf("Capacity after growth = %d, want >= 200", c.Capacity())
+	}
+
+	// Refs remain valid across growth
+	if got := string(c.Bytes(r1)); got != "0123456789" {
+		t.Errorf("Bytes(r1) after growth = %q, want %q", got, "0123456789")
+	}
+	if len(c.Bytes(r2)) != 200 {
+		t.Errorf("Bytes(r2) length = %d, want 200", len(c.Bytes(r2)))
+	}
+
+	// Allocations are adjacent in the single buffer
+	if r2.Off < r1.Off+r1.Len {
+		t.Errorf("r2.Off = %d overlaps r1 ending at %d", r2.Off, r1.Off+r1.Len)
+	}
+	if !bytes.HasPrefix(c.Buffer(), []byte("0123456789")) {
+		t.Error("Buffer() does not start with first allocation")
+	}
+
+	// Zero and negative sizes
+	if r := c.Alloc(0); r != (Ref{}) {
+		t.Errorf("Alloc(0) = %+v, want zero Ref", r)
+	}
+	if b := c.Bytes(Ref{}); b != nil {
+		t.Errorf("Bytes(Ref{}) = %v, want nil", b)
+	}
+}
+
+func TestContiguousArenaResetRelease(t *testing.T) {
+	c := NewContiguousArena(0)
+	if c.Capacity() != DefaultChunkSize {
+		t.Errorf("Capacity = %d, want %d", c.Capacity(), DefaultChunkSize)
+	}
+
+	c.AllocBytes(100)
+	c.Reset()
+	if c.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after Reset() = %d, want 0", c.SizeInUse())
+	}
+
+	c.Release()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic on use after Release()")
+		}
+	}()
+	c.Alloc(1)
+}
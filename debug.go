@@ -0,0 +1,260 @@
+package arena
+
+import "sync/atomic"
+
+// DebugLevel selects which diagnostics are enabled for newly created arenas.
+// Each level includes everything enabled by the levels below it.
+type DebugLevel int32
+
+const (
+	// DebugOff disables all diagnostics. This is the default.
+	DebugOff DebugLevel = iota
+	// DebugEvents records the last DebugEventLogSize operations per arena
+	// and includes them in panic messages.
+	DebugEvents
+	// DebugPoison additionally overwrites memory with PoisonByte on Reset
+	// and Release so stale reads are easy to spot.
+	DebugPoison
+	// DebugFull additionally places canary bytes after every allocation,
+	// verified on Reset and Release, tracks allocations per type, stamps
+	// every allocation with its generation for Validate, writes a
+	// ChunkTag header at the start of every chunk and reports arenas
+	// garbage collected without Release (see WithLeakDetection).
+	DebugFull
+)
+
+const (
+	// DebugEventLogSize is the event log length used by DebugEvents and above.
+	DebugEventLogSize = 64
+	// PoisonByte is written over reset or released memory under DebugPoison.
+	PoisonByte = 0xDB
+	// canaryByte fills the guard bytes placed after allocations under DebugFull.
+	canaryByte = 0xCA
+	// canarySize is the number of guard bytes placed after each allocation.
+	canarySize = 8
+)
+
+var debugLevel atomic.Int32
+
+// SetDebugLevel sets the diagnostics level applied to arenas created after
+// the call. Existing arenas are unaffected. Safe for concurrent use, so it
+// can be flipped at runtime (e.g. from an admin endpoint) without rebuilding.
+func SetDebugLevel(level DebugLevel) {
+	debugLevel.Store(int32(level))
+}
+
+// DebugLevelCurrent returns the diagnostics level applied to new arenas.
+func DebugLevelCurrent() DebugLevel {
+	return DebugLevel(debugLevel.Load())
+}
+
+// TypeStats holds per-type allocation counters collected under DebugFull.
+type TypeStats struct {
+	Count int // Number of Alloc/AllocSlice calls for the type
+	Bytes int // Total bytes requested for the type
+}
+
+// debugState holds all optional diagnostics for an Arena. An Arena only
+// carries one when some diagnostic is enabled, keeping the fast path to a
+// single nil check.
+type debugState struct {
+	events   *eventLog
+	poison   bool
+	canaries bool
+	guards   [][]byte // canary regions written since the last Reset
+	types    map[string]TypeStats
+	tags     bool   // write a ChunkTag header at the start of every chunk
+	stamps   bool   // prefix every allocation with a generation stamp
+	owner    uint64 // goroutine that adopted the arena; 0 if never transferred
+
+	assertions []func(AllocRequest) error // set by WithAllocAssertions
+	nextType   string                     // type of the pending typed allocation
+
+	poisonCheck bool        // set by WithPoisoning
+	sites       []allocSite // allocations of the current cycle (WithPoisoning)
+	prevSites   []allocSite // allocations of the previous cycle
+	guardSites  []int32     // index in sites of each guard's allocation
+}
+
+// debugState returns the arena's debug state, creating it if needed.
+func (a *Arena) debugState() *debugState {
+	if a.debug == nil {
+		a.debug = &debugState{}
+	}
+	return a.debug
+}
+
+// dropDebugIfIdle clears the debug state once no diagnostic is enabled.
+func (a *Arena) dropDebugIfIdle() {
+	d := a.debug
+	if d.events == nil && !d.poison && !d.canaries && d.types == nil && !d.tags && !d.stamps && d.owner == 0 && d.assertions == nil && !d.poisonCheck {
+		a.debug = nil
+	}
+}
+
+// applyDebugLevel enables the diagnostics selected by level.
+func (a *Arena) applyDebugLevel(level DebugLevel) {
+	if level >= DebugEvents {
+		a.EnableEventLog(DebugEventLogSize)
+	}
+	if level >= DebugPoison {
+		a.debugState().poison = true
+	}
+	if level >= DebugFull {
+		a.debug.canaries = true
+		a.debug.types = make(map[string]TypeStats)
+		a.debug.stamps = true
+		a.enableMemoryTags()
+		if a.leak == nil {
+			a.leak = &leakCheck{}
+		}
+	}
+}
+
+// TypeStats returns per-type allocation counters keyed by type name.
+// Returns nil unless the arena was created under DebugFull.
+func (a *Arena) TypeStats() map[string]TypeStats {
+	if a.debug == nil || a.debug.types == nil {
+		return nil
+	}
+	out := make(map[string]TypeStats, len(a.debug.types))
+	for k, v := range a.debug.types {
+		out[k] = v
+	}
+	return out
+}
+
+// allocBytesDebug is the AllocBytes path taken when diagnostics are enabled.
+func (a *Arena) allocBytesDebug(n int) []byte {
+	d := a.debug
+	a.checkOwner()
+	if d.assertions != nil {
+		a.checkAlloc(n)
+	}
+	total := n
+	if d.canaries {
+		total += canarySize
+	}
+	if d.stamps {
+		total += stampSize
+	}
+
+	c := a.currentChunk
+	var off uintptr
+	if c != nil {
+		off = alignPtr(c.offset)
+	}
+	if c == nil || off+uintptr(total) > uintptr(len(c.buf)) {
+		a.panicIfReleased()
+		a.advanceChunk(total)
+		c = a.currentChunk
+		off = alignPtr(c.offset)
+	}
+	if d.poisonCheck {
+		if k := len(d.guards); k > 0 {
+			a.checkGuard(k - 1)
+		}
+		a.checkPoisoned(c, off, total)
+	}
+	c.offset = off + uintptr(total)
+	a.countAlloc(n)
+	a.recordEvent(OpAlloc, n, c, off)
+	if d.tags {
+		putTagUsed(c)
+	}
+
+	b := c.buf[off : off+uintptr(total) : off+uintptr(total)]
+	if d.poisonCheck {
+		d.guardSites = append(d.guardSites, d.recordSite(b))
+	}
+	if d.stamps {
+		putStamp(b, n, a.generation)
+		b = b[stampSize:]
+	}
+	if d.canaries {
+		guard := b[n:]
+		for i := range guard {
+			guard[i] = canaryByte
+		}
+		d.guards = append(d.guards, guard)
+	}
+	return b[:n:n]
+}
+
+// debugReset verifies canaries and poisons used memory before a Reset or
+// Release discards it.
+func (a *Arena) debugReset() {
+	a.checkOwner()
+	a.verifyCanaries()
+	if a.debug.poison {
+		poisonChunks(a.chunks, a.keepStamps())
+	}
+	if a.debug.poisonCheck {
+		a.debug.rotateSites()
+	}
+}
+
+// verifyCanaries panics if any canary written since the last check was
+// overwritten, then forgets them.
+func (a *Arena) verifyCanaries() {
+	d := a.debug
+	for i := range d.guards {
+		a.checkGuard(i)
+	}
+	d.guards = d.guards[:0]
+	d.guardSites = d.guardSites[:0]
+}
+
+// checkGuard panics if the i'th canary since the last check was
+// overwritten.
+func (a *Arena) checkGuard(i int) {
+	for _, v := range a.debug.guards[i] {
+		if v != canaryByte {
+			a.canaryPanic(i)
+		}
+	}
+}
+
+// keepStamps reports whether poisoning leaves generation stamps intact,
+// which it does unless WithPoisoning checks the poison.
+func (a *Arena) keepStamps() bool {
+	return a.debug.stamps && !a.debug.poisonCheck
+}
+
+// poisonChunks overwrites the used part of each chunk with PoisonByte. If
+// keepStamps is set, generation stamps are left intact so Validate can
+// still name the generation of stale pointers.
+func poisonChunks(chunks []chunk, keepStamps bool) {
+	for i := range chunks {
+		c := &chunks[i]
+		buf := c.buf[:c.offset]
+		c.poisoned = max(c.poisoned, c.offset)
+		for j := 0; j < len(buf); j++ {
+			if keepStamps && j%8 == 0 && len(buf)-j >= stampSize && [8]byte(buf[j:j+8]) == stampMagic {
+				j += stampSize - 1
+				continue
+			}
+			buf[j] = PoisonByte
+		}
+	}
+}
+
+// countType records an allocation of size bytes of type T under DebugFull
+// and notes its type for alloc assertions.
+func countType[T any](a *Arena, size int) {
+	d := a.debug
+	if d == nil || d.types == nil && d.assertions == nil {
+		return
+	}
+	name := typeEntryFor[T]().info.Name
+	if d.assertions != nil && size > 0 {
+		d.nextType = name
+	}
+	if d.types == nil {
+		return
+	}
+	st := d.types[name]
+	st.Count++
+	st.Bytes += size
+	d.types[name] = st
+}
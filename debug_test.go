@@ -0,0 +1,81 @@
+package arena
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestSetDebugLevel(t *testing.T) {
+	defer SetDebugLevel(DebugOff)
+
+	a := NewArena(1024)
+	if a.debug != nil {
+		t.Fatal("arena created with DebugOff has debug state")
+	}
+
+	SetDebugLevel(DebugEvents)
+	if DebugLevelCurrent() != DebugEvents {
+		t.Errorf("DebugLevelCurrent() = %d, want %d", DebugLevelCurrent(), DebugEvents)
+	}
+	b := NewArena(1024)
+	b.AllocBytes(10)
+	if len(b.Events()) == 0 {
+		t.Error("DebugEvents arena recorded no events")
+	}
+
+	// Existing arenas are unaffected
+	if a.debug != nil {
+		t.Error("SetDebugLevel changed an existing arena")
+	}
+}
+
+func TestDebugPoison(t *testing.T) {
+	SetDebugLevel(DebugPoison)
+	a := NewArena(1024)
+	SetDebugLevel(DebugOff)
+
+	b := a.AllocBytes(16)
+	copy(b, "live data")
+	a.Reset()
+
+	for i, v := range b {
+		if v != PoisonByte {
+			t.Fatalf("b[%d] = %#x after Reset, want %#x", i, v, PoisonByte)
+		}
+	}
+}
+
+func TestDebugFull(t *testing.T) {
+	SetDebugLevel(DebugFull)
+	a := NewArena(1024)
+	SetDebugLevel(DebugOff)
+
+	Alloc[int64](a)
+	Alloc[int64](a)
+	AllocSlice[uint32](a, 4)
+
+	stats := a.TypeStats()
+	if got := stats["int64"]; got.Count != 2 || got.Bytes != 16 {
+		t.Errorf("TypeStats()[int64] = %+v, want {Count:2 Bytes:16}", got)
+	}
+	if got := stats["uint32"]; got.Count != 1 || got.Bytes != 16 {
+		t.Errorf("TypeStats()[uint32] = %+v, want {Count:1 Bytes:16}", got)
+	}
+
+	// Canaries are intact after well-behaved use
+	a.Reset()
+
+	// Writing past the end of an allocation is detected on Reset
+	b := a.AllocBytes(8)
+	unsafe.Slice(&b[0], len(b)+canarySize)[8] = 0
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(fmt.Sprint(r), "canary corrupted") {
+			t.Errorf("recover() = %v, want canary corruption panic", r)
+		}
+	}()
+	a.Reset()
+}
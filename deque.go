@@ -0,0 +1,120 @@
+package arena
+
+// Deque is a double-ended queue of T stored in an arena as a ring buffer,
+// for BFS frontiers and work queues that would otherwise be sized for the
+// worst case up front. When the ring is full it moves to one twice the
+// size, allocated from the arena. Like a Vector, a Deque used after the
+// arena is reset panics unless Clear is called first. A Deque is not
+// goroutine-safe.
+type Deque[T any] struct {
+	a     *Arena
+	buf   []T
+	head  int // index of the front element in buf
+	n     int
+	guard GenerationGuard
+}
+
+// minDequeCap is the ring size of a Deque's first allocation.
+const minDequeCap = 8
+
+// NewDeque returns an empty Deque with room for capHint elements before it
+// first grows.
+func NewDeque[T any](a *Arena, capHint int) *Deque[T] {
+	checkPointers[T]()
+	d := &Deque[T]{a: a, guard: a.Guard()}
+	if capHint > 0 {
+		d.buf = AllocSlice[T](a, capHint)
+	}
+	return d
+}
+
+// PushBack adds x at the back.
+func (d *Deque[T]) PushBack(x T) {
+	d.guard.Check()
+	if d.n == len(d.buf) {
+		d.grow()
+	}
+	d.buf[d.index(d.n)] = x
+	d.n++
+}
+
+// PushFront adds x at the front.
+func (d *Deque[T]) PushFront(x T) {
+	d.guard.Check()
+	if d.n == len(d.buf) {
+		d.grow()
+	}
+	d.head = d.index(len(d.buf) - 1)
+	d.buf[d.head] = x
+	d.n++
+}
+
+// PopFront removes and returns the front element. It returns false if the
+// deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	d.guard.Check()
+	var x T
+	if d.n == 0 {
+		return x, false
+	}
+	x = d.buf[d.head]
+	d.head = d.index(1)
+	d.n--
+	return x, true
+}
+
+// PopBack removes and returns the back element. It returns false if the
+// deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	d.guard.Check()
+	var x T
+	if d.n == 0 {
+		return x, false
+	}
+	d.n--
+	return d.buf[d.index(d.n)], true
+}
+
+// Len returns the number of elements.
+func (d *Deque[T]) Len() int {
+	d.guard.Check()
+	return d.n
+}
+
+// At returns element i, counting from the front. Panics if i is out of
+// range.
+func (d *Deque[T]) At(i int) T {
+	d.guard.Check()
+	if uint(i) >= uint(d.n) {
+		panic("arena: Deque index out of range")
+	}
+	return d.buf[d.index(i)]
+}
+
+// Clear removes every element, keeping the storage for reuse. After the
+// arena is reset it drops the storage instead, making the deque usable
+// again.
+func (d *Deque[T]) Clear() {
+	if !d.guard.Valid() {
+		d.buf = nil
+		d.guard = d.a.Guard()
+	}
+	d.head, d.n = 0, 0
+}
+
+// index returns the position in buf of element i from the front.
+func (d *Deque[T]) index(i int) int {
+	i += d.head
+	if i >= len(d.buf) {
+		i -= len(d.buf)
+	}
+	return i
+}
+
+// grow moves the elements, front first, to a ring twice the size.
+func (d *Deque[T]) grow() {
+	buf := AllocSlice[T](d.a, max(2*len(d.buf), minDequeCap))
+	k := copy(buf, d.buf[d.head:])
+	copy(buf[k:], d.buf[:d.head])
+	d.buf, d.head = buf, 0
+}
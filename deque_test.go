@@ -0,0 +1,108 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeque(t *testing.T) {
+	a := NewArena(4096)
+	d := NewDeque[int](a, 0)
+	if _, ok := d.PopFront(); ok {
+		t.Fatal("PopFront on an empty deque succeeded")
+	}
+	// Interleave both ends so the ring wraps before it grows.
+	for i := range 50 {
+		d.PushBack(i)
+		d.PushFront(-i - 1)
+	}
+	if d.Len() != 100 {
+		t.Fatalf("Len = %d, want 100", d.Len())
+	}
+	for i := range 100 {
+		if want := i - 50; d.At(i) != want {
+			t.Fatalf("At(%d) = %d, want %d", i, d.At(i), want)
+		}
+	}
+	if x, ok := d.PopFront(); !ok || x != -50 {
+		t.Errorf("PopFront = %d, %v, want -50, true", x, ok)
+	}
+	if x, ok := d.PopBack(); !ok || x != 49 {
+		t.Errorf("PopBack = %d, %v, want 49, true", x, ok)
+	}
+
+	// FIFO use across a wrap.
+	d.Clear()
+	for i := range 20 {
+		d.PushBack(i)
+		if i%2 == 1 {
+			d.PopFront()
+		}
+	}
+	for want := 10; want < 20; want++ {
+		if x, _ := d.PopFront(); x != want {
+			t.Fatalf("PopFront = %d, want %d", x, want)
+		}
+	}
+
+	if msg := panicMessage(func() { d.At(0) }); !strings.Contains(msg, "out of range") {
+		t.Errorf("At on an empty deque panicked with %q", msg)
+	}
+
+	d.PushBack(1)
+	a.Reset()
+	for name, f := range map[string]func(){
+		"Len":      func() { d.Len() },
+		"PushBack": func() { d.PushBack(2) },
+		"PopFront": func() { d.PopFront() },
+	} {
+		if msg := panicMessage(f); !strings.Contains(msg, "used after Reset") {
+			t.Errorf("%s after Reset panicked with %q, want use after Reset", name, msg)
+		}
+	}
+	d.Clear()
+	if d.Len() != 0 {
+		t.Errorf("Len after Reset and Clear = %d, want 0", d.Len())
+	}
+	d.PushFront(5)
+	if x, ok := d.PopBack(); !ok || x != 5 {
+		t.Errorf("PopBack after Reset = %d, %v, want 5, true", x, ok)
+	}
+}
+
+func TestDequeRecycledStorage(t *testing.T) {
+	a := NewArena(4096)
+	d := NewDeque[int64](a, 4)
+	d.PushBack(1)
+	d.PushFront(2)
+	a.Reset()
+	d.Clear()
+	// Takes over the memory the deque had before the reset.
+	other := AllocSlice[int64](a, 4)
+	for i := range other {
+		other[i] = -1
+	}
+	d.PushBack(7)
+	if x, ok := d.PopFront(); !ok || x != 7 || d.Len() != 0 {
+		t.Errorf("PopFront after Reset and Clear = %d, %v, want 7, true and empty", x, ok)
+	}
+	for _, x := range other {
+		if x != -1 {
+			t.Fatalf("deque wrote to recycled memory: %v", other)
+		}
+	}
+}
+
+func BenchmarkDequeFIFO(b *testing.B) {
+	a := NewArena(1 << 20)
+	for i := 0; i < b.N; i++ {
+		d := NewDeque[int](a, 0)
+		for j := range 1000 {
+			d.PushBack(j)
+			if j%3 == 0 {
+				d.PopFront()
+			}
+		}
+		a.Reset()
+	}
+}
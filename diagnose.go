@@ -0,0 +1,116 @@
+package arena
+
+import "fmt"
+
+// FindingKind identifies a pattern reported by Diagnose.
+type FindingKind uint8
+
+const (
+	// FindingLargeAllocations: the average allocation is larger than a
+	// chunk, so most allocations get a dedicated chunk.
+	FindingLargeAllocations FindingKind = iota + 1
+	// FindingLowUtilization: a reset cycle uses under 10% of the retained
+	// capacity.
+	FindingLowUtilization
+	// FindingFewAllocsPerReset: about one allocation per reset, so there is
+	// nothing to batch.
+	FindingFewAllocsPerReset
+	// FindingFrequentGrowth: chunks are still being added after warm-up.
+	FindingFrequentGrowth
+	// FindingColdCapacity: under half of the retained capacity held data
+	// in any cycle sampled by WithAccessSampling.
+	FindingColdCapacity
+)
+
+// String returns a short name for the finding kind.
+func (k FindingKind) String() string {
+	switch k {
+	case FindingLargeAllocations:
+		return "large-allocations"
+	case FindingLowUtilization:
+		return "low-utilization"
+	case FindingFewAllocsPerReset:
+		return "few-allocs-per-reset"
+	case FindingFrequentGrowth:
+		return "frequent-growth"
+	case FindingColdCapacity:
+		return "cold-capacity"
+	}
+	return "unknown"
+}
+
+// Finding is a diagnostic reported by Diagnose.
+type Finding struct {
+	Kind    FindingKind
+	Message string // Human-readable explanation and suggested action
+}
+
+// String formats the finding as "kind: message".
+func (f Finding) String() string {
+	return f.Kind.String() + ": " + f.Message
+}
+
+const (
+	// diagMinAllocs and diagMinResets are the sample sizes below which
+	// Diagnose stays silent rather than guess.
+	diagMinAllocs = 16
+	diagMinResets = 4
+	// diagLowUtilization is the cycle usage to capacity ratio flagged as low.
+	diagLowUtilization = 0.10
+	// diagColdCapacity is the sampled working set to capacity ratio below
+	// which retained capacity is flagged as cold.
+	diagColdCapacity = 0.5
+)
+
+// Diagnose inspects the arena's lifetime counters for patterns where an
+// arena is likely counterproductive and returns actionable findings, or nil
+// if none apply or too little has happened to tell. It is cheap enough to
+// call from a debug endpoint or at the end of a test.
+func (a *Arena) Diagnose() []Finding {
+	m := a.Metrics()
+	var out []Finding
+	add := func(k FindingKind, format string, args ...any) {
+		out = append(out, Finding{Kind: k, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if m.TotalAllocs >= diagMinAllocs {
+		if avg := m.TotalBytes / m.TotalAllocs; avg > uint64(m.ChunkSize) {
+			add(FindingLargeAllocations,
+				"average allocation of %d bytes exceeds the %d byte chunk size; allocate these from the heap or raise the chunk size",
+				avg, m.ChunkSize)
+		}
+	}
+	if m.Resets < diagMinResets {
+		return out
+	}
+	perCycle := float64(a.retiredBytes) / float64(m.Resets)
+	if m.Capacity > 0 && perCycle/float64(m.Capacity) < diagLowUtilization {
+		add(FindingLowUtilization,
+			"a reset cycle uses %.0f of %d retained bytes (%.1f%%); lower the chunk size or call TrimCold/ShrinkTo",
+			perCycle, m.Capacity, 100*perCycle/float64(m.Capacity))
+	}
+	if float64(m.TotalAllocs)/float64(m.Resets) <= 1 {
+		add(FindingFewAllocsPerReset,
+			"%d allocations over %d resets; with about one allocation per reset the arena adds overhead without batching anything",
+			m.TotalAllocs, m.Resets)
+	}
+	// The first chunk and warm-up growth are expected; steady growth is not.
+	if m.Grows > m.Resets {
+		add(FindingFrequentGrowth,
+			"%d chunks added over %d resets; raise the chunk size or set a growth factor so a cycle fits",
+			m.Grows, m.Resets)
+	}
+	if ws := a.sampledWorkingSet(); ws.Samples >= diagMinResets && float64(ws.Bytes) < diagColdCapacity*float64(ws.Capacity) {
+		add(FindingColdCapacity,
+			"sampled working set is %d of %d retained bytes (cycles peak at %d); WithRetention(%d) would free the rest",
+			ws.Bytes, ws.Capacity, ws.Peak, ws.Bytes)
+	}
+	return out
+}
+
+// Diagnose thread-safely returns the findings of Arena.Diagnose.
+func (s *SafeArena) Diagnose() []Finding {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.a.Diagnose()
+}
@@ -0,0 +1,71 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+func hasFinding(fs []Finding, k FindingKind) bool {
+	for _, f := range fs {
+		if f.Kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDiagnoseHealthy(t *testing.T) {
+	a := NewArena(4096)
+	for cycle := 0; cycle < 10; cycle++ {
+		for i := 0; i < 100; i++ {
+			a.AllocBytes(32)
+		}
+		a.Reset()
+	}
+	if fs := a.Diagnose(); fs != nil {
+		t.Errorf("Diagnose() = %v, want no findings", fs)
+	}
+	if fs := NewArena(1024).Diagnose(); fs != nil {
+		t.Errorf("Diagnose() on unused arena = %v, want nil", fs)
+	}
+}
+
+func TestDiagnoseFindings(t *testing.T) {
+	large := NewArena(1024)
+	for i := 0; i < 20; i++ {
+		large.AllocBytes(4096)
+		large.Reset()
+	}
+	fs := large.Diagnose()
+	for _, k := range []FindingKind{FindingLargeAllocations, FindingFewAllocsPerReset} {
+		if !hasFinding(fs, k) {
+			t.Errorf("Diagnose() = %v, missing %s", fs, k)
+		}
+	}
+
+	sparse := NewArena(1 << 16)
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 4; j++ {
+			sparse.AllocBytes(64)
+		}
+		sparse.Reset()
+	}
+	fs = sparse.Diagnose()
+	if !hasFinding(fs, FindingLowUtilization) || hasFinding(fs, FindingFewAllocsPerReset) {
+		t.Errorf("Diagnose() = %v, want only low utilization", fs)
+	}
+	growing := NewArena(1024)
+	for i := 0; i < 5; i++ {
+		for j := 0; j < 8*(i+1); j++ {
+			growing.AllocBytes(512) // each cycle needs more chunks than the last
+		}
+		growing.Reset()
+	}
+	if fs := growing.Diagnose(); !hasFinding(fs, FindingFrequentGrowth) {
+		t.Errorf("Diagnose() = %v, missing %s", fs, FindingFrequentGrowth)
+	}
+
+	if s := fs[0].String(); !strings.HasPrefix(s, "low-utilization: ") {
+		t.Errorf("Finding.String() = %q", s)
+	}
+}
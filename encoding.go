@@ -0,0 +1,44 @@
+package arena
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// EncodeBase64 returns the standard base64 encoding of src, allocated in the arena.
+// Returns nil if src is empty.
+func EncodeBase64(a *Arena, src []byte) []byte {
+	dst := a.AllocBytes(base64.StdEncoding.EncodedLen(len(src)))
+	base64.StdEncoding.Encode(dst, src)
+	return dst
+}
+
+// DecodeBase64 decodes standard base64 src into a buffer allocated in the arena.
+// On error the arena space reserved for the output is not reclaimed.
+func DecodeBase64(a *Arena, src []byte) ([]byte, error) {
+	dst := a.AllocBytes(base64.StdEncoding.DecodedLen(len(src)))
+	n, err := base64.StdEncoding.Decode(dst, src)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n:n], nil
+}
+
+// EncodeHex returns the hexadecimal encoding of src, allocated in the arena.
+// Returns nil if src is empty.
+func EncodeHex(a *Arena, src []byte) []byte {
+	dst := a.AllocBytes(hex.EncodedLen(len(src)))
+	hex.Encode(dst, src)
+	return dst
+}
+
+// DecodeHex decodes hexadecimal src into a buffer allocated in the arena.
+// On error the arena space reserved for the output is not reclaimed.
+func DecodeHex(a *Arena, src []byte) ([]byte, error) {
+	dst := a.AllocBytes(hex.DecodedLen(len(src)))
+	n, err := hex.Decode(dst, src)
+	if err != nil {
+		return nil, err
+	}
+	return dst[:n:n], nil
+}
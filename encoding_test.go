@@ -0,0 +1,65 @@
+package arena
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBase64RoundTrip(t *testing.T) {
+	a := NewArena(1024)
+	src := []byte("arena-backed encoding")
+
+	enc := EncodeBase64(a, src)
+	if string(enc) != "YXJlbmEtYmFja2VkIGVuY29kaW5n" {
+		t.Errorf("EncodeBase64() = %q", enc)
+	}
+	dec, err := DecodeBase64(a, enc)
+	if err != nil {
+		t.Fatalf("DecodeBase64() error = %v", err)
+	}
+	if !bytes.Equal(dec, src) {
+		t.Errorf("DecodeBase64() = %q, want %q", dec, src)
+	}
+
+	if _, err := DecodeBase64(a, []byte("!!!")); err == nil {
+		t.Error("DecodeBase64(invalid) error = nil, want error")
+	}
+	if EncodeBase64(a, nil) != nil {
+		t.Error("EncodeBase64(nil) should return nil")
+	}
+}
+
+func TestHexRoundTrip(t *testing.T) {
+	a := NewArena(1024)
+	src := []byte{0xde, 0xad, 0xbe, 0xef}
+
+	enc := EncodeHex(a, src)
+	if string(enc) != "deadbeef" {
+		t.Errorf("EncodeHex() = %q, want %q", enc, "deadbeef")
+	}
+	dec, err := DecodeHex(a, enc)
+	if err != nil {
+		t.Fatalf("DecodeHex() error = %v", err)
+	}
+	if !bytes.Equal(dec, src) {
+		t.Errorf("DecodeHex() = %x, want %x", dec, src)
+	}
+
+	if _, err := DecodeHex(a, []byte("zz")); err == nil {
+		t.Error("DecodeHex(invalid) error = nil, want error")
+	}
+}
+
+func BenchmarkEncodeBase64(b *testing.B) {
+	a := NewArena(1024 * 1024)
+	src := bytes.Repeat([]byte("x"), 256)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		EncodeBase64(a, src)
+		if i%1000 == 999 {
+			a.Reset()
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package arena
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+// EventOp identifies the kind of arena operation recorded in an event log.
+type EventOp uint8
+
+const (
+	OpAlloc EventOp = iota + 1
+	OpGrow
+	OpReset
+	OpRelease
+	OpRestore
+)
+
+// String returns the lower-case name of the operation.
+func (op EventOp) String() string {
+	switch op {
+	case OpAlloc:
+		return "alloc"
+	case OpGrow:
+		return "grow"
+	case OpReset:
+		return "reset"
+	case OpRelease:
+		return "release"
+	case OpRestore:
+		return "restore"
+	}
+	return "unknown"
+}
+
+// Event is a single recorded arena operation.
+type Event struct {
+	Op        EventOp
+	Size      int       // Requested size in bytes (0 for reset/release)
+	Chunk     int       // Chunk index the operation touched (-1 if none)
+	Offset    int       // Offset within the chunk where the allocation starts
+	Goroutine uint64    // ID of the goroutine that performed the operation
+	Time      time.Time // When the operation happened
+}
+
+// String formats the event as a single log line.
+func (e Event) String() string {
+	return fmt.Sprintf("%s %s size=%d chunk=%d offset=%d goroutine=%d",
+		e.Time.Format(time.RFC3339Nano), e.Op, e.Size, e.Chunk, e.Offset, e.Goroutine)
+}
+
+// eventLog is a fixed-size ring of the most recent arena operations.
+type eventLog struct {
+	ring   []Event
+	next   int
+	full   bool
+	stable bool // omit goroutine IDs and times (WithStableAddresses)
+}
+
+func (l *eventLog) record(e Event) {
+	if !l.stable {
+		e.Goroutine = goroutineID()
+		e.Time = time.Now()
+	}
+	l.ring[l.next] = e
+	l.next++
+	if l.next == len(l.ring) {
+		l.next = 0
+		l.full = true
+	}
+}
+
+// events returns the recorded events from oldest to newest.
+func (l *eventLog) events() []Event {
+	if !l.full {
+		return append([]Event(nil), l.ring[:l.next]...)
+	}
+	out := make([]Event, 0, len(l.ring))
+	out = append(out, l.ring[l.next:]...)
+	return append(out, l.ring[:l.next]...)
+}
+
+// EnableEventLog starts recording the last n arena operations for post-mortem
+// debugging. The log is included in the message of any panic raised by the
+// arena. Recording adds overhead to every allocation; intended for debugging.
+// If n <= 0, the event log is disabled.
+func (a *Arena) EnableEventLog(n int) {
+	if n <= 0 {
+		if a.debug != nil {
+			a.debug.events = nil
+			a.dropDebugIfIdle()
+		}
+		return
+	}
+	a.debugState().events = &eventLog{ring: make([]Event, n), stable: a.stableAddrs}
+}
+
+// Events returns the recorded operations from oldest to newest.
+// Returns nil if the event log is not enabled.
+func (a *Arena) Events() []Event {
+	if a.debug == nil || a.debug.events == nil {
+		return nil
+	}
+	return a.debug.events.events()
+}
+
+// DumpEvents writes the recorded operations to w, one per line.
+func (a *Arena) DumpEvents(w io.Writer) error {
+	for _, e := range a.Events() {
+		if _, err := fmt.Fprintln(w, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordEvent appends an operation to the event log if it is enabled.
+func (a *Arena) recordEvent(op EventOp, size int, c *chunk, offset uintptr) {
+	if a.debug == nil || a.debug.events == nil {
+		return
+	}
+	a.debug.events.record(Event{Op: op, Size: size, Chunk: a.chunkIndex(c), Offset: int(offset)})
+}
+
+// chunkIndex returns the index of c within a.chunks, or -1 if c is nil.
+func (a *Arena) chunkIndex(c *chunk) int {
+	if c == nil || len(a.chunks) == 0 {
+		return -1
+	}
+	base := uintptr(unsafe.Pointer(&a.chunks[0]))
+	return int((uintptr(unsafe.Pointer(c)) - base) / unsafe.Sizeof(chunk{}))
+}
+
+// panicWithEvents panics with msg, appending the event log if enabled.
+func (a *Arena) panicWithEvents(msg string) {
+	if a.debug == nil || a.debug.events == nil {
+		panic(msg)
+	}
+	var sb strings.Builder
+	sb.WriteString(msg)
+	sb.WriteString("\nrecent arena events:\n")
+	a.DumpEvents(&sb)
+	panic(sb.String())
+}
+
+// goroutineID parses the current goroutine ID from the runtime stack header.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
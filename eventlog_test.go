@@ -0,0 +1,56 @@
+package arena
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestEventLogRing(t *testing.T) {
+	a := NewArena(1024)
+	if a.Events() != nil {
+		t.Error("Events() should be nil when the log is disabled")
+	}
+
+	a.EnableEventLog(3)
+	a.AllocBytes(10)
+	a.AllocBytes(20)
+	a.AllocBytes(2000) // grow + alloc
+	a.Reset()
+
+	events := a.Events()
+	if len(events) != 3 {
+		t.Fatalf("len(Events()) = %d, want 3", len(events))
+	}
+	want := []EventOp{OpGrow, OpAlloc, OpReset}
+	for i, e := range events {
+		if e.Op != want[i] {
+			t.Errorf("Events()[%d].Op = %s, want %s", i, e.Op, want[i])
+		}
+		if e.Goroutine == 0 {
+			t.Errorf("Events()[%d].Goroutine = 0, want non-zero", i)
+		}
+	}
+	if events[1].Size != 2000 || events[1].Chunk != 1 {
+		t.Errorf("alloc event = %+v, want size 2000 in chunk 1", events[1])
+	}
+}
+
+func TestEventLogPanicDump(t *testing.T) {
+	a := NewArena(1024)
+	a.EnableEventLog(8)
+	a.AllocBytes(42)
+	a.Release()
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("Expected panic on use after Release()")
+		}
+		msg := fmt.Sprint(r)
+		if !strings.Contains(msg, "alloc size=42") || !strings.Contains(msg, "release") {
+			t.Errorf("panic message missing event dump:\n%s", msg)
+		}
+	}()
+	a.AllocBytes(1)
+}
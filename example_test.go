@@ -8,8 +8,8 @@ import (
 
 // Example demonstrates basic arena usage
 func Example() {
-	// Create a new arena with default chunk size
-	a := NewArena(0)
+	// Create a new arena with 64 KiB chunks
+	a := NewArena(64 << 10)
 	defer a.Release() // Always clean up
 
 	// Allocate raw bytes
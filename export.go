@@ -0,0 +1,27 @@
+package arena
+
+// UsedBytes returns the number of bytes CopyUsedTo copies: everything
+// handed out since the last reset, including alignment padding between
+// allocations but not the unused tails of chunks.
+func (a *Arena) UsedBytes() int {
+	if a.chunks == nil {
+		return 0
+	}
+	return a.usedBytes(a.chunks)
+}
+
+// CopyUsedTo copies the used region of every chunk into dst, in allocation
+// order, and returns the number of bytes copied. This exports everything
+// built since the last reset, such as a rendered response to be cached,
+// without tracking the individual allocations. If dst is shorter than
+// UsedBytes only its length is copied.
+func (a *Arena) CopyUsedTo(dst []byte) int {
+	n := 0
+	for i := range a.chunks {
+		if n == len(dst) {
+			break
+		}
+		n += copy(dst[n:], a.chunkData(i))
+	}
+	return n
+}
@@ -0,0 +1,39 @@
+package arena
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCopyUsedTo(t *testing.T) {
+	a := NewArena(64)
+	var want []byte
+	// Lengths are multiples of 8 so no padding falls between them.
+	for _, s := range []string{"<html>\n\n", "<body>\n\n", string(bytes.Repeat([]byte("x"), 56)), "</body>\n"} {
+		want = append(want, CloneBytes(a, []byte(s))...)
+	}
+	if a.NumChunks() < 2 {
+		t.Fatalf("test needs several chunks, got %d", a.NumChunks())
+	}
+	if got := a.UsedBytes(); got != len(want) {
+		t.Fatalf("UsedBytes = %d, want %d", got, len(want))
+	}
+	dst := make([]byte, a.UsedBytes())
+	if n := a.CopyUsedTo(dst); n != len(want) || !bytes.Equal(dst, want) {
+		t.Errorf("CopyUsedTo = %d, %q, want %q", n, dst, want)
+	}
+
+	short := make([]byte, 10)
+	if n := a.CopyUsedTo(short); n != 10 || !bytes.Equal(short, want[:10]) {
+		t.Errorf("CopyUsedTo(short) = %d, %q", n, short)
+	}
+
+	a.Reset()
+	if a.UsedBytes() != 0 || a.CopyUsedTo(dst) != 0 {
+		t.Error("used region not empty after Reset")
+	}
+	a.Release()
+	if a.UsedBytes() != 0 || a.CopyUsedTo(dst) != 0 {
+		t.Error("used region not empty after Release")
+	}
+}
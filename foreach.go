@@ -0,0 +1,78 @@
+package arena
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ForEachOption configures ForEach.
+type ForEachOption func(*forEachConfig)
+
+type forEachConfig struct {
+	workers int
+}
+
+// WithWorkers sets the number of items ForEach processes at once. The
+// default is GOMAXPROCS; n <= 1 processes the items one at a time on the
+// calling goroutine.
+func WithWorkers(n int) ForEachOption {
+	return func(c *forEachConfig) {
+		c.workers = n
+	}
+}
+
+// ForEach calls fn for every item in parallel, the batch-processing idiom
+// of one arena per worker packaged up: each worker takes an arena from
+// pool, resets it before every item after the first and puts it back when
+// done, so fn gets a clean arena per item without allocating one. Memory
+// allocated from the arena must not be retained after fn returns.
+//
+// Items are handed out in order but may complete in any order. If fn
+// returns an error, no further items are started and ForEach returns the
+// first error once the items in progress have finished.
+func ForEach[T any](pool *ArenaPool, items []T, fn func(a *Arena, item T) error, opts ...ForEachOption) error {
+	cfg := forEachConfig{workers: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	workers := min(max(cfg.workers, 1), len(items))
+	if workers == 0 {
+		return nil
+	}
+
+	var (
+		next    atomic.Int64
+		failed  atomic.Bool
+		errOnce sync.Once
+		first   error
+		wg      sync.WaitGroup
+	)
+	work := func() {
+		a := pool.Get()
+		defer pool.Put(a)
+		for used := false; !failed.Load(); used = true {
+			i := int(next.Add(1) - 1)
+			if i >= len(items) {
+				return
+			}
+			if used {
+				a.Reset()
+			}
+			if err := fn(a, items[i]); err != nil {
+				errOnce.Do(func() { first = err })
+				failed.Store(true)
+			}
+		}
+	}
+	wg.Add(workers - 1)
+	for range workers - 1 {
+		go func() {
+			defer wg.Done()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+	return first
+}
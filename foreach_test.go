@@ -0,0 +1,60 @@
+package arena
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestForEach(t *testing.T) {
+	pool := NewArenaPool(4096)
+	items := make([]int, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	var seen [1000]atomic.Int32
+	var sum atomic.Int64
+	err := ForEach(pool, items, func(a *Arena, item int) error {
+		if a.SizeInUse() != 0 {
+			t.Errorf("item %d got an arena with %d bytes in use", item, a.SizeInUse())
+		}
+		v := Alloc[int64](a)
+		*v = int64(item)
+		sum.Add(*v)
+		seen[item].Add(1)
+		return nil
+	}, WithWorkers(4))
+	if err != nil {
+		t.Fatalf("ForEach = %v", err)
+	}
+	if got, want := sum.Load(), int64(999*1000/2); got != want {
+		t.Errorf("sum = %d, want %d", got, want)
+	}
+	for i := range seen {
+		if n := seen[i].Load(); n != 1 {
+			t.Fatalf("item %d processed %d times", i, n)
+		}
+	}
+
+	if err := ForEach(pool, []int(nil), func(*Arena, int) error { panic("called") }); err != nil {
+		t.Errorf("ForEach over no items = %v", err)
+	}
+}
+
+func TestForEachError(t *testing.T) {
+	pool := NewArenaPool(4096)
+	errBad := errors.New("bad item")
+	var calls atomic.Int32
+	err := ForEach(pool, make([]int, 100), func(*Arena, int) error {
+		if calls.Add(1) == 10 {
+			return errBad
+		}
+		return nil
+	}, WithWorkers(1))
+	if !errors.Is(err, errBad) {
+		t.Errorf("ForEach = %v, want %v", err, errBad)
+	}
+	if n := calls.Load(); n != 10 {
+		t.Errorf("fn called %d times, want it to stop after the error", n)
+	}
+}
@@ -0,0 +1,114 @@
+package arena
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"runtime/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// defaultHeapGoalFraction is the share of GOMEMLIMIT at which the heap
+// goal signals pressure when GCPressureConfig leaves it unset.
+const defaultHeapGoalFraction = 0.8
+
+// GCPressureConfig sets when OnGCPressure reports memory pressure. Either
+// condition is enough.
+type GCPressureConfig struct {
+	// HeapGoalFraction signals pressure when the garbage collector's heap
+	// goal reaches this share of the Go memory limit (GOMEMLIMIT); 0.8 if
+	// unset. It never fires without a memory limit.
+	HeapGoalFraction float64
+	// MinInterval signals pressure when garbage collection cycles run
+	// closer together than this on average, the sign of a heap working
+	// hard to stay under its goal. 0 disables the check.
+	MinInterval time.Duration
+}
+
+// GCPressure describes the garbage collection cycle that signalled
+// pressure.
+type GCPressure struct {
+	HeapGoal    uint64        // Heap size the collector is aiming for, in bytes
+	MemoryLimit int64         // Go memory limit; math.MaxInt64 if none is set
+	Interval    time.Duration // Average time between cycles since the last check
+}
+
+// gcListener is the state of one OnGCPressure registration.
+type gcListener struct {
+	cfg     GCPressureConfig
+	fn      func(GCPressure)
+	stopped atomic.Bool
+	samples []metrics.Sample
+	cycles  uint64
+	last    time.Time
+}
+
+// gcSentinel is garbage on every cycle; its finalizer runs the listener's
+// check and rearms itself for the next cycle.
+type gcSentinel struct {
+	l *gcListener
+}
+
+// OnGCPressure calls fn, on the runtime's finalizer goroutine, after each
+// garbage collection cycle that shows memory pressure as configured by
+// cfg, so arena retention can be coordinated with the rest of the heap:
+// arenas hold chunk memory the collector cannot reclaim, and shedding it
+// when the heap is tight spares the process further cycles or an OOM
+// kill. fn must be quick and must not block. Cycles are observed with a
+// finalizer that rearms itself, so a cycle is occasionally missed while
+// the finalizer goroutine is busy. The returned stop unregisters fn.
+func OnGCPressure(cfg GCPressureConfig, fn func(GCPressure)) (stop func()) {
+	if cfg.HeapGoalFraction <= 0 {
+		cfg.HeapGoalFraction = defaultHeapGoalFraction
+	}
+	l := &gcListener{
+		cfg: cfg,
+		fn:  fn,
+		samples: []metrics.Sample{
+			{Name: "/gc/heap/goal:bytes"},
+			{Name: "/gc/cycles/total:gc-cycles"},
+		},
+		last: time.Now(),
+	}
+	metrics.Read(l.samples)
+	l.cycles = l.samples[1].Value.Uint64()
+	runtime.SetFinalizer(&gcSentinel{l: l}, (*gcSentinel).collected)
+	return func() { l.stopped.Store(true) }
+}
+
+// collected runs after each cycle that found the sentinel unreachable.
+func (s *gcSentinel) collected() {
+	if s.l.stopped.Load() {
+		return
+	}
+	s.l.check()
+	runtime.SetFinalizer(s, (*gcSentinel).collected)
+}
+
+// check reads the collector's state and calls fn under pressure.
+func (l *gcListener) check() {
+	metrics.Read(l.samples)
+	now := time.Now()
+	p := GCPressure{HeapGoal: l.samples[0].Value.Uint64(), MemoryLimit: debug.SetMemoryLimit(-1)}
+	if cycles := l.samples[1].Value.Uint64(); cycles > l.cycles {
+		p.Interval = now.Sub(l.last) / time.Duration(cycles-l.cycles)
+		l.cycles, l.last = cycles, now
+	}
+	tight := p.MemoryLimit < math.MaxInt64 && float64(p.HeapGoal) >= l.cfg.HeapGoalFraction*float64(p.MemoryLimit)
+	frequent := l.cfg.MinInterval > 0 && p.Interval > 0 && p.Interval < l.cfg.MinInterval
+	if tight || frequent {
+		l.fn(p)
+	}
+}
+
+// TrimOnGCPressure makes the pool Trim(minIdleResets) whenever
+// OnGCPressure reports pressure under cfg, so idle pooled arenas give
+// their cold chunks back as the heap tightens. Like Trim it never blocks:
+// each pooled arena applies the trim when next handed out. The listener
+// keeps the pool reachable until the returned stop is called.
+func (p *ArenaPool) TrimOnGCPressure(cfg GCPressureConfig, minIdleResets uint64) (stop func()) {
+	return OnGCPressure(cfg, func(GCPressure) {
+		p.Trim(minIdleResets)
+	})
+}
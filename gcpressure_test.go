@@ -0,0 +1,83 @@
+package arena
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+// waitGC runs collections until cond holds or a deadline passes.
+func waitGC(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+		if cond() {
+			return
+		}
+	}
+	t.Fatal("condition not met after repeated GC cycles")
+}
+
+func TestOnGCPressureHeapGoal(t *testing.T) {
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(1 << 40))
+	got := make(chan GCPressure, 16)
+	stop := OnGCPressure(GCPressureConfig{HeapGoalFraction: 1e-12}, func(p GCPressure) {
+		select {
+		case got <- p:
+		default:
+		}
+	})
+	defer stop()
+	var p GCPressure
+	waitGC(t, func() bool {
+		select {
+		case p = <-got:
+			return true
+		default:
+			return false
+		}
+	})
+	if p.MemoryLimit != 1<<40 || p.HeapGoal == 0 {
+		t.Errorf("GCPressure = %+v, want the memory limit and a heap goal", p)
+	}
+}
+
+func TestOnGCPressureQuiet(t *testing.T) {
+	fired := make(chan struct{}, 1)
+	stop := OnGCPressure(GCPressureConfig{HeapGoalFraction: 2}, func(GCPressure) {
+		select {
+		case fired <- struct{}{}:
+		default:
+		}
+	})
+	defer stop()
+	for range 3 {
+		runtime.GC()
+	}
+	time.Sleep(10 * time.Millisecond)
+	select {
+	case <-fired:
+		t.Error("pressure reported with the heap goal far below the limit and no interval check")
+	default:
+	}
+}
+
+func TestArenaPoolTrimOnGCPressure(t *testing.T) {
+	p := NewArenaPool(1024)
+	stop := p.TrimOnGCPressure(GCPressureConfig{MinInterval: time.Hour}, 1)
+	waitGC(t, func() bool { return p.Metrics().Trims > 0 })
+	stop()
+	// Once stopped, no more trims are requested.
+	runtime.GC()
+	time.Sleep(5 * time.Millisecond)
+	n := p.Metrics().Trims
+	for range 3 {
+		runtime.GC()
+	}
+	time.Sleep(5 * time.Millisecond)
+	if p.Metrics().Trims != n {
+		t.Errorf("Trims went from %d to %d after stop", n, p.Metrics().Trims)
+	}
+}
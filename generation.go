@@ -0,0 +1,57 @@
+package arena
+
+// Generation returns a counter that increases every time memory handed out
+// by the arena is invalidated (Reset, ResetCommit or Release). Containers
+// and iterators built on the arena compare generations to detect use of
+// stale memory.
+func (a *Arena) Generation() uint64 {
+	return a.generation
+}
+
+// invalidate marks all memory handed out so far as stale.
+func (a *Arena) invalidate() {
+	a.generation++
+	a.dropInterned()
+	if len(a.hooks) > 0 {
+		a.runResetHooks()
+	}
+}
+
+// dropInterned forgets the values interned by InternValue and by
+// Interners. Besides invalidate, ResetPrepare and rewinding to a Mark call
+// it: the memory they retire stays readable for a while, but must not be
+// handed out again as an interned value.
+func (a *Arena) dropInterned() {
+	a.internGen++
+	if a.interned != nil {
+		clear(a.interned)
+	}
+}
+
+// GenerationGuard records an arena generation so that code holding arena
+// memory across calls (iterators, cursors, container views) can fail
+// deterministically after a Reset instead of reading recycled memory. The
+// containers Vector, Deque, Bitset, Bloom and TypedArena check one on
+// every use, so all of them report use after a Reset with the same panic.
+type GenerationGuard struct {
+	a   *Arena
+	gen uint64
+}
+
+// Guard returns a GenerationGuard for the arena's current generation.
+func (a *Arena) Guard() GenerationGuard {
+	return GenerationGuard{a: a, gen: a.generation}
+}
+
+// Valid reports whether the arena has not been reset or released since
+// the guard was taken.
+func (g GenerationGuard) Valid() bool {
+	return g.a != nil && g.a.generation == g.gen
+}
+
+// Check panics if the guard is no longer valid.
+func (g GenerationGuard) Check() {
+	if !g.Valid() {
+		panic("arena: memory used after Reset()")
+	}
+}
@@ -0,0 +1,49 @@
+package arena
+
+import "testing"
+
+func TestGenerationGuard(t *testing.T) {
+	a := NewArena(1024)
+	g := a.Guard()
+	if !g.Valid() {
+		t.Fatal("fresh guard is not valid")
+	}
+
+	a.AllocBytes(64)
+	if !g.Valid() {
+		t.Error("allocation invalidated the guard")
+	}
+
+	a.Reset()
+	if g.Valid() {
+		t.Error("guard still valid after Reset()")
+	}
+	if a.Generation() != 1 {
+		t.Errorf("Generation() = %d, want 1", a.Generation())
+	}
+
+	// Two-phase reset only invalidates on commit
+	g = a.Guard()
+	a.ResetPrepare()
+	if !g.Valid() {
+		t.Error("guard invalidated by ResetPrepare()")
+	}
+	a.ResetCommit()
+	if g.Valid() {
+		t.Error("guard still valid after ResetCommit()")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic from Check() on stale guard")
+		}
+	}()
+	g.Check()
+}
+
+func TestGenerationGuardZero(t *testing.T) {
+	var g GenerationGuard
+	if g.Valid() {
+		t.Error("zero GenerationGuard should not be valid")
+	}
+}
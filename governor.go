@@ -0,0 +1,175 @@
+package arena
+
+import (
+	"math"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+)
+
+// defaultMemoryLimitFraction is the share of GOMEMLIMIT arenas may hold
+// when GovernorConfig sets neither Limit nor MemoryLimitFraction.
+const defaultMemoryLimitFraction = 0.5
+
+// GovernorConfig configures the process-wide arena governor.
+type GovernorConfig struct {
+	// Limit caps the chunk memory held by all arenas, in bytes. If 0, the
+	// cap is MemoryLimitFraction of the Go memory limit (GOMEMLIMIT), and
+	// there is no cap if no memory limit is set.
+	Limit int64
+	// MemoryLimitFraction is the share of the memory limit used when Limit
+	// is 0; 0.5 if unset.
+	MemoryLimitFraction float64
+	// MaxWait is how long a chunk allocation waits for other arenas to
+	// free memory before it is denied. 0 denies immediately.
+	MaxWait time.Duration
+	// OnPressure, if set, is called when a chunk allocation first has to
+	// wait and when one is denied, so the application can shed load. It
+	// runs on the allocating goroutine and must not allocate from arenas.
+	OnPressure func(GovernorStats)
+}
+
+// GovernorStats reports the state of the arena governor.
+type GovernorStats struct {
+	Limit   int64  // Cap in bytes; 0 if the governor is off or uncapped
+	InUse   int64  // Chunk bytes held by arenas charged to the governor
+	Delayed uint64 // Chunk allocations that had to wait for memory
+	Denied  uint64 // Chunk allocations denied
+}
+
+// governorState is an enabled governor.
+type governorState struct {
+	cfg     GovernorConfig
+	limit   int64
+	delayed atomic.Uint64
+	denied  atomic.Uint64
+}
+
+var (
+	governor      atomic.Pointer[governorState]
+	governorInUse atomic.Int64
+)
+
+// SetGovernor enables the process-wide arena governor, or replaces its
+// configuration. The governor tracks the chunk memory held by all arenas,
+// which the garbage collector cannot see as pressure, against a budget
+// derived from GOMEMLIMIT or given explicitly, and denies or delays new
+// chunks when it is exceeded so arenas do not push the process over its
+// container limit. Denied chunk allocations panic, like an exceeded
+// WithBudget. Only chunks added while the governor is enabled are counted.
+func SetGovernor(cfg GovernorConfig) {
+	g := &governorState{cfg: cfg, limit: cfg.Limit}
+	if g.limit <= 0 {
+		frac := cfg.MemoryLimitFraction
+		if frac <= 0 {
+			frac = defaultMemoryLimitFraction
+		}
+		if mem := debug.SetMemoryLimit(-1); mem < math.MaxInt64 {
+			g.limit = int64(float64(mem) * frac)
+		} else {
+			g.limit = 0
+		}
+	}
+	governor.Store(g)
+}
+
+// DisableGovernor turns the arena governor off. Memory it counted is still
+// uncounted as it is freed, so a later SetGovernor starts consistent.
+func DisableGovernor() {
+	governor.Store(nil)
+}
+
+// ReadGovernorStats returns the governor's current state.
+func ReadGovernorStats() GovernorStats {
+	st := GovernorStats{InUse: governorInUse.Load()}
+	if g := governor.Load(); g != nil {
+		st.Limit = g.limit
+		st.Delayed = g.delayed.Load()
+		st.Denied = g.denied.Load()
+	}
+	return st
+}
+
+// governorHold is the memory an arena has charged to the governor. It is
+// separate from the arena so a cleanup can refund it if the arena is
+// garbage collected without Release.
+type governorHold struct {
+	held atomic.Int64
+}
+
+// refundAll returns everything held to the governor.
+func (h *governorHold) refundAll() {
+	governorInUse.Add(-h.held.Swap(0))
+}
+
+// chargeGovernor charges size bytes of a new chunk to the governor,
+// waiting up to MaxWait for room if wait is set. It reports false if the
+// chunk is denied.
+func (a *Arena) chargeGovernor(size int, wait bool) bool {
+	g := governor.Load()
+	if g == nil {
+		return true
+	}
+	n := int64(size)
+	if !g.acquire(n, wait) {
+		return false
+	}
+	a.holdGovernor(n)
+	return true
+}
+
+// holdGovernor records n bytes charged to the governor as held by a.
+func (a *Arena) holdGovernor(n int64) {
+	if a.gov == nil {
+		a.gov = &governorHold{}
+		runtime.AddCleanup(a, (*governorHold).refundAll, a.gov)
+	}
+	a.gov.held.Add(n)
+}
+
+// refundGovernor returns size bytes of a dropped chunk to the governor.
+func (a *Arena) refundGovernor(size int) {
+	if a.gov == nil {
+		return
+	}
+	n := min(int64(size), a.gov.held.Load())
+	a.gov.held.Add(-n)
+	governorInUse.Add(-n)
+}
+
+// acquire charges n bytes if the limit allows, waiting with backoff up to
+// MaxWait if wait is set.
+func (g *governorState) acquire(n int64, wait bool) bool {
+	var deadline time.Time
+	delay := 50 * time.Microsecond
+	for {
+		if used := governorInUse.Add(n); g.limit <= 0 || used <= g.limit {
+			return true
+		}
+		governorInUse.Add(-n)
+		if !wait || g.cfg.MaxWait <= 0 {
+			break
+		}
+		now := time.Now()
+		if deadline.IsZero() {
+			deadline = now.Add(g.cfg.MaxWait)
+			g.delayed.Add(1)
+			g.pressure()
+		} else if now.After(deadline) {
+			break
+		}
+		time.Sleep(min(delay, deadline.Sub(now)))
+		delay = min(2*delay, 10*time.Millisecond)
+	}
+	g.denied.Add(1)
+	g.pressure()
+	return false
+}
+
+// pressure reports the governor's state to OnPressure.
+func (g *governorState) pressure() {
+	if g.cfg.OnPressure != nil {
+		g.cfg.OnPressure(ReadGovernorStats())
+	}
+}
@@ -0,0 +1,74 @@
+package arena
+
+import (
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+func TestGovernorLimit(t *testing.T) {
+	var pressure []GovernorStats
+	SetGovernor(GovernorConfig{
+		Limit:      governorInUse.Load() + 4096,
+		OnPressure: func(st GovernorStats) { pressure = append(pressure, st) },
+	})
+	defer DisableGovernor()
+
+	a := NewArena(1024)
+	for i := 0; i < 3; i++ {
+		a.AllocBytes(1000)
+	}
+	b := NewArena(1024) // the fourth chunk fills the limit
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic when the governor limit is exceeded")
+			}
+		}()
+		b.AllocBytes(2000)
+	}()
+	st := ReadGovernorStats()
+	if st.Denied != 1 || len(pressure) != 1 || pressure[0].Denied != 1 {
+		t.Errorf("stats = %+v, pressure calls = %v, want one denial", st, pressure)
+	}
+
+	// Release and trimming return memory to the governor.
+	inUse := st.InUse
+	a.Release()
+	if got := ReadGovernorStats().InUse; got != inUse-3*1024 {
+		t.Errorf("InUse after Release = %d, want %d", got, inUse-3*1024)
+	}
+	b.AllocBytes(2000)
+	b.AllocBytes(1000)
+	freed := b.ResetAndShrink(0)
+	if got := ReadGovernorStats().InUse; got != inUse-3*1024 || freed != 2000+1024 {
+		t.Errorf("InUse after shrink = %d, freed %d", got, freed)
+	}
+	b.Release()
+}
+
+func TestGovernorWait(t *testing.T) {
+	SetGovernor(GovernorConfig{Limit: governorInUse.Load() + 1024, MaxWait: 5 * time.Second})
+	defer DisableGovernor()
+
+	a := NewArena(1024)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		a.Release()
+	}()
+	b := NewArena(1024) // waits for a's chunk
+	if st := ReadGovernorStats(); st.Delayed != 1 || st.Denied != 0 {
+		t.Errorf("stats = %+v, want one delayed allocation", st)
+	}
+	b.Release()
+}
+
+func TestGovernorMemoryLimit(t *testing.T) {
+	old := debug.SetMemoryLimit(1 << 30)
+	defer debug.SetMemoryLimit(old)
+	SetGovernor(GovernorConfig{MemoryLimitFraction: 0.25})
+	defer DisableGovernor()
+	if got := ReadGovernorStats().Limit; got != 1<<28 {
+		t.Errorf("Limit = %d, want a quarter of the memory limit", got)
+	}
+}
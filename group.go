@@ -0,0 +1,110 @@
+package arena
+
+import (
+	"context"
+	"sync"
+)
+
+// TaskGroup runs a fan-out of tasks that each get their own arena from an
+// ArenaPool, in the manner of errgroup.Group: the first task to fail
+// cancels the group's context and its error is returned by Wait. Each
+// task's arena is reset and returned to the pool when the task completes,
+// and its activity is added to the group's metrics.
+type TaskGroup struct {
+	pool   *ArenaPool
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+	sem    chan struct{}
+
+	errOnce sync.Once
+	err     error
+
+	mu      sync.Mutex
+	metrics GroupMetrics
+}
+
+// GroupMetrics aggregates the arena activity of the tasks in a TaskGroup.
+type GroupMetrics struct {
+	Tasks     int    // Tasks completed
+	Allocs    uint64 // Allocations served across all tasks
+	Bytes     uint64 // Bytes handed out across all tasks
+	Grows     uint64 // Chunks added across all tasks
+	PeakInUse int    // Largest SizeInUse of a task arena at task completion
+}
+
+// Group returns a TaskGroup drawing arenas from pool, and a context derived
+// from ctx that is canceled when a task returns an error or Wait returns.
+func Group(ctx context.Context, pool *ArenaPool) (*TaskGroup, context.Context) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	return &TaskGroup{pool: pool, cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of tasks running at once to n; Go blocks
+// until a slot is free. n < 0 removes the limit. It must not be called
+// while tasks are running.
+func (g *TaskGroup) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go runs f in a new goroutine with an arena of its own. Memory allocated
+// from the arena must not be retained after f returns.
+func (g *TaskGroup) Go(f func(a *Arena) error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		a := g.pool.Get()
+		start := a.Metrics()
+		err := f(a)
+		end := a.Metrics()
+		g.pool.Put(a)
+
+		g.record(end.DeltaSince(start), end.SizeInUse)
+		if err != nil {
+			g.errOnce.Do(func() {
+				g.err = err
+				g.cancel(err)
+			})
+		}
+	}()
+}
+
+// Wait blocks until all tasks have returned and returns the first error
+// any of them returned.
+func (g *TaskGroup) Wait() error {
+	g.wg.Wait()
+	g.cancel(g.err)
+	return g.err
+}
+
+// Metrics returns the aggregated arena activity of the completed tasks.
+func (g *TaskGroup) Metrics() GroupMetrics {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.metrics
+}
+
+func (g *TaskGroup) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// record adds one task's arena activity to the group metrics.
+func (g *TaskGroup) record(d MetricsDelta, inUse int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	m := &g.metrics
+	m.Tasks++
+	m.Allocs += d.Allocs
+	m.Bytes += d.Bytes
+	m.Grows += d.Grows
+	m.PeakInUse = max(m.PeakInUse, inUse)
+}
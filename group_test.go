@@ -0,0 +1,52 @@
+package arena
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTaskGroup(t *testing.T) {
+	g, _ := Group(context.Background(), NewArenaPool(1024))
+	for i := 1; i <= 8; i++ {
+		g.Go(func(a *Arena) error {
+			mustAllocBytes(t, a, 100*i)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatalf("Wait error = %v", err)
+	}
+	m := g.Metrics()
+	if m.Tasks != 8 || m.Allocs != 8 || m.Bytes != 3600 {
+		t.Errorf("Metrics = %+v, want 8 tasks, 8 allocs, 3600 bytes", m)
+	}
+	if m.PeakInUse < 800 {
+		t.Errorf("PeakInUse = %d, want at least 800", m.PeakInUse)
+	}
+}
+
+func TestTaskGroupError(t *testing.T) {
+	errBoom := errors.New("boom")
+	g, ctx := Group(context.Background(), NewArenaPool(0))
+	g.SetLimit(2)
+	var canceled atomic.Int32
+	g.Go(func(*Arena) error { return errBoom })
+	for i := 0; i < 4; i++ {
+		g.Go(func(*Arena) error {
+			<-ctx.Done()
+			canceled.Add(1)
+			return ctx.Err()
+		})
+	}
+	if err := g.Wait(); err != errBoom {
+		t.Errorf("Wait error = %v, want %v", err, errBoom)
+	}
+	if canceled.Load() != 4 {
+		t.Errorf("%d tasks saw cancellation, want 4", canceled.Load())
+	}
+	if context.Cause(ctx) != errBoom {
+		t.Errorf("context cause = %v, want %v", context.Cause(ctx), errBoom)
+	}
+}
@@ -0,0 +1,105 @@
+package arena
+
+// Resettable is implemented by objects that wrap arena memory and must drop
+// it when the arena is reset, such as a bufio.Writer over an arena buffer.
+type Resettable interface {
+	Reset()
+}
+
+// resetHook is a callback run when arena memory is invalidated.
+type resetHook struct {
+	id   uint64
+	fn   func()
+	once bool // removed after its first run
+}
+
+// OnReset registers fn to run every time the arena's memory is invalidated:
+// on Reset, ResetCommit and Release. Hooks run in registration order after
+// the arena has been rewound, so they may allocate again. The returned
+// function unregisters the hook.
+func (a *Arena) OnReset(fn func()) (remove func()) {
+	return a.addResetHook(fn, false)
+}
+
+// Tie calls obj.Reset the next time a's memory is invalidated and then
+// forgets obj. This is the lifecycle needed by pooled objects built on arena
+// memory: tie the object when it starts using the arena, and it is reset
+// (and can be returned to its sync.Pool) before the memory is reused. The
+// returned function unties obj early, e.g. when it is recycled before the
+// arena is reset.
+func Tie(obj Resettable, a *Arena) (untie func()) {
+	return a.addResetHook(obj.Reset, true)
+}
+
+func (a *Arena) addResetHook(fn func(), once bool) func() {
+	a.hookSeq++
+	id := a.hookSeq
+	a.hooks = append(a.hooks, resetHook{id: id, fn: fn, once: once})
+	return func() {
+		for i, h := range a.hooks {
+			if h.id == id {
+				a.hooks = append(a.hooks[:i], a.hooks[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// runResetHooks runs all registered hooks, dropping one-shot ones first so
+// hooks may safely re-register themselves.
+func (a *Arena) runResetHooks() {
+	hooks := a.hooks
+	kept := make([]resetHook, 0, len(hooks))
+	for _, h := range hooks {
+		if !h.once {
+			kept = append(kept, h)
+		}
+	}
+	a.hooks = kept
+	for _, h := range hooks {
+		h.fn()
+	}
+}
+
+// WithAllocBudgetHook calls fn after every everyN allocations, counted
+// across resets, so long arena-driven batch loops can yield with
+// runtime.Gosched, check a context for cancellation or flush partial
+// results without counting iterations themselves:
+//
+//	a := arena.NewArena(0, arena.WithAllocBudgetHook(10000, func() {
+//		if ctx.Err() != nil {
+//			panic(errCanceled) // recovered by the batch driver
+//		}
+//		runtime.Gosched()
+//	}))
+//
+// fn runs on the allocating goroutine just before the allocation that
+// completes the count returns, and may itself allocate from the arena.
+// Every allocation function counts, including the allocations behind
+// containers. everyN <= 0 or a nil fn disables the hook.
+func WithAllocBudgetHook(everyN int, fn func()) Option {
+	return func(a *Arena) {
+		if everyN <= 0 || fn == nil {
+			a.allocHook = nil
+			a.allocHookAt = 0
+			return
+		}
+		a.allocHook = &allocHook{every: uint64(everyN), fn: fn}
+		a.allocHookAt = a.allocs + uint64(everyN)
+	}
+}
+
+// allocHook is the callback set by WithAllocBudgetHook.
+type allocHook struct {
+	every uint64
+	fn    func()
+}
+
+// runAllocHook schedules the next call and runs the allocation hook.
+//
+//go:noinline
+func (a *Arena) runAllocHook() {
+	h := a.allocHook
+	a.allocHookAt += h.every
+	h.fn()
+}
@@ -0,0 +1,101 @@
+package arena
+
+import (
+	"bufio"
+	"bytes"
+	"sync"
+	"testing"
+)
+
+type countingResetter struct{ resets int }
+
+func (c *countingResetter) Reset() { c.resets++ }
+
+func TestOnReset(t *testing.T) {
+	a := NewArena(1024)
+	calls := 0
+	remove := a.OnReset(func() { calls++ })
+
+	a.Reset()
+	a.Reset()
+	if calls != 2 {
+		t.Errorf("hook calls = %d, want 2", calls)
+	}
+
+	remove()
+	a.Reset()
+	if calls != 2 {
+		t.Errorf("hook ran after removal: calls = %d", calls)
+	}
+}
+
+func TestTie(t *testing.T) {
+	a := NewArena(1024)
+	obj := &countingResetter{}
+	Tie(obj, a)
+
+	a.Reset()
+	a.Reset()
+	if obj.resets != 1 {
+		t.Errorf("tied object resets = %d, want 1 (one-shot)", obj.resets)
+	}
+
+	early := &countingResetter{}
+	untie := Tie(early, a)
+	untie()
+	a.Release()
+	if early.resets != 0 {
+		t.Errorf("untied object was reset %d times", early.resets)
+	}
+}
+
+func TestTieWithPool(t *testing.T) {
+	var sink bytes.Buffer
+	pool := sync.Pool{New: func() any { return bufio.NewWriterSize(&sink, 16) }}
+	a := NewArena(1024)
+
+	// A pooled writer is tied to the arena while it is in use and
+	// recycled automatically on Reset.
+	w := pool.Get().(*bufio.Writer)
+	recycled := false
+	a.OnReset(func() {
+		w.Reset(&sink)
+		pool.Put(w)
+		recycled = true
+	})
+	w.Write(CloneBytes(a, []byte("data")))
+
+	a.Reset()
+	if !recycled || w.Buffered() != 0 {
+		t.Errorf("writer not recycled on Reset: recycled=%v buffered=%d", recycled, w.Buffered())
+	}
+}
+
+func TestWithAllocBudgetHook(t *testing.T) {
+	calls := 0
+	var a *Arena
+	a = NewArena(256, WithAllocBudgetHook(3, func() {
+		calls++
+		a.AllocBytes(8) // allocating from the hook counts towards the next call
+	}))
+	a.AllocBytes(8)
+	a.AllocBytes(8)
+	if calls != 0 {
+		t.Fatalf("hook ran %d times after 2 allocations, want 0", calls)
+	}
+	Alloc[int64](a)
+	if calls != 1 {
+		t.Fatalf("hook ran %d times after 3 allocations, want 1", calls)
+	}
+	a.Reset()
+	a.AllocBytes(1000) // slow path; the hook's allocation was the 4th
+	a.AllocBytes(8)
+	if calls != 2 {
+		t.Errorf("hook ran %d times after 6 allocations across a reset, want 2", calls)
+	}
+
+	b := NewArena(256, WithAllocBudgetHook(0, func() { t.Error("disabled hook ran") }))
+	for range 10 {
+		b.AllocBytes(8)
+	}
+}
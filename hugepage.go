@@ -0,0 +1,24 @@
+package arena
+
+// hugePageSize is the size of a transparent huge page on x86-64 and most
+// arm64 kernels.
+const hugePageSize = 2 << 20
+
+// WithHugePages backs the arena's chunks with 2MB-aligned mappings advised
+// for transparent huge pages (madvise MADV_HUGEPAGE on Linux), cutting TLB
+// misses for very large arenas such as those of analytics batch jobs. It
+// implies WithMmap, whose notes apply. Each chunk is rounded up to a
+// multiple of 2MB, and a huge page is faulted in whole on first touch, so
+// use chunk sizes of several megabytes. Reset discards whole huge pages.
+//
+// Metrics().HugePageBytes reports how much chunk memory the advice was
+// taken for; it is zero on other platforms, where the chunks are ordinary
+// mappings, and when transparent huge pages are disabled. The kernel may
+// still fall back to small pages when memory is fragmented; AnonHugePages
+// in /proc/self/smaps shows what it actually used.
+func WithHugePages() Option {
+	return func(a *Arena) {
+		WithMmap()(a)
+		a.maps.huge = true
+	}
+}
@@ -0,0 +1,49 @@
+package arena
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"testing"
+	"unsafe"
+)
+
+func TestWithHugePages(t *testing.T) {
+	a := NewArena(3<<20, WithHugePages())
+	defer a.Release()
+	b := a.AllocBytes(1 << 20)
+	b[0], b[len(b)-1] = 1, 1
+
+	m := a.Metrics()
+	if runtime.GOOS != "linux" {
+		if m.HugePageBytes != 0 {
+			t.Errorf("HugePageBytes = %d without transparent huge pages, want 0", m.HugePageBytes)
+		}
+		return
+	}
+	if p := uintptr(unsafe.Pointer(unsafe.SliceData(a.chunks[0].buf))); p%hugePageSize != 0 {
+		t.Errorf("chunk at %#x is not huge page aligned", p)
+	}
+	mode, err := os.ReadFile("/sys/kernel/mm/transparent_hugepage/enabled")
+	if err != nil || bytes.Contains(mode, []byte("[never]")) {
+		if m.HugePageBytes != 0 {
+			t.Errorf("HugePageBytes = %d with huge pages disabled, want 0", m.HugePageBytes)
+		}
+		return
+	}
+	if m.HugePageBytes != m.Capacity {
+		t.Errorf("HugePageBytes = %d, want the capacity %d", m.HugePageBytes, m.Capacity)
+	}
+
+	// Lifetime classes map their chunks the same way.
+	s := a.In(SessionScoped)
+	s.AllocBytes(8)
+	if got := s.Metrics().HugePageBytes; got != s.Capacity() {
+		t.Errorf("class arena HugePageBytes = %d, want %d", got, s.Capacity())
+	}
+
+	a.Reset()
+	if got := a.Metrics().HugePageBytes; got != m.Capacity {
+		t.Errorf("HugePageBytes = %d after Reset, want %d", got, m.Capacity)
+	}
+}
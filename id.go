@@ -0,0 +1,79 @@
+package arena
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"time"
+	"unsafe"
+)
+
+const (
+	uuidLen   = 36
+	ulidLen   = 26
+	hexDigits = "0123456789abcdef"
+	// crockford is the Crockford base32 alphabet used by ULIDs.
+	crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+// NewUUIDString returns a random (version 4) UUID in its canonical
+// 36-character form, formatted directly into arena memory. Unlike the
+// String method of common UUID packages it makes no heap allocation. The
+// string is valid until the arena is reset or released.
+func (a *Arena) NewUUIDString() string {
+	b := a.AllocBytes(uuidLen)
+	putUUID(b)
+	return unsafe.String(&b[0], uuidLen)
+}
+
+// AppendUUID appends a random (version 4) UUID in canonical form to dst,
+// growing dst inside the arena as Append does.
+func AppendUUID(a *Arena, dst []byte) []byte {
+	dst = GrowSlice(a, dst, len(dst)+uuidLen)
+	putUUID(dst[len(dst) : len(dst)+uuidLen])
+	return dst[:len(dst)+uuidLen]
+}
+
+// AppendULID appends a ULID for the current time to dst, growing dst
+// inside the arena as Append does. ULIDs are 26 characters of Crockford
+// base32: a millisecond timestamp followed by 80 random bits, so they sort
+// by creation time.
+func AppendULID(a *Arena, dst []byte) []byte {
+	dst = GrowSlice(a, dst, len(dst)+ulidLen)
+	putULID(dst[len(dst):len(dst)+ulidLen], time.Now())
+	return dst[:len(dst)+ulidLen]
+}
+
+// putUUID writes a random version 4 UUID to the 36 bytes of b.
+func putUUID(b []byte) {
+	var u [16]byte
+	rand.Read(u[:])
+	u[6] = u[6]&0x0f | 0x40 // version 4
+	u[8] = u[8]&0x3f | 0x80 // RFC 9562 variant
+	j := 0
+	for i, v := range u {
+		if i == 4 || i == 6 || i == 8 || i == 10 {
+			b[j] = '-'
+			j++
+		}
+		b[j] = hexDigits[v>>4]
+		b[j+1] = hexDigits[v&0x0f]
+		j += 2
+	}
+}
+
+// putULID writes the ULID for t and fresh randomness to the 26 bytes of b.
+func putULID(b []byte, t time.Time) {
+	var u [16]byte
+	ms := uint64(t.UnixMilli())
+	binary.BigEndian.PutUint16(u[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(u[2:6], uint32(ms))
+	rand.Read(u[6:])
+	// 128 bits as 26 base32 digits: the first digit holds the top 3 bits.
+	hi := binary.BigEndian.Uint64(u[0:8])
+	lo := binary.BigEndian.Uint64(u[8:16])
+	for i := ulidLen - 1; i >= 0; i-- {
+		b[i] = crockford[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+}
@@ -0,0 +1,75 @@
+package arena
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	ulidPattern = regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`)
+)
+
+func TestNewUUIDString(t *testing.T) {
+	a := NewArena(1024)
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		id := a.NewUUIDString()
+		if !uuidPattern.MatchString(id) {
+			t.Fatalf("NewUUIDString() = %q, not a version 4 UUID", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate UUID %q", id)
+		}
+		seen[id] = true
+	}
+
+	dst := AppendUUID(a, []byte("id="))
+	if !strings.HasPrefix(string(dst), "id=") || !uuidPattern.Match(dst[3:]) {
+		t.Errorf("AppendUUID = %q", dst)
+	}
+
+	if raceEnabled {
+		return // the race detector makes the random buffer escape
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		a.NewUUIDString()
+		a.Reset()
+	})
+	if allocs != 0 {
+		t.Errorf("NewUUIDString made %v heap allocations, want 0", allocs)
+	}
+}
+
+func TestAppendULID(t *testing.T) {
+	a := NewArena(1024)
+	first := AppendULID(a, nil)
+	if !ulidPattern.Match(first) {
+		t.Fatalf("AppendULID = %q, not a ULID", first)
+	}
+	time.Sleep(2 * time.Millisecond)
+	second := AppendULID(a, nil)
+	if string(second[:10]) <= string(first[:10]) {
+		t.Errorf("ULID timestamps not increasing: %s then %s", first, second)
+	}
+
+	// Known encoding: timestamp 1 ms with zero randomness.
+	b := make([]byte, ulidLen)
+	putULID(b, time.UnixMilli(1))
+	if got := string(b[:10]); got != "0000000001" {
+		t.Errorf("ULID timestamp for 1ms = %q, want 0000000001", got)
+	}
+
+	if raceEnabled {
+		return
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		AppendULID(a, nil)
+		a.Reset()
+	})
+	if allocs != 0 {
+		t.Errorf("AppendULID made %v heap allocations, want 0", allocs)
+	}
+}
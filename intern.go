@@ -0,0 +1,93 @@
+package arena
+
+import "unsafe"
+
+// InternValue returns a pointer to an arena-resident copy of v, reusing the
+// same slot for every call with an equal value until the arena is reset
+// (or ResetPrepare retires the slot).
+// Use it for small immutable values that are allocated over and over, such
+// as status codes or enum values boxed into interfaces. The pointed-to value
+// must not be modified.
+func InternValue[T comparable](a *Arena, v T) *T {
+	if p, ok := a.interned[v]; ok {
+		return (*T)(p)
+	}
+	p := AllocUninitialized[T](a)
+	*p = v
+	if a.interned == nil {
+		a.interned = make(map[any]unsafe.Pointer)
+	}
+	a.interned[v] = unsafe.Pointer(p)
+	return p
+}
+
+// NumInterned returns the number of distinct values interned since the last Reset.
+func (a *Arena) NumInterned() int {
+	return len(a.interned)
+}
+
+// AllocString copies s into the arena and returns the arena-backed copy.
+// It is CloneString under the name used by the other Alloc functions.
+func AllocString(a *Arena, s string) string {
+	return CloneString(a, s)
+}
+
+// Interner deduplicates strings within an arena: equal strings interned
+// during one arena generation share a single arena-backed copy. Parsers
+// and request handlers use it to store each distinct header name or key
+// once per request instead of once per occurrence. The table is dropped
+// automatically when the arena is reset. An Interner is not goroutine-safe.
+type Interner struct {
+	a    *Arena
+	gen  uint64 // a.internGen the table belongs to
+	strs map[string]string
+}
+
+// NewInterner returns an Interner placing strings in a.
+func NewInterner(a *Arena) *Interner {
+	return &Interner{a: a, gen: a.internGen, strs: make(map[string]string)}
+}
+
+// Intern returns the arena-backed copy of s, copying s into the arena the
+// first time it is seen in the current generation.
+func (in *Interner) Intern(s string) string {
+	in.sync()
+	if v, ok := in.strs[s]; ok {
+		return v
+	}
+	v := CloneString(in.a, s)
+	in.strs[v] = v
+	return v
+}
+
+// InternBytes is like Intern for a byte slice. Looking up a string that
+// was already interned does not allocate.
+func (in *Interner) InternBytes(b []byte) string {
+	in.sync()
+	if v, ok := in.strs[string(b)]; ok {
+		return v
+	}
+	var v string
+	if len(b) > 0 {
+		dst := CloneBytes(in.a, b)
+		v = unsafe.String(&dst[0], len(dst))
+	}
+	in.strs[v] = v
+	return v
+}
+
+// Len returns the number of distinct strings interned in the current
+// arena generation.
+func (in *Interner) Len() int {
+	in.sync()
+	return len(in.strs)
+}
+
+// sync forgets the interned strings once the arena has been reset, or
+// their memory retired by ResetPrepare or a rewind to a Mark.
+func (in *Interner) sync() {
+	if in.gen != in.a.internGen {
+		clear(in.strs)
+		in.gen = in.a.internGen
+	}
+}
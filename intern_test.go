@@ -0,0 +1,121 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestInternValue(t *testing.T) {
+	a := NewArena(1024)
+
+	p1 := InternValue(a, 200)
+	p2 := InternValue(a, 200)
+	if p1 != p2 {
+		t.Error("InternValue returned different slots for equal values")
+	}
+	if *p1 != 200 {
+		t.Errorf("*InternValue(200) = %d, want 200", *p1)
+	}
+
+	// Distinct values and types get distinct slots
+	if InternValue(a, 404) == p1 {
+		t.Error("InternValue(404) reused the slot for 200")
+	}
+	if b := InternValue(a, true); !*b {
+		t.Error("*InternValue(true) = false")
+	}
+	if InternValue(a, int64(200)) == (*int64)(nil) {
+		t.Error("InternValue(int64) returned nil")
+	}
+	if a.NumInterned() != 4 {
+		t.Errorf("NumInterned() = %d, want 4", a.NumInterned())
+	}
+
+	used := a.SizeInUse()
+	InternValue(a, 404)
+	if a.SizeInUse() != used {
+		t.Error("interning an existing value allocated memory")
+	}
+
+	a.Reset()
+	if a.NumInterned() != 0 {
+		t.Errorf("NumInterned() after Reset = %d, want 0", a.NumInterned())
+	}
+}
+
+func BenchmarkInternValue(b *testing.B) {
+	a := NewArena(1024 * 1024)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		InternValue(a, i&0x3f)
+	}
+}
+
+func TestInterner(t *testing.T) {
+	a := NewArena(1024)
+	in := NewInterner(a)
+
+	s1 := in.Intern(strings.Clone("content-type"))
+	s2 := in.InternBytes([]byte("content-type"))
+	if s1 != "content-type" || unsafe.StringData(s1) != unsafe.StringData(s2) {
+		t.Error("equal strings were not deduplicated")
+	}
+	if in.Intern("accept") == s1 || in.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", in.Len())
+	}
+
+	used := a.SizeInUse()
+	key := []byte("accept")
+	if n := testing.AllocsPerRun(10, func() { in.InternBytes(key) }); n != 0 {
+		t.Errorf("InternBytes of a known string allocated %v times", n)
+	}
+	if a.SizeInUse() != used {
+		t.Error("interning a known string used arena memory")
+	}
+
+	a.Reset()
+	if in.Len() != 0 {
+		t.Errorf("Len() after Reset = %d, want 0", in.Len())
+	}
+	if s := in.Intern("accept"); s != "accept" {
+		t.Errorf("Intern after Reset = %q", s)
+	}
+}
+
+func TestInternAcrossResetPrepare(t *testing.T) {
+	a := NewArena(1024)
+	in := NewInterner(a)
+	InternValue(a, 200)
+	in.Intern("content-type")
+
+	a.ResetPrepare()
+	p := InternValue(a, 200)
+	s := in.Intern("content-type")
+	a.ResetCommit()
+
+	// Fill the recycled chunks; the values interned after ResetPrepare
+	// must not have been served from them.
+	for i := 0; i < 4; i++ {
+		b := a.AllocBytes(900)
+		for j := range b {
+			b[j] = 0xff
+		}
+	}
+	if *p != 200 {
+		t.Errorf("interned value = %d after ResetCommit, want 200", *p)
+	}
+	if s != "content-type" {
+		t.Errorf("interned string = %q after ResetCommit, want %q", s, "content-type")
+	}
+}
+
+func TestAllocString(t *testing.T) {
+	a := NewArena(1024)
+	if s := AllocString(a, "hello"); s != "hello" {
+		t.Errorf("AllocString = %q, want %q", s, "hello")
+	}
+	if s := AllocString(a, ""); s != "" {
+		t.Errorf("AllocString(\"\") = %q", s)
+	}
+}
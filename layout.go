@@ -0,0 +1,70 @@
+package arena
+
+import (
+	"fmt"
+	"io"
+	"unsafe"
+)
+
+// StableBase is the virtual address of the first chunk of an arena created
+// with WithStableAddresses.
+const StableBase = 0x10000
+
+// WithStableAddresses makes the arena's debug output reproducible across
+// runs, for golden-file tests of layouts and dumps. Addresses reported by
+// Addr and DumpLayout become virtual: chunks are laid end to end from
+// StableBase in chunk order, so they depend only on the sequence of
+// operations, not on ASLR or the Go heap. The event log records goroutine
+// 0 and the zero time instead of the real values. Allocation itself is
+// unaffected.
+func WithStableAddresses() Option {
+	return func(a *Arena) {
+		a.stableAddrs = true
+	}
+}
+
+// Addr returns the address of the first byte of b, which must have been
+// allocated from the arena, or 0 if b is empty or not in the arena. Under
+// WithStableAddresses the address is virtual.
+func (a *Arena) Addr(b []byte) uintptr {
+	if len(b) == 0 {
+		return 0
+	}
+	p := uintptr(unsafe.Pointer(&b[0]))
+	for i := range a.chunks {
+		base := uintptr(unsafe.Pointer(unsafe.SliceData(a.chunks[i].buf)))
+		if p >= base && p < base+uintptr(len(a.chunks[i].buf)) {
+			return a.chunkAddr(i) + (p - base)
+		}
+	}
+	return 0
+}
+
+// DumpLayout writes one line per chunk to w describing its base address,
+// size, bytes in use and idle reset cycles. Combined with
+// WithStableAddresses the output is stable enough to compare against a
+// golden file.
+func (a *Arena) DumpLayout(w io.Writer) error {
+	for i := range a.chunks {
+		info := a.chunkInfo(i)
+		_, err := fmt.Fprintf(w, "chunk=%d base=%#x size=%d used=%d idle=%d\n",
+			i, a.chunkAddr(i), info.Size, info.Used, info.IdleResets)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// chunkAddr returns the base address of chunk i, virtual under
+// WithStableAddresses.
+func (a *Arena) chunkAddr(i int) uintptr {
+	if !a.stableAddrs {
+		return uintptr(unsafe.Pointer(unsafe.SliceData(a.chunks[i].buf)))
+	}
+	addr := uintptr(StableBase)
+	for _, c := range a.chunks[:i] {
+		addr += uintptr(len(c.buf))
+	}
+	return addr
+}
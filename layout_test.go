@@ -0,0 +1,50 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStableLayout(t *testing.T) {
+	dump := func() (string, string) {
+		a := NewArena(1024, WithStableAddresses())
+		a.EnableEventLog(8)
+		a.AllocBytes(100)
+		b := a.AllocBytes(2000)
+		if got := a.Addr(b); got != StableBase+1024 {
+			t.Errorf("Addr = %#x, want %#x", got, StableBase+1024)
+		}
+		var layout, events strings.Builder
+		if err := a.DumpLayout(&layout); err != nil {
+			t.Fatal(err)
+		}
+		a.DumpEvents(&events)
+		return layout.String(), events.String()
+	}
+
+	layout, events := dump()
+	want := "chunk=0 base=0x10000 size=1024 used=100 idle=0\n" +
+		"chunk=1 base=0x10400 size=2000 used=2000 idle=0\n"
+	if layout != want {
+		t.Errorf("DumpLayout =\n%s\nwant\n%s", layout, want)
+	}
+	if !strings.Contains(events, "goroutine=0") {
+		t.Errorf("stable event log records goroutine IDs:\n%s", events)
+	}
+
+	layout2, events2 := dump()
+	if layout2 != layout || events2 != events {
+		t.Error("stable dumps differ between runs")
+	}
+}
+
+func TestAddrOutsideArena(t *testing.T) {
+	a := NewArena(1024)
+	b := a.AllocBytes(8)
+	if a.Addr(b) == 0 {
+		t.Error("Addr of arena memory = 0")
+	}
+	if got := a.Addr(make([]byte, 8)); got != 0 {
+		t.Errorf("Addr of foreign memory = %#x, want 0", got)
+	}
+}
@@ -0,0 +1,113 @@
+package arena
+
+import (
+	"log"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// leakStackDepth bounds the creation stack recorded for leak reports.
+const leakStackDepth = 32
+
+// LeakReport describes an arena that was garbage collected without being
+// released.
+type LeakReport struct {
+	Name  string // Arena name set with WithName
+	Stack string // Where the arena was created or taken from an ArenaPool
+}
+
+var leakHandler atomic.Pointer[func(LeakReport)]
+
+// SetLeakHandler sets the function called for every leaked arena found by
+// leak detection, replacing the default, which logs the report. It runs on
+// a runtime cleanup goroutine, so it must be safe for concurrent use and
+// must not block. A nil fn restores the default.
+func SetLeakHandler(fn func(LeakReport)) {
+	if fn == nil {
+		leakHandler.Store(nil)
+		return
+	}
+	leakHandler.Store(&fn)
+}
+
+// WithLeakDetection records where the arena was created and reports it
+// through the leak handler (see SetLeakHandler) if the arena is garbage
+// collected without Release, the usual sign of a request path that forgot
+// to return its arena. Arenas held by an ArenaPool are not reported, and
+// those taken from one report the Get call site instead. Leak detection
+// is also enabled by DebugFull. It costs a stack capture per arena and is
+// meant for tests and debug builds.
+func WithLeakDetection() Option {
+	return func(a *Arena) {
+		a.leak = &leakCheck{}
+	}
+}
+
+// leakCheck is an arena's armed leak cleanup.
+type leakCheck struct {
+	cleanup runtime.Cleanup
+	armed   bool
+}
+
+// leakSite is the state a leak cleanup needs; it must not reference the
+// arena, or the arena would never become unreachable.
+type leakSite struct {
+	name string
+	pcs  []uintptr
+}
+
+// armLeakCheck records the caller's stack and registers the cleanup that
+// reports the arena if it is collected before disarmLeakCheck. skip is the
+// number of arena frames to leave out of the stack.
+func (a *Arena) armLeakCheck(skip int) {
+	if a.leak == nil || a.leak.armed {
+		return
+	}
+	pcs := make([]uintptr, leakStackDepth)
+	site := &leakSite{name: a.name, pcs: pcs[:runtime.Callers(skip+2, pcs)]}
+	a.leak.cleanup = runtime.AddCleanup(a, reportLeak, site)
+	a.leak.armed = true
+}
+
+// disarmLeakCheck cancels the cleanup registered by armLeakCheck.
+func (a *Arena) disarmLeakCheck() {
+	if a.leak != nil && a.leak.armed {
+		a.leak.cleanup.Stop()
+		a.leak.armed = false
+	}
+}
+
+// reportLeak passes the report for a leaked arena to the leak handler.
+func reportLeak(site *leakSite) {
+	r := LeakReport{Name: site.name, Stack: formatStack(site.pcs)}
+	if fn := leakHandler.Load(); fn != nil {
+		(*fn)(r)
+		return
+	}
+	name := r.Name
+	if name == "" {
+		name = "unnamed"
+	}
+	log.Printf("arena: %s arena garbage collected without Release; created at:\n%s", name, r.Stack)
+}
+
+// formatStack renders program counters as a goroutine-style stack trace.
+func formatStack(pcs []uintptr) string {
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		sb.WriteString(f.Function)
+		sb.WriteString("\n\t")
+		sb.WriteString(f.File)
+		sb.WriteByte(':')
+		sb.WriteString(strconv.Itoa(f.Line))
+		sb.WriteByte('\n')
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
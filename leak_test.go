@@ -0,0 +1,81 @@
+package arena
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// collectLeaks runs the garbage collector until want leak reports arrive
+// or timeout passes, and returns the reports.
+func collectLeaks(reports chan LeakReport, want int, timeout time.Duration) []LeakReport {
+	var got []LeakReport
+	deadline := time.Now().Add(timeout)
+	for len(got) < want && time.Now().Before(deadline) {
+		runtime.GC()
+		select {
+		case r := <-reports:
+			got = append(got, r)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	return got
+}
+
+// testLeakHandler forwards the reports for arenas named by these tests,
+// ignoring arenas other tests leave for the collector.
+func testLeakHandler(reports chan LeakReport) func(LeakReport) {
+	return func(r LeakReport) {
+		if strings.HasPrefix(r.Name, "leaktest-") {
+			reports <- r
+		}
+	}
+}
+
+//go:noinline
+func leakArena() {
+	NewArena(1024, WithLeakDetection(), WithName("leaktest-leaky")).AllocBytes(8)
+}
+
+func TestLeakDetection(t *testing.T) {
+	reports := make(chan LeakReport, 16)
+	SetLeakHandler(testLeakHandler(reports))
+	defer SetLeakHandler(nil)
+
+	leakArena()
+	got := collectLeaks(reports, 1, 5*time.Second)
+	if len(got) != 1 {
+		t.Fatalf("got %d leak reports, want 1", len(got))
+	}
+	if got[0].Name != "leaktest-leaky" || !strings.Contains(got[0].Stack, "leakArena") {
+		t.Errorf("report = %+v, want name leaktest-leaky and a stack through leakArena", got[0])
+	}
+
+	// Released and pooled arenas are not leaks.
+	func() {
+		NewArena(1024, WithLeakDetection(), WithName("leaktest-released")).Release()
+		pool := NewArenaPool(1024, WithLeakDetection(), WithName("leaktest-pooled"))
+		pool.Put(pool.Get())
+	}()
+	if got := collectLeaks(reports, 1, 200*time.Millisecond); len(got) != 0 {
+		t.Errorf("unexpected leak reports: %+v", got)
+	}
+}
+
+func TestLeakDetectionPoolGet(t *testing.T) {
+	reports := make(chan LeakReport, 16)
+	SetLeakHandler(testLeakHandler(reports))
+	defer SetLeakHandler(nil)
+
+	pool := NewArenaPool(1024, WithLeakDetection(), WithName("leaktest-pool"))
+	pool.Put(pool.Get())
+	func() {
+		pool.Get() // taken and never returned
+	}()
+	got := collectLeaks(reports, 1, 5*time.Second)
+	if len(got) != 1 || !strings.Contains(got[0].Stack, "TestLeakDetectionPoolGet") {
+		t.Errorf("reports = %+v, want one naming the Get call site", got)
+	}
+	runtime.KeepAlive(pool)
+}
@@ -0,0 +1,374 @@
+package arena
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Log segment layout, in memory and on disk. All integers are little endian.
+//
+//	header (16 bytes):
+//	  [0:8]   magic "ARENALOG"
+//	  [8:16]  segment sequence number (uint64)
+//	records, each:
+//	  [0:4]   record length n (uint32)
+//	  [4:8]   CRC-32C of the record data
+//	  [8:8+n] record data
+const (
+	logHeaderSize       = 16
+	logRecordHeaderSize = 8
+	logFileExt          = ".seg"
+)
+
+var logMagic = [8]byte{'A', 'R', 'E', 'N', 'A', 'L', 'O', 'G'}
+
+var (
+	// ErrRecordTooLarge is returned by Log.Append for records that do not
+	// fit in an empty segment.
+	ErrRecordTooLarge = errors.New("arena: log record larger than a segment")
+	// ErrLogCorrupt is returned when a segment's header or a record fails
+	// validation.
+	ErrLogCorrupt = errors.New("arena: corrupt log segment")
+	// ErrLogClosed is returned by Log.Append after Close.
+	ErrLogClosed = errors.New("arena: log closed")
+)
+
+// LogOptions configures NewLog.
+type LogOptions struct {
+	// SegmentSize is the size of a segment in bytes, including its header.
+	// DefaultChunkSize is used if <= 0.
+	SegmentSize int
+	// Dir, if set, is the directory sealed segments are written to, one
+	// file per segment. Once written, a segment's memory is reused for new
+	// records and readers load it from disk. Without Dir, sealed segments
+	// stay in memory until discarded.
+	Dir string
+}
+
+// Log is an append-only record log built on arenas. Records are appended
+// to the current segment, an arena chunk; when it fills up it is sealed
+// and, with LogOptions.Dir, flushed to a segment file so its memory can be
+// reused. Readers iterate the sealed segments in order, which makes a Log
+// a building block for write-ahead logs and telemetry buffers. Records are
+// checksummed with CRC-32C. A Log is not goroutine-safe.
+type Log struct {
+	segSize int
+	dir     string
+	pool    *ArenaPool
+	cur     *logSegment
+	sealed  []*logSegment
+	nextSeq uint64
+	closed  bool
+}
+
+// logSegment is a segment held in memory or, once flushed, on disk.
+type logSegment struct {
+	seq  uint64
+	a    *Arena // holds buf; nil once flushed or discarded
+	buf  []byte // header and records; nil once flushed
+	size int
+	path string // set once flushed
+}
+
+// LogSegment describes a sealed segment.
+type LogSegment struct {
+	Seq  uint64 // Sequence number; segments are numbered in append order
+	Size int    // Bytes including the header
+	Path string // Segment file, or "" if the segment is only in memory
+}
+
+// NewLog creates a Log. With opts.Dir the directory is created if needed,
+// and numbering continues after any segment files already in it.
+func NewLog(opts LogOptions) (*Log, error) {
+	size := opts.SegmentSize
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if size <= logHeaderSize+logRecordHeaderSize {
+		return nil, fmt.Errorf("arena: log segment size %d too small", size)
+	}
+	l := &Log{segSize: size, dir: opts.Dir, pool: NewArenaPool(size)}
+	if l.dir != "" {
+		if err := os.MkdirAll(l.dir, 0o755); err != nil {
+			return nil, err
+		}
+		seqs, err := logDirSeqs(l.dir)
+		if err != nil {
+			return nil, err
+		}
+		if len(seqs) > 0 {
+			l.nextSeq = seqs[len(seqs)-1] + 1
+		}
+	}
+	return l, nil
+}
+
+// Append adds rec to the log, sealing the current segment first if rec
+// does not fit. rec is copied.
+func (l *Log) Append(rec []byte) error {
+	if l.closed {
+		return ErrLogClosed
+	}
+	need := logRecordHeaderSize + len(rec)
+	if logHeaderSize+need > l.segSize {
+		return ErrRecordTooLarge
+	}
+	if l.cur != nil && len(l.cur.buf)+need > cap(l.cur.buf) {
+		if err := l.Seal(); err != nil {
+			return err
+		}
+	}
+	if l.cur == nil {
+		l.cur = l.newSegment()
+	}
+	s := l.cur
+	var hdr [logRecordHeaderSize]byte
+	binary.LittleEndian.PutUint32(hdr[0:4], uint32(len(rec)))
+	binary.LittleEndian.PutUint32(hdr[4:8], crc32.Checksum(rec, crcTable))
+	s.buf = append(append(s.buf, hdr[:]...), rec...)
+	s.size = len(s.buf)
+	return nil
+}
+
+// newSegment starts a segment in an arena from the pool.
+func (l *Log) newSegment() *logSegment {
+	a := l.pool.Get()
+	s := &logSegment{seq: l.nextSeq, a: a, buf: a.AllocBytes(l.segSize)[:logHeaderSize]}
+	l.nextSeq++
+	copy(s.buf[0:8], logMagic[:])
+	binary.LittleEndian.PutUint64(s.buf[8:16], s.seq)
+	s.size = len(s.buf)
+	return s
+}
+
+// Seal seals the current segment, making its records visible to readers,
+// and flushes it to disk if the log has a directory. It does nothing if
+// the current segment is empty. If the flush fails the segment stays
+// sealed in memory and the error is returned.
+func (l *Log) Seal() error {
+	s := l.cur
+	if s == nil || len(s.buf) == logHeaderSize {
+		return nil
+	}
+	l.cur = nil
+	l.sealed = append(l.sealed, s)
+	if l.dir == "" {
+		return nil
+	}
+	return l.flush(s)
+}
+
+// flush writes s to its segment file and returns its memory to the pool.
+func (l *Log) flush(s *logSegment) error {
+	path := logSegmentPath(l.dir, s.seq)
+	if err := writeFileDurable(path, s.buf); err != nil {
+		return err
+	}
+	s.path = path
+	s.buf = nil
+	l.pool.Put(s.a)
+	s.a = nil
+	return nil
+}
+
+// writeFileDurable writes data to path so that after a crash the file is
+// either absent or complete: it writes and syncs a temporary file, renames
+// it into place and syncs the directory to persist the rename.
+func writeFileDurable(path string, data []byte) error {
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	d, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	err = d.Sync()
+	if cerr := d.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Segments returns the sealed segments in order.
+func (l *Log) Segments() []LogSegment {
+	out := make([]LogSegment, len(l.sealed))
+	for i, s := range l.sealed {
+		out[i] = LogSegment{Seq: s.seq, Size: s.size, Path: s.path}
+	}
+	return out
+}
+
+// Discard drops the sealed segments numbered below seq, returning their
+// memory for reuse and removing their files. Records read from them must
+// no longer be used.
+func (l *Log) Discard(seq uint64) error {
+	var err error
+	kept := l.sealed[:0]
+	for _, s := range l.sealed {
+		if s.seq >= seq {
+			kept = append(kept, s)
+			continue
+		}
+		if s.a != nil {
+			s.buf = nil
+			l.pool.Put(s.a)
+			s.a = nil
+		}
+		if s.path != "" {
+			if rerr := os.Remove(s.path); rerr != nil && err == nil {
+				err = rerr
+			}
+		}
+	}
+	clear(l.sealed[len(kept):])
+	l.sealed = kept
+	return err
+}
+
+// Close seals the current segment and stops the log accepting records.
+// Segments that are only in memory are dropped; segment files are kept.
+func (l *Log) Close() error {
+	if l.closed {
+		return nil
+	}
+	err := l.Seal()
+	l.closed = true
+	for _, s := range l.sealed {
+		if s.a != nil {
+			s.buf = nil
+			l.pool.Put(s.a)
+			s.a = nil
+		}
+	}
+	l.sealed = nil
+	return err
+}
+
+// Reader returns a reader over the segments sealed so far. Segments sealed
+// later are not included.
+func (l *Log) Reader() *LogReader {
+	return &LogReader{segs: slices.Clone(l.sealed)}
+}
+
+// OpenLogReader returns a reader over the segment files in dir, in order,
+// for example to replay a log written before a restart.
+func OpenLogReader(dir string) (*LogReader, error) {
+	seqs, err := logDirSeqs(dir)
+	if err != nil {
+		return nil, err
+	}
+	segs := make([]*logSegment, len(seqs))
+	for i, seq := range seqs {
+		segs[i] = &logSegment{seq: seq, path: logSegmentPath(dir, seq)}
+	}
+	return &LogReader{segs: segs}, nil
+}
+
+// LogReader iterates the records of sealed log segments.
+type LogReader struct {
+	segs []*logSegment
+	data []byte // segment being read
+	pos  int
+	seq  uint64
+}
+
+// Next returns the next record, or io.EOF after the last one. Records in
+// memory alias the log's segments and are valid until the segment is
+// discarded; records loaded from disk are valid indefinitely. A damaged
+// segment returns an error wrapping ErrLogCorrupt.
+func (r *LogReader) Next() ([]byte, error) {
+	for r.pos >= len(r.data) {
+		if len(r.segs) == 0 {
+			return nil, io.EOF
+		}
+		if err := r.load(r.segs[0]); err != nil {
+			return nil, err
+		}
+		r.segs = r.segs[1:]
+	}
+	rest := r.data[r.pos:]
+	if len(rest) < logRecordHeaderSize {
+		return nil, fmt.Errorf("%w: segment %d: truncated record header", ErrLogCorrupt, r.seq)
+	}
+	n := int(binary.LittleEndian.Uint32(rest[0:4]))
+	if n > len(rest)-logRecordHeaderSize {
+		return nil, fmt.Errorf("%w: segment %d: truncated record", ErrLogCorrupt, r.seq)
+	}
+	rec := rest[logRecordHeaderSize : logRecordHeaderSize+n : logRecordHeaderSize+n]
+	if crc32.Checksum(rec, crcTable) != binary.LittleEndian.Uint32(rest[4:8]) {
+		return nil, fmt.Errorf("%w: segment %d: record checksum mismatch", ErrLogCorrupt, r.seq)
+	}
+	r.pos += logRecordHeaderSize + n
+	return rec, nil
+}
+
+// load makes s the segment being read, reading it from disk if it has
+// been flushed.
+func (r *LogReader) load(s *logSegment) error {
+	data := s.buf
+	if data == nil {
+		if s.path == "" {
+			return fmt.Errorf("arena: log segment %d was discarded", s.seq)
+		}
+		var err error
+		if data, err = os.ReadFile(s.path); err != nil {
+			return err
+		}
+	}
+	if len(data) < logHeaderSize || [8]byte(data[0:8]) != logMagic ||
+		binary.LittleEndian.Uint64(data[8:16]) != s.seq {
+		return fmt.Errorf("%w: segment %d: bad header", ErrLogCorrupt, s.seq)
+	}
+	r.data, r.pos, r.seq = data, logHeaderSize, s.seq
+	return nil
+}
+
+// logSegmentPath returns the file name of segment seq in dir.
+func logSegmentPath(dir string, seq uint64) string {
+	return filepath.Join(dir, fmt.Sprintf("%016x%s", seq, logFileExt))
+}
+
+// logDirSeqs returns the sequence numbers of the segment files in dir in
+// ascending order.
+func logDirSeqs(dir string) ([]uint64, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var seqs []uint64
+	for _, e := range entries {
+		name, ok := strings.CutSuffix(e.Name(), logFileExt)
+		if !ok || e.IsDir() {
+			continue
+		}
+		if seq, err := strconv.ParseUint(name, 16, 64); err == nil {
+			seqs = append(seqs, seq)
+		}
+	}
+	slices.Sort(seqs)
+	return seqs, nil
+}
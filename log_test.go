@@ -0,0 +1,136 @@
+package arena
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+// readAll returns the records of r as strings.
+func readAll(t *testing.T, r *LogReader) []string {
+	t.Helper()
+	var recs []string
+	for {
+		rec, err := r.Next()
+		if err == io.EOF {
+			return recs
+		}
+		if err != nil {
+			t.Fatalf("Next error = %v", err)
+		}
+		recs = append(recs, string(rec))
+	}
+}
+
+func TestLogInMemory(t *testing.T) {
+	l, err := NewLog(LogOptions{SegmentSize: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		if err := l.Append([]byte(fmt.Sprintf("record-%02d", i))); err != nil {
+			t.Fatalf("Append error = %v", err)
+		}
+	}
+	// 17 bytes per record, 6 per segment: one segment sealed by rotation.
+	if got := readAll(t, l.Reader()); len(got) != 6 || got[0] != "record-00" {
+		t.Errorf("records before Seal = %q, want the first 6", got)
+	}
+	l.Seal()
+	got := readAll(t, l.Reader())
+	if len(got) != 10 || got[9] != "record-09" {
+		t.Errorf("records after Seal = %q, want all 10", got)
+	}
+	segs := l.Segments()
+	if len(segs) != 2 || segs[0].Seq != 0 || segs[1].Seq != 1 || segs[0].Path != "" {
+		t.Errorf("Segments() = %+v", segs)
+	}
+
+	if err := l.Append(make([]byte, 128)); !errors.Is(err, ErrRecordTooLarge) {
+		t.Errorf("Append(oversized) error = %v, want ErrRecordTooLarge", err)
+	}
+
+	stale := l.Reader()
+	l.Discard(1)
+	if got := readAll(t, l.Reader()); len(got) != 4 || got[0] != "record-06" {
+		t.Errorf("records after Discard = %q, want the last 4", got)
+	}
+	if _, err := stale.Next(); err == nil {
+		t.Error("reader over a discarded segment returned no error")
+	}
+
+	l.Close()
+	if err := l.Append([]byte("x")); !errors.Is(err, ErrLogClosed) {
+		t.Errorf("Append after Close error = %v, want ErrLogClosed", err)
+	}
+}
+
+func TestLogSegmentFiles(t *testing.T) {
+	dir := t.TempDir()
+	l, err := NewLog(LogOptions{SegmentSize: 64, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 7; i++ {
+		l.Append([]byte(fmt.Sprintf("event %d", i)))
+	}
+	r := l.Reader() // taken while segments are being flushed
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close error = %v", err)
+	}
+	if got := readAll(t, r); len(got) != 6 {
+		t.Errorf("records from disk = %q, want 6", got)
+	}
+
+	// Replay after a restart, then keep appending to new segments.
+	r, err = OpenLogReader(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := readAll(t, r)
+	if len(got) != 7 || got[6] != "event 6" {
+		t.Errorf("replayed records = %q, want 7", got)
+	}
+	l, err = NewLog(LogOptions{SegmentSize: 64, Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Append([]byte("after restart"))
+	l.Seal()
+	segs := l.Segments()
+	if len(segs) != 1 || segs[0].Seq != 3 || segs[0].Path == "" {
+		t.Fatalf("Segments() after restart = %+v, want segment 3 on disk", segs)
+	}
+
+	data, _ := os.ReadFile(segs[0].Path)
+	data[len(data)-1] ^= 0xFF
+	os.WriteFile(segs[0].Path, data, 0o644)
+	if _, err := l.Reader().Next(); !errors.Is(err, ErrLogCorrupt) {
+		t.Errorf("Next on corrupt segment error = %v, want ErrLogCorrupt", err)
+	}
+	if err := l.Discard(4); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(segs[0].Path); !os.IsNotExist(err) {
+		t.Errorf("segment file still present after Discard: %v", err)
+	}
+}
+
+func TestWriteFileDurable(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/seg"
+	if err := writeFileDurable(path, []byte("data")); err != nil {
+		t.Fatalf("writeFileDurable error = %v", err)
+	}
+	if b, err := os.ReadFile(path); err != nil || string(b) != "data" {
+		t.Errorf("file = %q, %v, want data", b, err)
+	}
+	if _, err := os.Stat(path + ".tmp"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+	if err := writeFileDurable(dir+"/missing/seg", []byte("data")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("writeFileDurable(missing dir) error = %v, want not exist", err)
+	}
+}
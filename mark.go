@@ -0,0 +1,81 @@
+package arena
+
+// ArenaMark is an allocation position recorded by Snapshot.
+type ArenaMark struct {
+	chunk     int     // index of the current chunk; -1 if the arena had none
+	offset    uintptr // bump offset within it
+	gen       uint64
+	requested int // bytes requested by live allocations, for BytesWasted
+}
+
+// Snapshot returns a mark of the arena's current allocation position for
+// Restore. Marks nest: a phase can take its own mark, roll back to it, and
+// leave allocations made before an outer mark untouched.
+//
+//	m := a.Snapshot()
+//	parseHeaders(a)
+//	a.Restore(m) // drops everything parseHeaders allocated
+func (a *Arena) Snapshot() ArenaMark {
+	a.panicIfReleased()
+	m := ArenaMark{chunk: -1, gen: a.generation, requested: a.requested}
+	if c := a.currentChunk; c != nil {
+		m.chunk = a.chunkIndex(c)
+		m.offset = c.offset
+	}
+	return m
+}
+
+// Restore rolls the arena back to m, making the memory of every allocation
+// since the mark available again. Allocations made before the mark stay
+// valid. Chunks added since the mark are kept for reuse, as with Reset.
+//
+// Restore panics if the arena was reset since the mark, or if the mark lies
+// beyond the current position because an earlier mark was restored; a mark
+// discarded that way must not be used even once allocation has moved past
+// it again. Restore does not advance the
+// generation, so GenerationGuard users such as Pool, Vector and TypedArena
+// must not hold values allocated after the mark; the caches of InternValue
+// and Interners are cleared.
+// Regions pinned after the mark must be unpinned first.
+func (a *Arena) Restore(m ArenaMark) {
+	a.panicIfReleased()
+	if m.gen != a.generation {
+		a.panicWithEvents("arena: Restore with mark from before a reset")
+	}
+	cur := a.chunkIndex(a.currentChunk)
+	if m.chunk > cur || m.chunk == cur && m.chunk >= 0 && m.offset > a.currentChunk.offset {
+		a.panicWithEvents("arena: Restore with mark beyond the current position")
+	}
+	if a.debug != nil && a.debug.canaries {
+		a.verifyCanaries()
+	}
+	a.notePeak()
+	a.requested = m.requested
+	first := max(m.chunk, 0)
+	for i := first; i <= cur; i++ {
+		c := &a.chunks[i]
+		start := a.chunkBase
+		if i == m.chunk {
+			start = m.offset
+		} else if i == 0 && a.template != nil {
+			start += uintptr(len(a.template)) // marked before lazy init
+		}
+		if c.offset <= start {
+			continue
+		}
+		if a.debug != nil && a.debug.poison {
+			for j := range c.buf[start:c.offset] {
+				c.buf[int(start)+j] = PoisonByte
+			}
+		}
+		c.offset = start
+		c.zeroed = false
+	}
+	if m.chunk >= 0 {
+		a.currentChunk = &a.chunks[m.chunk]
+	} else if len(a.chunks) > 0 {
+		a.currentChunk = &a.chunks[0]
+	}
+	a.dropInterned()
+	a.recordEvent(OpRestore, 0, a.currentChunk, m.offset)
+}
@@ -0,0 +1,81 @@
+package arena
+
+import "testing"
+
+func TestSnapshotRestore(t *testing.T) {
+	a := NewArena(1024)
+	keep := a.AllocBytes(100)
+	copy(keep, "kept")
+	outer := a.Snapshot()
+	used := a.SizeInUse()
+
+	a.AllocBytes(200)
+	inner := a.Snapshot()
+	innerUsed := a.SizeInUse()
+	for i := 0; i < 10; i++ {
+		a.AllocBytes(500) // spills into new chunks
+	}
+	a.Restore(inner)
+	if got := a.SizeInUse(); got != innerUsed {
+		t.Errorf("SizeInUse after inner Restore = %d, want %d", got, innerUsed)
+	}
+	chunks := a.NumChunks()
+	a.Restore(outer)
+	if got := a.SizeInUse(); got != used {
+		t.Errorf("SizeInUse after outer Restore = %d, want %d", got, used)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic on Restore() with a discarded mark")
+			}
+		}()
+		a.Restore(inner)
+	}()
+	if string(keep[:4]) != "kept" {
+		t.Errorf("allocation before the mark = %q, want kept", keep[:4])
+	}
+
+	// Memory after the mark is handed out again; chunks are reused.
+	b := a.AllocBytes(8)
+	if &b[0] != &a.chunks[0].buf[alignPtr(100)+a.chunkBase] {
+		t.Error("allocation after Restore did not reuse the rolled back memory")
+	}
+	for i := 0; i < 10; i++ {
+		a.AllocBytes(500)
+	}
+	if a.NumChunks() != chunks {
+		t.Errorf("NumChunks = %d, want %d (chunks reused)", a.NumChunks(), chunks)
+	}
+
+	a.Reset()
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on Restore() with a mark from before Reset()")
+		}
+	}()
+	a.Restore(outer)
+}
+
+func TestSnapshotRestorePoison(t *testing.T) {
+	SetDebugLevel(DebugPoison)
+	a := NewArena(1024)
+	SetDebugLevel(DebugOff)
+
+	m := a.Snapshot()
+	b := a.AllocBytes(16)
+	a.Restore(m)
+	if b[0] != PoisonByte || b[15] != PoisonByte {
+		t.Errorf("rolled back memory = %#x, want poisoned", b[0])
+	}
+}
+
+func TestSnapshotRestoreLazy(t *testing.T) {
+	a := NewArenaFromTemplate([]byte("template"), 256, WithLazyInit())
+	m := a.Snapshot()
+	a.AllocBytes(100)
+	a.Restore(m)
+	if got := a.SizeInUse(); got != len("template") {
+		t.Errorf("SizeInUse = %d, want the template only", got)
+	}
+}
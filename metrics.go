@@ -1,5 +1,10 @@
 package arena
 
+import (
+	"strconv"
+	"time"
+)
+
 // SizeInUse returns the total number of bytes currently allocated in the arena.
 // This includes internal fragmentation due to alignment.
 func (a *Arena) SizeInUse() int {
@@ -13,6 +18,26 @@ func (a *Arena) SizeInUse() int {
 	return sum
 }
 
+// usedBytes returns the bytes handed out from chunks, excluding reserved
+// chunk headers.
+func (a *Arena) usedBytes(chunks []chunk) int {
+	sum := 0
+	for _, c := range chunks {
+		sum += int(c.offset - a.chunkBase)
+	}
+	return sum
+}
+
+// hugePageBytes returns the chunk capacity backed by memory advised for
+// huge pages.
+func (a *Arena) hugePageBytes() int {
+	m := a.chunkMaps()
+	if m == nil || !m.huge {
+		return 0
+	}
+	return m.hugeBytes(a.chunks)
+}
+
 // NumChunks returns the number of chunks currently allocated by the arena.
 func (a *Arena) NumChunks() int {
 	if a.chunks == nil {
@@ -50,13 +75,74 @@ func (a *Arena) ChunkSize() int {
 
 // Metrics returns a snapshot of arena statistics.
 func (a *Arena) Metrics() ArenaMetrics {
+	a.notePeak()
 	return ArenaMetrics{
-		SizeInUse:   a.SizeInUse(),
-		Capacity:    a.Capacity(),
-		NumChunks:   a.NumChunks(),
-		ChunkSize:   a.ChunkSize(),
-		Utilization: a.Utilization(),
+		SizeInUse:         a.SizeInUse(),
+		Capacity:          a.Capacity(),
+		NumChunks:         a.NumChunks(),
+		ChunkSize:         a.ChunkSize(),
+		Utilization:       a.Utilization(),
+		Growth:            a.GrowthPolicy(),
+		HugePageBytes:     a.hugePageBytes(),
+		NumAllocations:    int(a.allocs - a.cycleAllocs),
+		BytesWasted:       a.bytesWasted(),
+		PeakSizeInUse:     a.peak,
+		LargestAllocation: a.largest,
+		TotalAllocs:       a.allocs,
+		TotalBytes:        a.retiredBytes + uint64(a.usedBytes(a.chunks)),
+		Resets:            a.resets,
+		Grows:             a.grows,
+		Trims:             a.trims,
+		TrimmedBytes:      a.trimmedBytes,
+		Time:              time.Now(),
+	}
+}
+
+// countAlloc records an n-byte allocation. It runs on the fast path and
+// is kept within the inlining budget (guarded by TestBumpInlinable).
+func (a *Arena) countAlloc(n int) {
+	a.allocs++
+	a.requested += n
+	a.largest = max(a.largest, n)
+	if a.allocs == a.allocHookAt {
+		a.runAllocHook()
+	}
+}
+
+// notePeak folds the current usage into the high-water mark. Usage only
+// falls at resets and rollbacks, which call it first, so the mark is exact.
+func (a *Arena) notePeak() {
+	a.peak = max(a.peak, a.SizeInUse())
+}
+
+// endCycleMetrics starts the per-cycle counters over for a reset.
+func (a *Arena) endCycleMetrics() {
+	a.notePeak()
+	a.cycleAllocs = a.allocs
+	a.requested = 0
+}
+
+// bytesWasted returns the bytes of the current cycle's chunks that hold no
+// requested data: alignment padding and diagnostic headers between
+// allocations, plus the tails of chunks left behind because the next
+// allocation did not fit. Free space after the current position is not
+// waste; the Preload template is not counted.
+func (a *Arena) bytesWasted() int {
+	cur := a.chunkIndex(a.currentChunk)
+	if cur < 0 {
+		return 0
 	}
+	return max(a.usedBytes(a.chunks[:cur+1])-len(a.template)-a.requested, 0) + a.chunkTails()
+}
+
+// chunkTails returns the free bytes of the chunks before the current one,
+// which this cycle moved past.
+func (a *Arena) chunkTails() int {
+	tails := 0
+	for _, c := range a.chunks[:max(a.chunkIndex(a.currentChunk), 0)] {
+		tails += len(c.buf) - int(c.offset)
+	}
+	return tails
 }
 
 // ArenaMetrics contains statistical information about an arena.
@@ -66,48 +152,167 @@ type ArenaMetrics struct {
 	NumChunks   int     // Number of chunks
 	ChunkSize   int     // Default chunk size
 	Utilization float64 // Ratio of used to total capacity (0.0-1.0)
+	Growth      GrowthPolicy
+
+	// HugePageBytes is the part of Capacity backed by memory advised for
+	// transparent huge pages (see WithHugePages).
+	HugePageBytes int
+
+	// Allocation profile, for tuning the chunk size: a chunk size well
+	// above LargestAllocation and near PeakSizeInUse keeps cycles in one
+	// chunk, and a high BytesWasted relative to SizeInUse means chunk
+	// tails are being skipped.
+	NumAllocations    int // Allocations served since the last reset
+	BytesWasted       int // Padding and skipped chunk tails in the current cycle
+	PeakSizeInUse     int // Highest SizeInUse since the arena was created
+	LargestAllocation int // Largest single allocation in bytes since creation
+
+	// Cumulative counters since the arena was created, for DeltaSince.
+	TotalAllocs  uint64    // Allocations served
+	TotalBytes   uint64    // Bytes handed out, including alignment padding
+	Resets       uint64    // Completed resets (Reset or ResetCommit)
+	Grows        uint64    // Chunks added
+	Trims        uint64    // TrimCold, ShrinkTo and ResetAndShrink calls, including WithRetention resets
+	TrimmedBytes uint64    // Chunk bytes those trims freed
+	Time         time.Time // When the snapshot was taken
+}
+
+// MetricsDelta describes arena activity between two metrics snapshots, both
+// as absolute counts and as per-second rates.
+type MetricsDelta struct {
+	Interval       time.Duration // Time between the snapshots
+	Allocs         uint64        // Allocations served in the interval
+	Bytes          uint64        // Bytes handed out in the interval
+	Resets         uint64        // Resets completed in the interval
+	Grows          uint64        // Chunks added in the interval
+	CapacityChange int           // Change in capacity in bytes (may be negative)
+	AllocsPerSec   float64
+	BytesPerSec    float64
+	ResetsPerSec   float64
+	GrowsPerSec    float64
+}
+
+// DeltaSince returns the activity between an earlier snapshot prev and m,
+// so exporters and alerts can work with rates instead of absolute values.
+// Rates are zero if the snapshots were taken at the same instant. prev must
+// come from the same arena.
+func (m ArenaMetrics) DeltaSince(prev ArenaMetrics) MetricsDelta {
+	d := MetricsDelta{
+		Interval:       m.Time.Sub(prev.Time),
+		Allocs:         m.TotalAllocs - prev.TotalAllocs,
+		Bytes:          counterDelta(m.TotalBytes, prev.TotalBytes),
+		Resets:         m.Resets - prev.Resets,
+		Grows:          m.Grows - prev.Grows,
+		CapacityChange: m.Capacity - prev.Capacity,
+	}
+	if secs := d.Interval.Seconds(); secs > 0 {
+		d.AllocsPerSec = float64(d.Allocs) / secs
+		d.BytesPerSec = float64(d.Bytes) / secs
+		d.ResetsPerSec = float64(d.Resets) / secs
+		d.GrowsPerSec = float64(d.Grows) / secs
+	}
+	return d
+}
+
+// counterDelta returns cur - prev, or 0 if the counter went backwards (a
+// Scratch release gives bytes back).
+func counterDelta(cur, prev uint64) uint64 {
+	if cur < prev {
+		return 0
+	}
+	return cur - prev
+}
+
+// AppendText appends the metrics to dst as space-separated key=value pairs
+// and returns the extended buffer. It implements encoding.TextAppender and
+// performs no heap allocations when dst has enough capacity.
+func (m ArenaMetrics) AppendText(dst []byte) ([]byte, error) {
+	dst = append(dst, "size_in_use="...)
+	dst = strconv.AppendInt(dst, int64(m.SizeInUse), 10)
+	dst = append(dst, " capacity="...)
+	dst = strconv.AppendInt(dst, int64(m.Capacity), 10)
+	dst = append(dst, " num_chunks="...)
+	dst = strconv.AppendInt(dst, int64(m.NumChunks), 10)
+	dst = append(dst, " chunk_size="...)
+	dst = strconv.AppendInt(dst, int64(m.ChunkSize), 10)
+	dst = append(dst, " utilization="...)
+	dst = strconv.AppendFloat(dst, m.Utilization, 'f', 4, 64)
+	dst = append(dst, " huge_page_bytes="...)
+	dst = strconv.AppendInt(dst, int64(m.HugePageBytes), 10)
+	dst = append(dst, " num_allocations="...)
+	dst = strconv.AppendInt(dst, int64(m.NumAllocations), 10)
+	dst = append(dst, " bytes_wasted="...)
+	dst = strconv.AppendInt(dst, int64(m.BytesWasted), 10)
+	dst = append(dst, " peak_size_in_use="...)
+	dst = strconv.AppendInt(dst, int64(m.PeakSizeInUse), 10)
+	dst = append(dst, " largest_allocation="...)
+	dst = strconv.AppendInt(dst, int64(m.LargestAllocation), 10)
+	dst = append(dst, " total_allocs="...)
+	dst = strconv.AppendUint(dst, m.TotalAllocs, 10)
+	dst = append(dst, " total_bytes="...)
+	dst = strconv.AppendUint(dst, m.TotalBytes, 10)
+	dst = append(dst, " resets="...)
+	dst = strconv.AppendUint(dst, m.Resets, 10)
+	dst = append(dst, " grows="...)
+	dst = strconv.AppendUint(dst, m.Grows, 10)
+	dst = append(dst, " trims="...)
+	dst = strconv.AppendUint(dst, m.Trims, 10)
+	dst = append(dst, " trimmed_bytes="...)
+	dst = strconv.AppendUint(dst, m.TrimmedBytes, 10)
+	dst = append(dst, " growth_factor="...)
+	dst = strconv.AppendFloat(dst, m.Growth.Factor, 'g', -1, 64)
+	dst = append(dst, " growth_min_chunk_size="...)
+	dst = strconv.AppendInt(dst, int64(m.Growth.MinChunkSize), 10)
+	dst = append(dst, " growth_max_chunk_size="...)
+	dst = strconv.AppendInt(dst, int64(m.Growth.MaxChunkSize), 10)
+	dst = append(dst, " growth_next_chunk_size="...)
+	dst = strconv.AppendInt(dst, int64(m.Growth.NextChunkSize), 10)
+	dst = append(dst, " growth_custom="...)
+	dst = strconv.AppendBool(dst, m.Growth.Custom)
+	dst = append(dst, " time="...)
+	dst = m.Time.AppendFormat(dst, time.RFC3339Nano)
+	return dst, nil
+}
+
+// MarshalText implements encoding.TextMarshaler using AppendText.
+func (m ArenaMetrics) MarshalText() ([]byte, error) {
+	return m.AppendText(nil)
 }
 
-// Thread-safe metrics for SafeArena
+// Thread-safe metrics for SafeArena. They are read without taking the
+// lock (see safeStats), so monitoring never contends with allocation.
 
 // SizeInUse thread-safely returns the total number of bytes currently allocated.
 func (s *SafeArena) SizeInUse() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.a.SizeInUse()
+	return s.Metrics().SizeInUse
 }
 
 // NumChunks thread-safely returns the number of chunks currently allocated.
 func (s *SafeArena) NumChunks() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.a.NumChunks()
+	return s.stats.snap.Load().m.NumChunks
 }
 
 // Capacity thread-safely returns the total capacity of all chunks.
 func (s *SafeArena) Capacity() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.a.Capacity()
+	return s.stats.snap.Load().m.Capacity
 }
 
 // Utilization thread-safely returns the ratio of bytes in use to total capacity.
 func (s *SafeArena) Utilization() float64 {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.a.Utilization()
+	return s.Metrics().Utilization
 }
 
 // ChunkSize thread-safely returns the default chunk size.
 func (s *SafeArena) ChunkSize() int {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.a.ChunkSize()
+	return s.stats.snap.Load().m.ChunkSize
 }
 
-// Metrics thread-safely returns a snapshot of arena statistics.
+// Metrics thread-safely returns a snapshot of arena statistics without
+// taking the lock. The figures are consistent as of the last locked
+// operation; allocations served lock-free from the shards are added in as
+// they are read.
 func (s *SafeArena) Metrics() ArenaMetrics {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	return s.a.Metrics()
+	m := s.loadMetrics()
+	s.adjustShardMetrics(&m)
+	return m
 }
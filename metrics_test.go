@@ -1,7 +1,12 @@
 package arena
 
 import (
+	"encoding"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
+	"unicode"
 )
 
 func TestArenaMetrics(t *testing.T) {
@@ -151,6 +156,68 @@ func TestSafeArenaMetrics(t *testing.T) {
 	}
 }
 
+func TestSafeArenaMetricsLockFree(t *testing.T) {
+	for _, shards := range []int{0, 4} {
+		s := NewSafeArena(1024, WithShards(shards))
+		s.AllocBytes(3)
+		s.AllocBytes(100)
+		s.AllocBytes(2000) // new chunk
+		SafeAllocSlice[int64](s, 10)
+		s.Reset()
+		s.AllocBytes(900)
+		s.AllocBytes(900)
+
+		got := s.Metrics()
+		s.mu.Lock()
+		want := s.a.Metrics()
+		s.adjustShardMetrics(&want)
+		s.mu.Unlock()
+		got.Time, want.Time = time.Time{}, time.Time{}
+		if got != want {
+			t.Errorf("shards=%d: lock-free Metrics() =\n%+v\nwant\n%+v", shards, got, want)
+		}
+
+		// Readers do not wait for the lock.
+		s.mu.Lock()
+		done := make(chan ArenaMetrics)
+		go func() { done <- s.Metrics() }()
+		select {
+		case m := <-done:
+			if m.SizeInUse != got.SizeInUse || s.NumChunks() != got.NumChunks || s.Capacity() != got.Capacity {
+				t.Errorf("shards=%d: Metrics() while locked = %+v, want %+v", shards, m, got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("shards=%d: Metrics() blocked on the arena lock", shards)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func TestSafeArenaMetricsConcurrent(t *testing.T) {
+	s := NewSafeArena(4096, WithShards(0))
+	stop := make(chan struct{})
+	go func() {
+		defer close(stop)
+		for i := range 20000 {
+			s.AllocBytes(1 + i%300)
+			if i%500 == 499 {
+				s.Reset()
+			}
+		}
+	}()
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+		m := s.Metrics()
+		if m.SizeInUse > m.Capacity || m.SizeInUse < 0 || m.BytesWasted < 0 || m.PeakSizeInUse < m.SizeInUse {
+			t.Fatalf("inconsistent metrics %+v", m)
+		}
+	}
+}
+
 func TestUtilizationEdgeCases(t *testing.T) {
 	// Test with released arena
 	a := NewArena(1024)
@@ -238,3 +305,189 @@ func BenchmarkSafeArenaMetrics(b *testing.B) {
 		}
 	})
 }
+
+func TestArenaMetricsAppendText(t *testing.T) {
+	m := ArenaMetrics{
+		SizeInUse: 300, Capacity: 1024, NumChunks: 1, ChunkSize: 1024, Utilization: 0.29296875, HugePageBytes: 1024,
+		NumAllocations: 7, BytesWasted: 12, PeakSizeInUse: 900, LargestAllocation: 256,
+		TotalAllocs: 70, TotalBytes: 9000, Resets: 10, Grows: 2, Trims: 1, TrimmedBytes: 1024,
+		Growth: GrowthPolicy{Factor: 1.5, MinChunkSize: 512, MaxChunkSize: 4096, NextChunkSize: 1536},
+		Time:   time.Date(2024, 5, 1, 12, 0, 0, 500, time.UTC),
+	}
+
+	got, err := m.AppendText([]byte("arena: "))
+	if err != nil {
+		t.Fatalf("AppendText() error = %v", err)
+	}
+	want := "arena: size_in_use=300 capacity=1024 num_chunks=1 chunk_size=1024 utilization=0.2930 huge_page_bytes=1024" +
+		" num_allocations=7 bytes_wasted=12 peak_size_in_use=900 largest_allocation=256" +
+		" total_allocs=70 total_bytes=9000 resets=10 grows=2 trims=1 trimmed_bytes=1024" +
+		" growth_factor=1.5 growth_min_chunk_size=512 growth_max_chunk_size=4096 growth_next_chunk_size=1536" +
+		" growth_custom=false time=2024-05-01T12:00:00.0000005Z"
+	if string(got) != want {
+		t.Errorf("AppendText() = %q, want %q", got, want)
+	}
+
+	text, _ := m.MarshalText()
+	if string(text) != want[len("arena: "):] {
+		t.Errorf("MarshalText() = %q", text)
+	}
+
+	var _ encoding.TextAppender = m
+	buf := make([]byte, 0, 512)
+	allocs := testing.AllocsPerRun(100, func() {
+		buf, _ = m.AppendText(buf[:0])
+	})
+	if allocs != 0 {
+		t.Errorf("AppendText() allocs = %v, want 0", allocs)
+	}
+}
+
+// TestArenaMetricsAppendTextFields fails when a field of ArenaMetrics is
+// added without a key in AppendText. Keys are the snake_case field names,
+// prefixed by the enclosing field's for nested structs.
+func TestArenaMetricsAppendTextFields(t *testing.T) {
+	text, _ := ArenaMetrics{}.MarshalText()
+	keys := make(map[string]bool)
+	for _, kv := range strings.Fields(string(text)) {
+		k, _, _ := strings.Cut(kv, "=")
+		keys[k] = true
+	}
+
+	var check func(prefix string, typ reflect.Type)
+	check = func(prefix string, typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			f := typ.Field(i)
+			key := prefix + snakeCase(f.Name)
+			if f.Type.Kind() == reflect.Struct && f.Type != reflect.TypeFor[time.Time]() {
+				check(key+"_", f.Type)
+				continue
+			}
+			if !keys[key] {
+				t.Errorf("AppendText does not emit ArenaMetrics field %s (key %q)", f.Name, key)
+			}
+		}
+	}
+	check("", reflect.TypeFor[ArenaMetrics]())
+}
+
+// snakeCase converts a Go field name such as HugePageBytes to huge_page_bytes.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func TestMetricsDeltaSince(t *testing.T) {
+	a := NewArena(1024)
+	m1 := a.Metrics()
+
+	for i := 0; i < 10; i++ {
+		a.AllocBytes(16)
+	}
+	a.AllocBytes(2000) // grows
+	a.Reset()
+	a.AllocBytes(8)
+	m2 := a.Metrics()
+	m2.Time = m1.Time.Add(2 * time.Second)
+
+	d := m2.DeltaSince(m1)
+	if d.Interval != 2*time.Second {
+		t.Errorf("Interval = %v, want 2s", d.Interval)
+	}
+	if d.Allocs != 12 {
+		t.Errorf("Allocs = %d, want 12", d.Allocs)
+	}
+	if d.Bytes != 10*16+2000+8 {
+		t.Errorf("Bytes = %d, want %d", d.Bytes, 10*16+2000+8)
+	}
+	if d.Resets != 1 || d.Grows != 1 {
+		t.Errorf("Resets, Grows = %d, %d, want 1, 1", d.Resets, d.Grows)
+	}
+	if d.CapacityChange != m2.Capacity-m1.Capacity || d.CapacityChange <= 0 {
+		t.Errorf("CapacityChange = %d, want %d", d.CapacityChange, m2.Capacity-m1.Capacity)
+	}
+	if d.AllocsPerSec != 6 || d.ResetsPerSec != 0.5 || d.GrowsPerSec != 0.5 {
+		t.Errorf("rates = %v allocs/s %v resets/s %v grows/s, want 6, 0.5, 0.5",
+			d.AllocsPerSec, d.ResetsPerSec, d.GrowsPerSec)
+	}
+	if d.BytesPerSec != float64(d.Bytes)/2 {
+		t.Errorf("BytesPerSec = %v, want %v", d.BytesPerSec, float64(d.Bytes)/2)
+	}
+
+	if z := m2.DeltaSince(m2); z.AllocsPerSec != 0 || z.Allocs != 0 {
+		t.Errorf("DeltaSince(self) = %+v, want zero activity", z)
+	}
+}
+
+func TestMetricsDeltaScratchRelease(t *testing.T) {
+	a := NewArena(1024)
+	_, done := a.Scratch(100)
+	m1 := a.Metrics()
+	done()
+	if d := a.Metrics().DeltaSince(m1); d.Bytes != 0 {
+		t.Errorf("Bytes after Scratch release = %d, want 0", d.Bytes)
+	}
+}
+
+func TestArenaMetricsAllocationProfile(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(3)
+	a.AllocBytes(5)    // 5 bytes of padding before it
+	a.AllocBytes(1010) // skips the 1011-byte tail of the first chunk
+	m := a.Metrics()
+	if m.NumAllocations != 3 || m.LargestAllocation != 1010 {
+		t.Errorf("NumAllocations, LargestAllocation = %d, %d, want 3, 1010", m.NumAllocations, m.LargestAllocation)
+	}
+	if m.BytesWasted != 5+1011 {
+		t.Errorf("BytesWasted = %d, want %d", m.BytesWasted, 5+1011)
+	}
+	if m.PeakSizeInUse != 1023 || m.SizeInUse != 1023 {
+		t.Errorf("PeakSizeInUse, SizeInUse = %d, %d, want 1023, 1023", m.PeakSizeInUse, m.SizeInUse)
+	}
+
+	// Rolled-back and released memory leaves the high-water mark alone
+	// and takes its padding with it.
+	mark := a.Snapshot()
+	a.AllocBytes(1)
+	a.AllocBytes(1)
+	high := a.SizeInUse()
+	a.Restore(mark)
+	_, done := a.Scratch(1)
+	done()
+	if m := a.Metrics(); m.BytesWasted != 5+1011 || m.PeakSizeInUse != high || m.NumAllocations != 6 {
+		t.Errorf("after rollback: BytesWasted, PeakSizeInUse, NumAllocations = %d, %d, %d, want %d, %d, 6",
+			m.BytesWasted, m.PeakSizeInUse, m.NumAllocations, 5+1011, high)
+	}
+
+	a.Reset()
+	a.AllocBytes(10)
+	m = a.Metrics()
+	if m.NumAllocations != 1 || m.BytesWasted != 0 {
+		t.Errorf("after Reset: NumAllocations, BytesWasted = %d, %d, want 1, 0", m.NumAllocations, m.BytesWasted)
+	}
+	if m.PeakSizeInUse != high || m.LargestAllocation != 1010 {
+		t.Errorf("after Reset: PeakSizeInUse, LargestAllocation = %d, %d, want %d, 1010", m.PeakSizeInUse, m.LargestAllocation, high)
+	}
+}
+
+func TestArenaMetricsAlignedWaste(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(1)
+	b := a.AllocBytesAligned(64, 64)
+	m := a.Metrics()
+	if want := a.SizeInUse() - 65; m.BytesWasted != want {
+		t.Errorf("BytesWasted = %d, want %d", m.BytesWasted, want)
+	}
+	if len(b) != 64 || m.LargestAllocation != 64 {
+		t.Errorf("LargestAllocation = %d, want 64", m.LargestAllocation)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package arena
+
+// mlockRegion reports that this platform cannot lock memory.
+func mlockRegion(region []byte) (func() error, error) {
+	return nil, ErrPinUnsupported
+}
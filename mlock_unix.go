@@ -0,0 +1,13 @@
+//go:build linux || darwin
+
+package arena
+
+import "syscall"
+
+// mlockRegion locks region into RAM.
+func mlockRegion(region []byte) (func() error, error) {
+	if err := syscall.Mlock(region); err != nil {
+		return nil, err
+	}
+	return func() error { return syscall.Munlock(region) }, nil
+}
@@ -0,0 +1,182 @@
+package arena
+
+import (
+	"math"
+	"unsafe"
+)
+
+// Option configures an Arena at construction time.
+type Option func(*Arena)
+
+// WithGrowthFactor makes each new chunk factor times larger than the one
+// before it, so arenas that outgrow their initial chunk size need fewer
+// chunks. Sizes stay within WithMinChunkSize/WithMaxChunkSize bounds.
+// A factor <= 1 keeps every chunk at the configured chunk size.
+func WithGrowthFactor(factor float64) Option {
+	return func(a *Arena) {
+		a.growth = factor
+	}
+}
+
+// WithMinChunkSize sets the smallest chunk the arena will add on its own.
+func WithMinChunkSize(n int) Option {
+	return func(a *Arena) {
+		a.minChunkSize = n
+	}
+}
+
+// WithMaxChunkSize caps the size of chunks the arena adds on its own,
+// bounding the worst-case single chunk allocation. Allocations larger than
+// the cap still receive a dedicated chunk of their exact size.
+func WithMaxChunkSize(n int) Option {
+	return func(a *Arena) {
+		a.maxChunkSize = n
+	}
+}
+
+// WithMaxAllocSize caps the size of a single allocation at n bytes:
+// larger requests panic, and AllocSliceE returns an error wrapping
+// ErrAllocTooLarge instead. It guards against runaway sizes computed from
+// untrusted input. The cap applies to the bytes requested, except that
+// AllocBytesAligned may request up to align-1 bytes of slack. n <= 0
+// means no limit.
+func WithMaxAllocSize(n int) Option {
+	return func(a *Arena) {
+		a.maxAlloc = n
+	}
+}
+
+// GrowthFunc computes the size of the next chunk an arena adds on its own
+// from the size of the previous one and the number of chunks the arena
+// holds. Results are bounded by WithMinChunkSize/WithMaxChunkSize; a result
+// <= 0 falls back to the arena's chunk size.
+type GrowthFunc func(prev, numChunks int) int
+
+// WithGrowthFunc sizes new chunks with fn, for policies a growth factor
+// cannot express such as stepped or workload-driven sizes. It takes
+// precedence over WithGrowthFactor.
+func WithGrowthFunc(fn GrowthFunc) Option {
+	return func(a *Arena) {
+		a.growthFunc = fn
+	}
+}
+
+// Options is the struct form of the chunk sizing options, for callers
+// that assemble a configuration as data rather than as a list of Options.
+// Zero fields keep the defaults.
+type Options struct {
+	ChunkSize    int        // Size of the first chunk; DefaultChunkSize if <= 0
+	GrowthFactor float64    // See WithGrowthFactor
+	MinChunkSize int        // See WithMinChunkSize
+	MaxChunkSize int        // See WithMaxChunkSize
+	GrowthFunc   GrowthFunc // See WithGrowthFunc
+}
+
+// NewArenaWithOptions creates an Arena configured by o. Any further opts
+// are applied after o.
+func NewArenaWithOptions(o Options, opts ...Option) *Arena {
+	all := make([]Option, 0, 4+len(opts))
+	if o.GrowthFactor != 0 {
+		all = append(all, WithGrowthFactor(o.GrowthFactor))
+	}
+	if o.MinChunkSize != 0 {
+		all = append(all, WithMinChunkSize(o.MinChunkSize))
+	}
+	if o.MaxChunkSize != 0 {
+		all = append(all, WithMaxChunkSize(o.MaxChunkSize))
+	}
+	if o.GrowthFunc != nil {
+		all = append(all, WithGrowthFunc(o.GrowthFunc))
+	}
+	return NewArena(o.ChunkSize, append(all, opts...)...)
+}
+
+// WithLazyInit defers allocating the first chunk until the first
+// allocation, so arenas created defensively (e.g. one per request) cost
+// nothing if they are never used. Until then the arena reports zero
+// capacity and zero chunks.
+func WithLazyInit() Option {
+	return func(a *Arena) {
+		a.lazy = true
+	}
+}
+
+// WithEmptySlices makes zero-length requests to AllocBytes, AllocSlice,
+// AllocSliceE and AllocSliceZeroed return an empty, non-nil slice instead
+// of nil, for callers whose consumers tell the two apart: encoding/json
+// writes an empty slice as [] but a nil one as null. The slice points into
+// the arena's first chunk and has zero capacity, so appending to it moves
+// to the heap rather than overwriting arena memory. Negative sizes still
+// return nil.
+func WithEmptySlices() Option {
+	return func(a *Arena) {
+		a.emptySlices = true
+	}
+}
+
+// emptySlice returns the result of allocating n <= 0 elements: nil, or
+// with WithEmptySlices an empty slice for n == 0. It allocates nothing.
+func emptySlice[T any](a *Arena, n int) []T {
+	if n < 0 || !a.emptySlices {
+		return nil
+	}
+	if len(a.chunks) == 0 { // lazily initialized or released
+		return unsafe.Slice((*T)(unsafe.Pointer(&emptyBase)), 0)
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(unsafe.SliceData(a.chunks[0].buf))), 0)
+}
+
+// emptyBase backs empty slices of arenas that have no chunk.
+var emptyBase uint64
+
+// GrowthPolicy is the effective chunk sizing policy of an arena.
+type GrowthPolicy struct {
+	Factor        float64 // Chunk size multiplier (<= 1 means fixed-size chunks)
+	MinChunkSize  int     // Lower bound for chunk sizes (0 for none)
+	MaxChunkSize  int     // Upper bound for chunk sizes (0 for none)
+	NextChunkSize int     // Size of the next chunk the arena will add
+	Custom        bool    // Chunk sizes come from a GrowthFunc; Factor is unused
+}
+
+// GrowthPolicy returns the arena's effective chunk sizing policy.
+func (a *Arena) GrowthPolicy() GrowthPolicy {
+	return GrowthPolicy{
+		Factor:        a.growth,
+		MinChunkSize:  a.minChunkSize,
+		MaxChunkSize:  a.maxChunkSize,
+		NextChunkSize: a.nextChunk,
+		Custom:        a.growthFunc != nil,
+	}
+}
+
+// advanceChunkSize applies the growth function or factor to the next
+// chunk size.
+func (a *Arena) advanceChunkSize() {
+	if a.growthFunc != nil {
+		next := a.growthFunc(a.nextChunk, len(a.chunks)+1)
+		if next <= 0 {
+			next = a.chunkSize
+		}
+		a.nextChunk = a.clampChunkSize(next)
+		return
+	}
+	if a.growth <= 1 {
+		return
+	}
+	next := float64(a.nextChunk) * a.growth
+	if next > math.MaxInt/2 {
+		next = math.MaxInt / 2
+	}
+	a.nextChunk = a.clampChunkSize(int(next))
+}
+
+// clampChunkSize bounds n by the configured minimum and maximum chunk sizes.
+func (a *Arena) clampChunkSize(n int) int {
+	if a.maxChunkSize > 0 && n > a.maxChunkSize {
+		n = a.maxChunkSize
+	}
+	if a.minChunkSize > 0 && n < a.minChunkSize {
+		n = a.minChunkSize
+	}
+	return n
+}
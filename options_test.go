@@ -0,0 +1,180 @@
+package arena
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestWithGrowthFactor(t *testing.T) {
+	a := NewArena(1024, WithGrowthFactor(2), WithMaxChunkSize(4096))
+
+	want := []int{1024, 2048, 4096, 4096}
+	for len(a.chunks) < len(want) {
+		a.AllocBytes(1000)
+	}
+	for i, c := range a.chunks {
+		if len(c.buf) != want[i] {
+			t.Errorf("chunk %d size = %d, want %d", i, len(c.buf), want[i])
+		}
+	}
+
+	// Oversized allocations still get an exact chunk above the cap
+	a.AllocBytes(10000)
+	if got := len(a.chunks[len(a.chunks)-1].buf); got != 10000 {
+		t.Errorf("oversized chunk size = %d, want 10000", got)
+	}
+
+	p := a.Metrics().Growth
+	if p.Factor != 2 || p.MaxChunkSize != 4096 || p.NextChunkSize != 4096 {
+		t.Errorf("Metrics().Growth = %+v", p)
+	}
+}
+
+func TestChunkSizeBounds(t *testing.T) {
+	a := NewArena(100, WithMinChunkSize(512))
+	if a.Capacity() != 512 {
+		t.Errorf("Capacity with min bound = %d, want 512", a.Capacity())
+	}
+
+	a = NewArena(1<<20, WithMaxChunkSize(1<<16))
+	if a.Capacity() != 1<<16 {
+		t.Errorf("Capacity with max bound = %d, want %d", a.Capacity(), 1<<16)
+	}
+
+	// Without a growth factor chunk sizes stay fixed
+	a = NewArena(256)
+	a.AllocBytes(200)
+	a.AllocBytes(200)
+	if a.GrowthPolicy().NextChunkSize != 256 || a.Capacity() != 512 {
+		t.Errorf("fixed policy: next = %d, capacity = %d", a.GrowthPolicy().NextChunkSize, a.Capacity())
+	}
+}
+
+func TestWithLazyInit(t *testing.T) {
+	a := NewArena(1024, WithLazyInit())
+	if m := a.Metrics(); m.Capacity != 0 || m.NumChunks != 0 || m.SizeInUse != 0 {
+		t.Errorf("Metrics before first alloc = %+v, want zero capacity and chunks", m)
+	}
+	a.Reset() // no chunk needed
+	if a.NumChunks() != 0 {
+		t.Errorf("NumChunks after Reset() = %d, want 0", a.NumChunks())
+	}
+
+	p := Alloc[int64](a)
+	*p = 42
+	if a.NumChunks() != 1 || a.Capacity() != 1024 {
+		t.Errorf("NumChunks, Capacity after alloc = %d, %d, want 1, 1024", a.NumChunks(), a.Capacity())
+	}
+
+	a.Release()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic on use after Release()")
+		}
+	}()
+	a.AllocBytes(1)
+}
+
+func TestWithLazyInitPaths(t *testing.T) {
+	if got := NewArena(1024, WithLazyInit()).Grow(100); got != 1024 {
+		t.Errorf("Grow on lazy arena = %d, want 1024", got)
+	}
+	b := NewArena(1024, WithLazyInit())
+	b.Preload([]byte("seed"))
+	if string(b.Template()) != "seed" {
+		t.Errorf("Template() = %q, want %q", b.Template(), "seed")
+	}
+	c := NewArena(1024, WithLazyInit(), WithBackgroundZeroing())
+	c.Reset()
+	if c.NumChunks() != 0 {
+		t.Errorf("NumChunks after Reset() with background zeroing = %d, want 0", c.NumChunks())
+	}
+	if buf, done := c.Scratch(8); len(buf) != 8 {
+		t.Errorf("len(Scratch(8)) = %d, want 8", len(buf))
+	} else {
+		done()
+	}
+	d := NewArena(1024, WithLazyInit())
+	if _, meta := d.AllocBytesMeta(16); meta.Chunk != 0 {
+		t.Errorf("AllocBytesMeta chunk = %d, want 0", meta.Chunk)
+	}
+}
+
+func TestNewArenaWithOptions(t *testing.T) {
+	a := NewArenaWithOptions(Options{ChunkSize: 1024, GrowthFactor: 2, MaxChunkSize: 4096})
+	for len(a.chunks) < 4 {
+		a.AllocBytes(1000)
+	}
+	for i, want := range []int{1024, 2048, 4096, 4096} {
+		if got := len(a.chunks[i].buf); got != want {
+			t.Errorf("chunk %d size = %d, want %d", i, got, want)
+		}
+	}
+
+	// Custom policy: double every other chunk, capped by MaxChunkSize
+	var calls []int
+	a = NewArenaWithOptions(Options{
+		ChunkSize:    512,
+		MaxChunkSize: 2048,
+		GrowthFunc: func(prev, numChunks int) int {
+			calls = append(calls, numChunks)
+			if numChunks%2 == 0 {
+				return prev * 2
+			}
+			return prev
+		},
+	})
+	for len(a.chunks) < 6 {
+		a.AllocBytes(500)
+	}
+	for i, want := range []int{512, 512, 1024, 1024, 2048, 2048} {
+		if got := len(a.chunks[i].buf); got != want {
+			t.Errorf("custom chunk %d size = %d, want %d", i, got, want)
+		}
+	}
+	if len(calls) != 6 || calls[0] != 1 {
+		t.Errorf("GrowthFunc numChunks = %v, want 1..6", calls)
+	}
+	if p := a.GrowthPolicy(); !p.Custom || p.MaxChunkSize != 2048 {
+		t.Errorf("GrowthPolicy() = %+v, want custom with max 2048", p)
+	}
+
+	// Non-positive results fall back to the chunk size
+	a = NewArenaWithOptions(Options{ChunkSize: 256, GrowthFunc: func(int, int) int { return 0 }})
+	a.AllocBytes(200)
+	a.AllocBytes(200)
+	if got := len(a.chunks[1].buf); got != 256 {
+		t.Errorf("fallback chunk size = %d, want 256", got)
+	}
+}
+
+func TestWithEmptySlices(t *testing.T) {
+	plain := NewArena(1024)
+	if plain.AllocBytes(0) != nil || AllocSlice[int](plain, 0) != nil {
+		t.Error("zero-length allocations are non-nil without WithEmptySlices")
+	}
+
+	for _, a := range []*Arena{NewArena(1024, WithEmptySlices()), NewArena(1024, WithEmptySlices(), WithLazyInit())} {
+		b := a.AllocBytes(0)
+		s := AllocSliceZeroed[int64](a, 0)
+		e, err := AllocSliceE[string](a, 0)
+		if b == nil || s == nil || e == nil || err != nil {
+			t.Fatalf("zero-length allocations = %v, %v, %v, %v, want empty non-nil slices", b, s, e, err)
+		}
+		if len(b) != 0 || cap(b) != 0 {
+			t.Errorf("len, cap = %d, %d, want 0, 0", len(b), cap(b))
+		}
+		if j, _ := json.Marshal(s); string(j) != "[]" {
+			t.Errorf("empty slice encodes as %s, want []", j)
+		}
+		if a.AllocBytes(-1) != nil {
+			t.Error("AllocBytes(-1) != nil")
+		}
+		if a.SizeInUse() != 0 {
+			t.Errorf("SizeInUse = %d after zero-length allocations, want 0", a.SizeInUse())
+		}
+		if n := testing.AllocsPerRun(100, func() { a.AllocBytes(0) }); n != 0 {
+			t.Errorf("AllocBytes(0) made %v heap allocations, want 0", n)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package arena
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// inTransit is the debug owner of an arena whose ownership token has not
+// been redeemed yet: no goroutine may use it.
+const inTransit = math.MaxUint64
+
+// OwnershipToken hands an Arena from one goroutine to another. It is
+// created by TransferOwnership and redeemed exactly once with Adopt.
+type OwnershipToken struct {
+	a        *Arena
+	redeemed atomic.Bool
+}
+
+// TransferOwnership gives up the calling goroutine's ownership of a and
+// returns a token for the goroutine taking over, e.g. a parser handing its
+// results to a responder. Nothing is copied: the adopter receives the same
+// arena with all allocations intact. Send the token over a channel (or any
+// other synchronization) so the handoff is ordered.
+//
+// With diagnostics enabled (see SetDebugLevel), allocating from, resetting
+// or releasing the arena on any goroutine other than the adopter panics,
+// including the previous owner and including before Adopt.
+func (a *Arena) TransferOwnership() *OwnershipToken {
+	a.panicIfReleased()
+	if a.debug != nil {
+		a.checkOwner()
+		a.debug.owner = inTransit
+	}
+	return &OwnershipToken{a: a}
+}
+
+// Adopt redeems tok and returns the arena, now owned by the calling
+// goroutine. Panics if tok was already redeemed.
+func Adopt(tok *OwnershipToken) *Arena {
+	if !tok.redeemed.CompareAndSwap(false, true) {
+		panic("arena: ownership token already redeemed")
+	}
+	a := tok.a
+	if a.debug != nil {
+		a.debug.owner = goroutineID()
+	}
+	return a
+}
+
+// checkOwner panics if the arena was transferred and the calling goroutine
+// is not its adopter. Only called with diagnostics enabled.
+func (a *Arena) checkOwner() {
+	switch owner := a.debug.owner; owner {
+	case 0:
+		// Never transferred.
+	case inTransit:
+		a.panicWithEvents("arena: use after TransferOwnership() before Adopt()")
+	default:
+		if owner != goroutineID() {
+			a.panicWithEvents("arena: use by a goroutine that does not own the arena")
+		}
+	}
+}
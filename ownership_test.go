@@ -0,0 +1,61 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTransferOwnership(t *testing.T) {
+	a := NewArena(1024)
+	b := a.AllocBytes(5)
+	copy(b, "hello")
+
+	tok := a.TransferOwnership()
+	ch := make(chan *OwnershipToken, 1)
+	ch <- tok
+	done := make(chan string)
+	go func() {
+		got := Adopt(<-ch)
+		got.AllocBytes(10)
+		got.Reset()
+		done <- string(b)
+	}()
+	if s := <-done; s != "hello" {
+		t.Errorf("adopter saw %q, want %q", s, "hello")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic on second Adopt")
+		}
+	}()
+	Adopt(tok)
+}
+
+func TestTransferOwnershipDebugEnforcement(t *testing.T) {
+	defer SetDebugLevel(DebugOff)
+	SetDebugLevel(DebugEvents)
+	a := NewArena(1024)
+	a.AllocBytes(8)
+
+	tok := a.TransferOwnership()
+	if msg := panicMessage(func() { a.AllocBytes(8) }); !strings.Contains(msg, "before Adopt()") {
+		t.Errorf("allocation in transit: panic %q, want before Adopt()", msg)
+	}
+
+	adopted := make(chan struct{})
+	finish := make(chan struct{})
+	go func() {
+		Adopt(tok).AllocBytes(8)
+		close(adopted)
+		<-finish
+	}()
+	<-adopted
+	if msg := panicMessage(func() { a.AllocBytes(8) }); !strings.Contains(msg, "does not own the arena") {
+		t.Errorf("allocation by previous owner: panic %q, want ownership panic", msg)
+	}
+	if msg := panicMessage(a.Reset); !strings.Contains(msg, "does not own the arena") {
+		t.Errorf("Reset by previous owner: panic %q, want ownership panic", msg)
+	}
+	close(finish)
+}
@@ -0,0 +1,177 @@
+package arena
+
+import (
+	"errors"
+	"os"
+	"unsafe"
+)
+
+var (
+	// ErrNotInArena is returned by PinRegion for memory that was not
+	// allocated from the arena in its current cycle.
+	ErrNotInArena = errors.New("arena: region not allocated from this arena")
+	// ErrPinUnsupported is returned by the default pin function on
+	// platforms without mlock; use WithPinFunc there.
+	ErrPinUnsupported = errors.New("arena: memory locking not supported on this platform")
+	// ErrUnpinned is returned by Unpin if the region was already unpinned.
+	ErrUnpinned = errors.New("arena: region already unpinned")
+)
+
+// PinFunc pins region for device or kernel IO, for example by registering
+// it as an io_uring fixed buffer, and returns the function that undoes it.
+type PinFunc func(region []byte) (unpin func() error, err error)
+
+// WithPinFunc makes PinRegion pin regions with fn instead of mlock.
+func WithPinFunc(fn PinFunc) Option {
+	return func(a *Arena) {
+		a.pinFunc = fn
+	}
+}
+
+// Pin is a region pinned by PinRegion.
+type Pin struct {
+	p *pinState
+}
+
+type pinState struct {
+	a      *Arena
+	key    *byte // data pointer of the chunk holding the region
+	region []byte
+	unpin  func() error
+}
+
+// PinRegion pins the pages holding b, which must have been allocated from
+// the arena, so they can be handed to DMA or io_uring. The region is
+// widened to page boundaries within its chunk and locked with mlock, or
+// passed to the function set with WithPinFunc.
+//
+// Until every pin on a chunk is released with Unpin, Reset, ResetCommit
+// and Release set the chunk aside instead of recycling, poisoning or
+// clearing it: the pinned memory keeps its contents and is not handed out
+// again. Pin and Unpin are not goroutine-safe; synchronize them with other
+// uses of the arena.
+func (a *Arena) PinRegion(b []byte) (Pin, error) {
+	a.panicIfReleased()
+	if len(b) == 0 {
+		return Pin{}, ErrNotInArena
+	}
+	c := a.chunkHolding(b)
+	if c == nil {
+		return Pin{}, ErrNotInArena
+	}
+	region := pageRegion(c.buf, b)
+	pin := a.pinFunc
+	if pin == nil {
+		pin = mlockRegion
+	}
+	unpin, err := pin(region)
+	if err != nil {
+		return Pin{}, err
+	}
+	key := unsafe.SliceData(c.buf)
+	if a.pins == nil {
+		a.pins = make(map[*byte]int)
+	}
+	a.pins[key]++
+	return Pin{&pinState{a: a, key: key, region: region, unpin: unpin}}, nil
+}
+
+// Region returns the page-aligned region that was pinned.
+func (p Pin) Region() []byte {
+	if p.p == nil {
+		return nil
+	}
+	return p.p.region
+}
+
+// Unpin releases the pin. Once no pins remain on a chunk that a Reset set
+// aside, the chunk is recycled.
+func (p Pin) Unpin() error {
+	s := p.p
+	if s == nil || s.unpin == nil {
+		return ErrUnpinned
+	}
+	err := s.unpin()
+	s.unpin = nil
+	s.a.unpinChunk(s.key)
+	return err
+}
+
+// NumPins returns the number of regions currently pinned.
+func (a *Arena) NumPins() int {
+	n := 0
+	for _, count := range a.pins {
+		n += count
+	}
+	return n
+}
+
+// chunkHolding returns the live or retired chunk containing b, or nil.
+func (a *Arena) chunkHolding(b []byte) *chunk {
+	p := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	for _, set := range [][]chunk{a.chunks, a.retired} {
+		for i := range set {
+			c := &set[i]
+			base := uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+			if p >= base && p+uintptr(len(b)) <= base+c.offset {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// pageRegion widens b to page boundaries without leaving buf.
+func pageRegion(buf, b []byte) []byte {
+	page := uintptr(os.Getpagesize())
+	base := uintptr(unsafe.Pointer(unsafe.SliceData(buf)))
+	p := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	start := max(p&^(page-1), base) - base
+	end := min((p+uintptr(len(b))+page-1)&^(page-1), base+uintptr(len(buf))) - base
+	return buf[start:end:end]
+}
+
+// holdPinned moves the chunks of set that hold pinned regions to a.pinned
+// and returns the rest.
+func (a *Arena) holdPinned(set []chunk) []chunk {
+	kept := set[:0]
+	for _, c := range set {
+		if a.pins[unsafe.SliceData(c.buf)] > 0 {
+			a.pinned = append(a.pinned, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	clear(set[len(kept):])
+	return kept
+}
+
+// holdPinnedChunks is the Reset side of holdPinned: the arena continues
+// with the unpinned chunks, growing a fresh one if all were pinned.
+func (a *Arena) holdPinnedChunks() {
+	a.chunks = a.holdPinned(a.chunks)
+	a.currentChunk = nil
+	if len(a.chunks) == 0 && len(a.pinned) > 0 {
+		a.grow(max(a.chunkSize, len(a.template)))
+	}
+}
+
+// unpinChunk drops one pin on the chunk identified by key, recycling the
+// chunk if it was set aside and this was its last pin.
+func (a *Arena) unpinChunk(key *byte) {
+	if a.pins[key]--; a.pins[key] > 0 {
+		return
+	}
+	delete(a.pins, key)
+	for i, c := range a.pinned {
+		if unsafe.SliceData(c.buf) == key {
+			a.pinned = append(a.pinned[:i], a.pinned[i+1:]...)
+			if a.chunks != nil {
+				c.offset = a.chunkBase
+				c.zeroed = false
+				a.spare = append(a.spare, c)
+			}
+			return
+		}
+	}
+}
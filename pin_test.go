@@ -0,0 +1,81 @@
+package arena
+
+import (
+	"errors"
+	"os"
+	"testing"
+)
+
+func TestPinRegionHoldsChunkAcrossReset(t *testing.T) {
+	var pinned, unpinned int
+	a := NewArena(1024, WithPinFunc(func(region []byte) (func() error, error) {
+		pinned++
+		return func() error { unpinned++; return nil }, nil
+	}))
+	b := a.AllocBytes(64)
+	copy(b, "dma buffer")
+	p, err := a.PinRegion(b)
+	if err != nil {
+		t.Fatalf("PinRegion error = %v", err)
+	}
+	if pinned != 1 || a.NumPins() != 1 {
+		t.Fatalf("pin calls = %d, NumPins = %d, want 1 and 1", pinned, a.NumPins())
+	}
+	if r := p.Region(); len(r) == 0 || len(r) > 1024 {
+		t.Errorf("len(Region()) = %d, want within the chunk", len(r))
+	}
+
+	a.Reset()
+	for i := 0; i < 4; i++ {
+		clear(a.AllocBytes(512))
+	}
+	if string(b[:10]) != "dma buffer" {
+		t.Errorf("pinned memory reused after Reset: %q", b[:10])
+	}
+	if len(a.pinned) != 1 {
+		t.Fatalf("chunks set aside = %d, want 1", len(a.pinned))
+	}
+
+	if err := p.Unpin(); err != nil || unpinned != 1 {
+		t.Fatalf("Unpin error = %v, unpin calls = %d", err, unpinned)
+	}
+	if len(a.pinned) != 0 || len(a.spare) != 1 {
+		t.Errorf("after Unpin: %d set aside, %d spare, want 0 and 1", len(a.pinned), len(a.spare))
+	}
+	if err := p.Unpin(); !errors.Is(err, ErrUnpinned) {
+		t.Errorf("second Unpin error = %v, want ErrUnpinned", err)
+	}
+}
+
+func TestPinRegionRejectsForeignMemory(t *testing.T) {
+	a := NewArena(1024, WithPinFunc(func([]byte) (func() error, error) {
+		return func() error { return nil }, nil
+	}))
+	if _, err := a.PinRegion(make([]byte, 8)); !errors.Is(err, ErrNotInArena) {
+		t.Errorf("PinRegion(heap) error = %v, want ErrNotInArena", err)
+	}
+	b := a.AllocBytes(8)
+	a.Reset()
+	if _, err := a.PinRegion(b); !errors.Is(err, ErrNotInArena) {
+		t.Errorf("PinRegion(stale) error = %v, want ErrNotInArena", err)
+	}
+}
+
+func TestPinRegionMlock(t *testing.T) {
+	a := NewArena(4 * os.Getpagesize())
+	b := a.AllocBytes(100)
+	p, err := a.PinRegion(b)
+	if errors.Is(err, ErrPinUnsupported) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Skipf("mlock unavailable: %v", err)
+	}
+	if r := p.Region(); len(r) < len(b) {
+		t.Errorf("len(Region()) = %d, want at least %d", len(r), len(b))
+	}
+	a.Release()
+	if err := p.Unpin(); err != nil {
+		t.Errorf("Unpin after Release error = %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+package arena
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sync/atomic"
+)
+
+// PointerPolicy controls how Alloc and AllocSlice treat types that contain
+// Go pointers. Arena chunks are plain byte buffers the garbage collector
+// does not scan, so a heap pointer stored only in arena memory can be
+// collected while still referenced.
+type PointerPolicy int32
+
+const (
+	// PointerAllow performs no checking. This is the default.
+	PointerAllow PointerPolicy = iota
+	// PointerWarn logs once per pointer-containing type.
+	PointerWarn
+	// PointerForbid panics when a pointer-containing type is allocated.
+	PointerForbid
+)
+
+var pointerPolicy atomic.Int32
+
+// ErrPointerType is returned by AllocChecked for types that contain Go
+// pointers.
+var ErrPointerType = errors.New("arena: type contains pointers invisible to the GC")
+
+// SetPointerPolicy sets how generic allocation treats pointer-containing
+// types. Safe for concurrent use.
+func SetPointerPolicy(p PointerPolicy) {
+	pointerPolicy.Store(int32(p))
+}
+
+// HasPointers reports whether values of type T contain Go pointers
+// (pointers, strings, slices, maps, channels, funcs or interfaces).
+// The result is cached per type.
+func HasPointers[T any]() bool {
+	return typeEntryFor[T]().info.HasPointers
+}
+
+// AllocChecked is Alloc for code that wants the pointer check regardless of
+// the process-wide PointerPolicy: it returns an error wrapping
+// ErrPointerType instead of allocating if T contains Go pointers (see
+// HasPointers), since a heap value referenced only from arena memory can be
+// collected, and arena values it points to are recycled by Reset while the
+// pointer is still there. Types registered with AllowPointers are
+// accepted.
+func AllocChecked[T any](a *Arena) (*T, error) {
+	if e := typeEntryFor[T](); e.info.HasPointers && !e.info.AllowPointers {
+		return nil, fmt.Errorf("%w: %s", ErrPointerType, e.info.Name)
+	}
+	return Alloc[T](a), nil
+}
+
+// checkPointers applies the current PointerPolicy to type T.
+func checkPointers[T any]() {
+	policy := PointerPolicy(pointerPolicy.Load())
+	if policy == PointerAllow {
+		return
+	}
+	e := typeEntryFor[T]()
+	if !e.info.HasPointers || e.info.AllowPointers {
+		return
+	}
+	if policy == PointerForbid {
+		panic(fmt.Sprintf("arena: type %s contains pointers invisible to the GC", e.info.Name))
+	}
+	if e.warned.CompareAndSwap(false, true) {
+		log.Printf("arena: type %s contains pointers invisible to the GC", e.info.Name)
+	}
+}
+
+// typeHasPointers walks t's layout looking for pointer-shaped fields.
+func typeHasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.UnsafePointer, reflect.String, reflect.Slice,
+		reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+		return true
+	case reflect.Array:
+		return t.Len() > 0 && typeHasPointers(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if typeHasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package arena
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestHasPointers(t *testing.T) {
+	type flat struct {
+		a int64
+		b [4]uint8
+	}
+	type nested struct {
+		f    flat
+		name string
+	}
+
+	tests := []struct {
+		name string
+		got  bool
+		want bool
+	}{
+		{"int", HasPointers[int](), false},
+		{"flat struct", HasPointers[flat](), false},
+		{"empty array of pointers", HasPointers[[0]*int](), false},
+		{"pointer", HasPointers[*int](), true},
+		{"string", HasPointers[string](), true},
+		{"slice", HasPointers[[]byte](), true},
+		{"nested string", HasPointers[nested](), true},
+		{"array of maps", HasPointers[[2]map[int]int](), true},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("HasPointers[%s]() = %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestPointerPolicy(t *testing.T) {
+	defer SetPointerPolicy(PointerAllow)
+	a := NewArena(1024)
+
+	// Allow: no checks
+	Alloc[*int](a)
+
+	// Warn: logs once per type
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+	SetPointerPolicy(PointerWarn)
+	type warned struct{ p *int }
+	forgetType[warned](t) // the warning is once per registry entry
+	Alloc[warned](a)
+	AllocSlice[warned](a, 2)
+	if n := strings.Count(buf.String(), "contains pointers"); n != 1 {
+		t.Errorf("warnings logged = %d, want 1:\n%s", n, buf.String())
+	}
+
+	// Forbid: pointer-free types still allowed
+	SetPointerPolicy(PointerForbid)
+	Alloc[int64](a)
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic allocating pointer type under PointerForbid")
+		}
+	}()
+	AllocSlice[string](a, 1)
+}
+
+func TestAllocChecked(t *testing.T) {
+	a := NewArena(1024)
+	defer a.Release()
+
+	type flat struct{ x, y int64 }
+	p, err := AllocChecked[flat](a)
+	if err != nil || p == nil || *p != (flat{}) {
+		t.Fatalf("AllocChecked[flat] = %v, %v; want a zeroed value", p, err)
+	}
+
+	type withString struct{ name string }
+	if _, err := AllocChecked[withString](a); !errors.Is(err, ErrPointerType) || !strings.Contains(err.Error(), "withString") {
+		t.Errorf("AllocChecked[withString] error = %v, want ErrPointerType naming the type", err)
+	}
+
+	type sameArena struct{ next *sameArena }
+	Register[sameArena](AllowPointers())
+	if _, err := AllocChecked[sameArena](a); err != nil {
+		t.Errorf("AllocChecked of a type registered with AllowPointers: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package arena
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// WithPoisoning enables the checks that catch the classic arena bug of
+// keeping a slice across Reset. Reset and Release fill the memory they
+// discard with PoisonByte, as under DebugPoison, and when an allocation
+// later reuses poisoned memory it must still hold the poison: otherwise
+// something wrote through a stale reference after the Reset, and the
+// allocation panics naming the allocation the stale reference came from.
+// Every allocation is also followed by canary bytes, checked on the next
+// allocation and on Reset, and a panic for an overwritten canary names
+// the allocation that overflowed. The stack of every allocation is
+// recorded for these reports, so this mode is for tests and debugging.
+//
+// Generation stamps (see Validate) are poisoned along with the rest, so
+// Validate no longer names the generation of stale pointers. Poisoned
+// memory is not checked under WithBackgroundZeroing, which clears it.
+func WithPoisoning() Option {
+	return func(a *Arena) {
+		d := a.debugState()
+		d.poison = true
+		d.canaries = true
+		d.poisonCheck = true
+	}
+}
+
+// allocSite is an allocation recorded by WithPoisoning.
+type allocSite struct {
+	start, end uintptr // addresses spanned, including stamp and canary
+	pcs        []uintptr
+}
+
+// recordSite records the allocation b with the stack from the function
+// that called allocBytesSlow, and returns its index.
+func (d *debugState) recordSite(b []byte) int32 {
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	pcs := make([]uintptr, leakStackDepth)
+	n := runtime.Callers(4, pcs)
+	d.sites = append(d.sites, allocSite{start: start, end: start + uintptr(len(b)), pcs: pcs[:n]})
+	return int32(len(d.sites) - 1)
+}
+
+// rotateSites keeps the allocations of the cycle a reset ends, to name
+// them in use-after-reset reports of the next cycle.
+func (d *debugState) rotateSites() {
+	d.prevSites, d.sites = d.sites, d.prevSites[:0]
+}
+
+// siteAt returns the recorded allocation spanning addr, preferring the
+// most recent one.
+func (d *debugState) siteAt(addr uintptr) (allocSite, bool) {
+	for _, sites := range [][]allocSite{d.sites, d.prevSites} {
+		for i := len(sites) - 1; i >= 0; i-- {
+			if s := sites[i]; addr >= s.start && addr < s.end {
+				return s, true
+			}
+		}
+	}
+	return allocSite{}, false
+}
+
+// checkPoisoned panics if the part of [off, off+n) in c that was poisoned
+// by a reset no longer holds the poison.
+func (a *Arena) checkPoisoned(c *chunk, off uintptr, n int) {
+	if a.zeroer != nil || off >= c.poisoned {
+		return
+	}
+	end := min(off+uintptr(n), c.poisoned)
+	for p := off; p < end; p++ {
+		if c.buf[p] == PoisonByte {
+			continue
+		}
+		msg := fmt.Sprintf("arena: memory written after Reset (use after reset) at chunk %d offset %d",
+			a.chunkIndex(c), p)
+		if s, ok := a.debug.siteAt(uintptr(unsafe.Pointer(&c.buf[p]))); ok {
+			msg += "; the stale reference was allocated at:\n" + formatStack(s.pcs)
+		}
+		a.panicWithEvents(msg)
+	}
+}
+
+// canaryPanic panics for an overwritten canary, naming the allocation it
+// guards if its stack was recorded.
+func (a *Arena) canaryPanic(i int) {
+	msg := "arena: canary corrupted, write past end of allocation"
+	if d := a.debug; i < len(d.guardSites) {
+		msg += "; the allocation was made at:\n" + formatStack(d.sites[d.guardSites[i]].pcs)
+	}
+	a.panicWithEvents(msg)
+}
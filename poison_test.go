@@ -0,0 +1,88 @@
+package arena
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// panicMessage runs fn and returns the message it panicked with, or "".
+func panicMessage(fn func()) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	fn()
+	return ""
+}
+
+//go:noinline
+func keepAcrossReset(a *Arena) []byte {
+	return a.AllocBytes(64)
+}
+
+func TestPoisoningUseAfterReset(t *testing.T) {
+	a := NewArena(1024, WithPoisoning())
+	defer a.Release()
+	stale := keepAcrossReset(a)
+	a.Reset()
+	for i, v := range stale {
+		if v != PoisonByte {
+			t.Fatalf("stale[%d] = %#x after Reset, want %#x", i, v, PoisonByte)
+		}
+	}
+
+	stale[10] = 1 // write through a slice kept across Reset
+	msg := panicMessage(func() { a.AllocBytes(64) })
+	if !strings.Contains(msg, "use after reset") || !strings.Contains(msg, "keepAcrossReset") {
+		t.Errorf("panic = %q, want a use-after-reset report naming keepAcrossReset", msg)
+	}
+}
+
+//go:noinline
+func overflowingAlloc(a *Arena) []byte {
+	return a.AllocBytes(16)
+}
+
+func TestPoisoningCanary(t *testing.T) {
+	a := NewArena(1024, WithPoisoning())
+	defer a.Release()
+	b := overflowingAlloc(a)
+	past := unsafe.Slice(&b[0], 20)
+	past[17] = 'x' // write past the end
+
+	msg := panicMessage(func() { a.AllocBytes(8) })
+	if !strings.Contains(msg, "canary corrupted") || !strings.Contains(msg, "overflowingAlloc") {
+		t.Errorf("panic = %q, want a canary report naming overflowingAlloc", msg)
+	}
+	past[17] = canaryByte // let Release pass
+}
+
+func TestPoisoningCleanUse(t *testing.T) {
+	for _, level := range []DebugLevel{DebugOff, DebugFull} {
+		SetDebugLevel(level)
+		a := NewArena(512, WithPoisoning())
+		SetDebugLevel(DebugOff)
+
+		for cycle := 0; cycle < 4; cycle++ {
+			for i := 0; i < 50; i++ {
+				copy(a.AllocBytes(1+(i*cycle)%90), "data")
+				*Alloc[int64](a) = int64(i)
+			}
+			m := a.Snapshot()
+			a.AllocBytes(100)
+			a.Restore(m)
+			a.AllocBytes(100)
+			if cycle%2 == 0 {
+				a.Reset()
+			} else {
+				a.ResetPrepare()
+				a.AllocBytes(40)
+				a.ResetCommit()
+			}
+		}
+		a.Release()
+	}
+}
@@ -0,0 +1,63 @@
+package arena
+
+import "unsafe"
+
+// Pool is a free list of T values allocated from an arena. Get reuses a
+// value returned with Free if one is available and otherwise allocates a
+// new one, so workloads where objects churn quickly stay within a fixed
+// footprint without a full Reset. After the arena is reset the free list
+// is dropped, since its values were reclaimed with the rest of the arena.
+// A Pool is not goroutine-safe.
+type Pool[T any] struct {
+	a     *Arena
+	guard GenerationGuard
+	free  []*T
+}
+
+// NewPool returns a Pool allocating from a.
+func NewPool[T any](a *Arena) *Pool[T] {
+	return &Pool[T]{a: a, guard: a.Guard()}
+}
+
+// Get returns a zeroed *T, reusing a freed value if possible.
+func (p *Pool[T]) Get() *T {
+	p.sync()
+	if n := len(p.free); n > 0 {
+		v := p.free[n-1]
+		p.free[n-1] = nil
+		p.free = p.free[:n-1]
+		var zero T
+		*v = zero
+		return v
+	}
+	return Alloc[T](p.a)
+}
+
+// Free returns v, which must have come from Get, to the free list. v must
+// not be used afterwards. Under DebugPoison the value is overwritten with
+// PoisonByte so use after Free is easy to spot.
+func (p *Pool[T]) Free(v *T) {
+	p.sync()
+	if p.a.debug != nil && p.a.debug.poison {
+		b := unsafe.Slice((*byte)(unsafe.Pointer(v)), unsafe.Sizeof(*v))
+		for i := range b {
+			b[i] = PoisonByte
+		}
+	}
+	p.free = append(p.free, v)
+}
+
+// Available returns the number of freed values waiting to be reused.
+func (p *Pool[T]) Available() int {
+	p.sync()
+	return len(p.free)
+}
+
+// sync drops the free list once the arena has been reset.
+func (p *Pool[T]) sync() {
+	if !p.guard.Valid() {
+		clear(p.free)
+		p.free = p.free[:0]
+		p.guard = p.a.Guard()
+	}
+}
@@ -0,0 +1,56 @@
+package arena
+
+import "testing"
+
+type poolNode struct {
+	id   int
+	next *poolNode
+}
+
+func TestPoolReuse(t *testing.T) {
+	a := NewArena(1024)
+	p := NewPool[poolNode](a)
+
+	x := p.Get()
+	x.id = 7
+	p.Free(x)
+	if p.Available() != 1 {
+		t.Fatalf("Available = %d, want 1", p.Available())
+	}
+	used := a.SizeInUse()
+	y := p.Get()
+	if y != x || y.id != 0 {
+		t.Errorf("Get after Free = %p (id %d), want reused zeroed %p", y, y.id, x)
+	}
+	if a.SizeInUse() != used {
+		t.Error("Get with a free value allocated from the arena")
+	}
+
+	// Churn stays within the footprint of the live set
+	p.Free(y)
+	for i := 0; i < 1000; i++ {
+		v := p.Get()
+		p.Free(v)
+	}
+	if a.SizeInUse() != used {
+		t.Errorf("SizeInUse after churn = %d, want %d", a.SizeInUse(), used)
+	}
+
+	a.Reset()
+	if p.Available() != 0 {
+		t.Errorf("Available after Reset = %d, want 0", p.Available())
+	}
+}
+
+func TestPoolPoison(t *testing.T) {
+	SetDebugLevel(DebugPoison)
+	a := NewArena(1024)
+	SetDebugLevel(DebugOff)
+
+	p := NewPool[int64](a)
+	v := p.Get()
+	p.Free(v)
+	if want := int64(-0x2424242424242425); *v != want {
+		t.Errorf("freed value = %#x, want poisoned %#x", *v, want)
+	}
+}
@@ -0,0 +1,132 @@
+package arena
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// maxPoolPrewarm bounds the arenas Restore creates per bucket, however
+// busy the recorded state claims the pool was.
+const maxPoolPrewarm = 256
+
+// ErrPoolState is returned by ArenaPool.Restore for a state recorded from
+// a pool with a different configuration, or one that is malformed.
+var ErrPoolState = errors.New("arena: pool state does not match the pool")
+
+// PoolState is the tuning an ArenaPool has learned from its traffic: for
+// each bucket, how many arenas were out at once and how much the busiest
+// of them held. New arenas are pre-sized from it, and Restore carries it
+// over to the next process, so an autoscaled instance starts with warm,
+// correctly sized arenas instead of learning during its first spike. It
+// is meant to be stored with encoding/json:
+//
+//	// at shutdown
+//	json.NewEncoder(f).Encode(pool.State())
+//
+//	// at startup
+//	var st arena.PoolState
+//	if json.NewDecoder(f).Decode(&st) == nil {
+//		err = pool.Restore(st)
+//	}
+type PoolState struct {
+	ChunkSize int               `json:"chunk_size"` // Chunk size the pool was created with
+	Buckets   []PoolBucketState `json:"buckets"`    // Buckets that have been used
+}
+
+// PoolBucketState is the learned tuning of one ArenaPool bucket.
+type PoolBucketState struct {
+	ChunkSize int `json:"chunk_size"`  // Chunk size of the bucket's arenas
+	PeakInUse int `json:"peak_in_use"` // Most arenas taken from the bucket at once
+	PeakUsed  int `json:"peak_used"`   // Most bytes an arena held when put back
+}
+
+// poolStats tracks the usage of one ArenaPool bucket.
+type poolStats struct {
+	out      atomic.Int64 // arenas taken and not yet put back
+	peakOut  atomic.Int64
+	peakUsed atomic.Int64
+}
+
+// take counts an arena taken from the bucket.
+func (st *poolStats) take() {
+	storeMax(&st.peakOut, st.out.Add(1))
+}
+
+// give counts an arena put back holding used bytes.
+func (st *poolStats) give(used int) {
+	st.out.Add(-1)
+	storeMax(&st.peakUsed, int64(used))
+}
+
+// drop counts an arena taken from the bucket that will not be put back.
+func (st *poolStats) drop() {
+	st.out.Add(-1)
+}
+
+// warm sizes a new arena for the busiest cycle seen, so it runs in one
+// chunk from the start.
+func (st *poolStats) warm(a *Arena) *Arena {
+	if used := int(st.peakUsed.Load()); used > a.ChunkSize() {
+		a.EnsureCapacity(used)
+	}
+	return a
+}
+
+// storeMax raises v to n if n is larger.
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		old := v.Load()
+		if n <= old || v.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
+
+// State returns the tuning the pool has learned, for Restore.
+func (p *ArenaPool) State() PoolState {
+	st := PoolState{ChunkSize: p.chunkSize}
+	add := func(size int, s *poolStats) {
+		if out, used := s.peakOut.Load(), s.peakUsed.Load(); out > 0 || used > 0 {
+			st.Buckets = append(st.Buckets, PoolBucketState{ChunkSize: size, PeakInUse: int(out), PeakUsed: int(used)})
+		}
+	}
+	add(p.chunkSize, &p.base)
+	for class := range p.classStats {
+		add(1<<class, &p.classStats[class])
+	}
+	return st
+}
+
+// Restore adopts a state saved by State, typically from the previous run
+// of the process: learned sizes are merged with the pool's own, and each
+// bucket is pre-filled with as many arenas as were in use at its peak (at
+// most 256), sized for the busiest cycle. As with any sync.Pool, arenas
+// that stay unused are dropped by the garbage collector after a few
+// cycles. Returns an error wrapping ErrPoolState, without changing the
+// pool, if st was recorded with another chunk size or is malformed, or
+// one wrapping ErrChunkUnavailable if pre-filling ran out of budget.
+func (p *ArenaPool) Restore(st PoolState) error {
+	if st.ChunkSize != p.chunkSize {
+		return fmt.Errorf("%w: recorded with chunk size %d, pool uses %d", ErrPoolState, st.ChunkSize, p.chunkSize)
+	}
+	for _, b := range st.Buckets {
+		if pool, _ := p.bucket(b.ChunkSize); pool == nil || b.PeakInUse < 0 || b.PeakUsed < 0 {
+			return fmt.Errorf("%w: invalid bucket %+v", ErrPoolState, b)
+		}
+	}
+	for _, b := range st.Buckets {
+		pool, s := p.bucket(b.ChunkSize)
+		storeMax(&s.peakOut, int64(b.PeakInUse))
+		storeMax(&s.peakUsed, int64(b.PeakUsed))
+		for range min(b.PeakInUse, maxPoolPrewarm) {
+			a, err := NewArenaE(b.ChunkSize, p.opts...)
+			if err != nil {
+				return err
+			}
+			s.warm(a).disarmLeakCheck()
+			pool.Put(a)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package arena
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestArenaPoolState(t *testing.T) {
+	p := NewArenaPool(1024)
+	var held []*Arena
+	for range 3 {
+		held = append(held, p.Get())
+	}
+	held[0].AllocBytes(5000)
+	for _, a := range held {
+		p.Put(a)
+	}
+	big := p.GetSized(4000)
+	p.Put(big)
+
+	st := p.State()
+	if len(st.Buckets) != 2 {
+		t.Fatalf("State() = %+v, want the base and the 4096 bucket", st)
+	}
+	base := st.Buckets[0]
+	if base.ChunkSize != 1024 || base.PeakInUse != 3 || base.PeakUsed < 5000 {
+		t.Errorf("base bucket = %+v, want chunk size 1024, 3 in use, at least 5000 used", base)
+	}
+	if b := st.Buckets[1]; b.ChunkSize != 4096 || b.PeakInUse != 1 || b.PeakUsed != 0 {
+		t.Errorf("class bucket = %+v, want chunk size 4096, 1 in use, none used", b)
+	}
+
+	data, err := json.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored PoolState
+	if err := json.Unmarshal(data, &restored); err != nil {
+		t.Fatal(err)
+	}
+	q := NewArenaPool(1024)
+	if err := q.Restore(restored); err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	// Pre-filled or new, arenas come sized for the busiest cycle.
+	a := q.Get()
+	if a.AllocBytes(5000); a.NumChunks() > 2 || a.Capacity() < base.PeakUsed {
+		t.Errorf("restored arena has %d chunks and capacity %d, want room for %d bytes up front", a.NumChunks(), a.Capacity(), base.PeakUsed)
+	}
+	if got := q.State().Buckets[0]; got.PeakUsed != base.PeakUsed || got.PeakInUse != 3 {
+		t.Errorf("restored base bucket = %+v, want %+v", got, base)
+	}
+}
+
+func TestArenaPoolRestoreMismatch(t *testing.T) {
+	p := NewArenaPool(1024)
+	if err := p.Restore(PoolState{ChunkSize: 2048}); !errors.Is(err, ErrPoolState) {
+		t.Errorf("Restore with another chunk size = %v, want ErrPoolState", err)
+	}
+	bad := PoolState{ChunkSize: 1024, Buckets: []PoolBucketState{
+		{ChunkSize: 1024, PeakInUse: 2},
+		{ChunkSize: 3000, PeakInUse: 1},
+	}}
+	if err := p.Restore(bad); !errors.Is(err, ErrPoolState) {
+		t.Errorf("Restore with a bad bucket = %v, want ErrPoolState", err)
+	}
+	if st := p.State(); len(st.Buckets) != 0 {
+		t.Errorf("State after a rejected Restore = %+v, want it unchanged", st)
+	}
+}
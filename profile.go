@@ -0,0 +1,37 @@
+package arena
+
+// CompactChunkSize is the default chunk size (4 KiB) when the package is
+// built with the arena_compact build tag.
+const CompactChunkSize = 4 << 10
+
+// WithCompactProfile configures the arena for memory-constrained targets
+// such as routers and IoT gateways with single-digit MB budgets:
+//
+//   - chunks never grow past maxChunkSize (the arena's chunk size if <= 0);
+//     an allocation that does not fit gets a chunk of exactly its size
+//     rather than a rounded-up one;
+//   - the growth factor and growth function are reset to fixed-size chunks;
+//   - if maxChunks > 0, at most maxChunks chunks are held at once,
+//     counting those kept for reuse. Growing past the limit panics.
+//     Together with WithBudget this bounds the arena's footprint.
+//
+// The profile deliberately keeps native-width chunk offsets rather than
+// uint32 ones. The offset feeds the pointer arithmetic of every
+// allocation, and a chunk's bookkeeping is dominated by its slice header,
+// so narrowing it would save a few bytes per chunk at a cost on the
+// allocation fast path.
+//
+// Building with the arena_compact tag applies WithCompactProfile(0, 0) to
+// every new Arena and lowers DefaultChunkSize to CompactChunkSize; options
+// passed to NewArena still override it.
+func WithCompactProfile(maxChunkSize, maxChunks int) Option {
+	return func(a *Arena) {
+		if maxChunkSize <= 0 {
+			maxChunkSize = a.chunkSize
+		}
+		a.maxChunkSize = maxChunkSize
+		a.maxChunks = maxChunks
+		a.growth = 0
+		a.growthFunc = nil
+	}
+}
@@ -0,0 +1,10 @@
+//go:build arena_compact
+
+package arena
+
+// DefaultChunkSize is the default chunk size for new arenas (CompactChunkSize
+// under the arena_compact build tag).
+const DefaultChunkSize = CompactChunkSize
+
+// compactBuild reports whether the arena_compact build tag is set.
+const compactBuild = true
@@ -0,0 +1,9 @@
+//go:build !arena_compact
+
+package arena
+
+// DefaultChunkSize is the default chunk size for new arenas (64 KiB).
+const DefaultChunkSize = 1 << 16
+
+// compactBuild reports whether the arena_compact build tag is set.
+const compactBuild = false
@@ -0,0 +1,61 @@
+package arena
+
+import "testing"
+
+func TestCompactProfile(t *testing.T) {
+	a := NewArena(1024, WithGrowthFactor(2), WithCompactProfile(0, 3))
+	if p := a.GrowthPolicy(); p.MaxChunkSize != 1024 || p.Factor != 0 {
+		t.Errorf("GrowthPolicy() = %+v, want max 1024 and fixed-size chunks", p)
+	}
+	for i := 0; i < 3; i++ {
+		a.AllocBytes(1000)
+	}
+	if a.NumChunks() != 3 || a.Capacity() != 3*1024 {
+		t.Errorf("NumChunks, Capacity = %d, %d, want 3, %d", a.NumChunks(), a.Capacity(), 3*1024)
+	}
+
+	// Chunks are reused after Reset without counting against the limit.
+	a.Reset()
+	for i := 0; i < 3; i++ {
+		a.AllocBytes(1000)
+	}
+
+	defer func() {
+		if r := recover(); r != "arena: chunk limit reached" {
+			t.Errorf("recover() = %v, want chunk limit panic", r)
+		}
+	}()
+	a.AllocBytes(1000)
+}
+
+func TestCompactProfileOversize(t *testing.T) {
+	a := NewArena(1024, WithCompactProfile(2048, 0))
+	// Allocations larger than a chunk get a chunk of exactly their size,
+	// within the cap or beyond it.
+	for _, n := range []int{2000, 4096} {
+		before := a.Capacity()
+		if b := a.AllocBytes(n); len(b) != n {
+			t.Fatalf("len = %d, want %d", len(b), n)
+		}
+		if got := a.Capacity() - before; got != n {
+			t.Errorf("AllocBytes(%d) added a %d-byte chunk, want %d", n, got, n)
+		}
+	}
+	if p := a.GrowthPolicy(); p.NextChunkSize != 1024 {
+		t.Errorf("NextChunkSize = %d after oversize allocations, want 1024", p.NextChunkSize)
+	}
+}
+
+func TestCompactBuildDefaults(t *testing.T) {
+	a := NewArena(0)
+	if compactBuild {
+		if DefaultChunkSize != CompactChunkSize || a.GrowthPolicy().MaxChunkSize != CompactChunkSize {
+			t.Errorf("compact build: DefaultChunkSize = %d, MaxChunkSize = %d, want %d",
+				DefaultChunkSize, a.GrowthPolicy().MaxChunkSize, CompactChunkSize)
+		}
+		return
+	}
+	if a.GrowthPolicy().MaxChunkSize != 0 {
+		t.Errorf("MaxChunkSize = %d, want 0 without arena_compact", a.GrowthPolicy().MaxChunkSize)
+	}
+}
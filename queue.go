@@ -0,0 +1,109 @@
+package arena
+
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// qnode is a queue link. Nodes live in arena memory; they only ever point
+// to other nodes and payloads in the same arena.
+type qnode struct {
+	next    atomic.Pointer[qnode]
+	payload []byte
+}
+
+// Queue is a multi-producer, single-consumer FIFO for handing arena-backed
+// messages between goroutines without copying. Its nodes are allocated from
+// a SafeArena, and payloads pushed onto it must come from the same arena.
+//
+// Lifetime follows epochs: every message pushed during an epoch stays valid
+// until the consumer has popped it and called Done, after which TryReset may
+// reset the arena and start a new epoch.
+type Queue struct {
+	s       *SafeArena
+	reset   sync.RWMutex // producers hold R while pushing; TryReset holds W
+	head    atomic.Pointer[qnode]
+	tail    *qnode // consumer only
+	pending atomic.Int64
+	epoch   atomic.Uint64
+}
+
+// NewQueue creates a Queue allocating its nodes from s.
+func NewQueue(s *SafeArena) *Queue {
+	q := &Queue{s: s}
+	q.initStub()
+	return q
+}
+
+// initStub installs a fresh sentinel node allocated from the arena.
+func (q *Queue) initStub() {
+	stub := q.allocNode()
+	q.head.Store(stub)
+	q.tail = stub
+}
+
+// allocNode returns a zeroed node from the arena.
+func (q *Queue) allocNode() *qnode {
+	b := q.s.AllocBytes(int(unsafe.Sizeof(qnode{})))
+	clear(b)
+	return (*qnode)(unsafe.Pointer(&b[0]))
+}
+
+// Push enqueues payload without copying it. payload must be memory
+// allocated from the queue's SafeArena. Safe for concurrent producers.
+func (q *Queue) Push(payload []byte) {
+	q.reset.RLock()
+	defer q.reset.RUnlock()
+	n := q.allocNode()
+	n.payload = payload
+	q.pending.Add(1)
+	prev := q.head.Swap(n)
+	prev.next.Store(n)
+}
+
+// Pop dequeues the oldest message. It returns false if the queue is empty.
+// Must only be called from the single consumer goroutine. The returned
+// payload stays valid until Done is called for it and the epoch ends.
+func (q *Queue) Pop() ([]byte, bool) {
+	next := q.tail.next.Load()
+	if next == nil {
+		return nil, false
+	}
+	q.tail = next
+	payload := next.payload
+	next.payload = nil
+	return payload, true
+}
+
+// Done marks one popped message as fully consumed.
+func (q *Queue) Done() {
+	q.pending.Add(-1)
+}
+
+// Pending returns the number of messages pushed in the current epoch that
+// have not been marked Done.
+func (q *Queue) Pending() int {
+	return int(q.pending.Load())
+}
+
+// Epoch returns the number of completed TryReset calls.
+func (q *Queue) Epoch() uint64 {
+	return q.epoch.Load()
+}
+
+// TryReset ends the current epoch by resetting the arena, provided every
+// message pushed so far has been popped and marked Done. It returns false,
+// leaving the arena untouched, if any message is still outstanding. Must
+// only be called from the consumer goroutine.
+func (q *Queue) TryReset() bool {
+	q.reset.Lock()
+	defer q.reset.Unlock()
+	if q.pending.Load() != 0 || q.tail.next.Load() != nil {
+		return false
+	}
+	q.s.Reset()
+	q.initStub()
+	q.epoch.Add(1)
+	return true
+}
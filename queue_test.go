@@ -0,0 +1,84 @@
+package arena
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestQueueFIFO(t *testing.T) {
+	s := NewSafeArena(1024)
+	q := NewQueue(s)
+
+	for _, msg := range []string{"a", "bb", "ccc"} {
+		b := s.AllocBytes(len(msg))
+		copy(b, msg)
+		q.Push(b)
+	}
+
+	for _, want := range []string{"a", "bb", "ccc"} {
+		got, ok := q.Pop()
+		if !ok || string(got) != want {
+			t.Fatalf("Pop() = %q, %v; want %q, true", got, ok, want)
+		}
+	}
+	if _, ok := q.Pop(); ok {
+		t.Error("Pop() on empty queue returned true")
+	}
+
+	// Epoch cannot end while messages are outstanding
+	if q.TryReset() {
+		t.Error("TryReset() succeeded with 3 messages not Done")
+	}
+	q.Done()
+	q.Done()
+	q.Done()
+	if !q.TryReset() {
+		t.Error("TryReset() failed after all messages were Done")
+	}
+	if q.Epoch() != 1 || s.SizeInUse() == 0 {
+		t.Errorf("after TryReset: Epoch() = %d, SizeInUse = %d", q.Epoch(), s.SizeInUse())
+	}
+}
+
+func TestQueueConcurrentProducers(t *testing.T) {
+	s := NewSafeArena(4096)
+	q := NewQueue(s)
+	const producers, perProducer = 4, 500
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(id byte) {
+			defer wg.Done()
+			for i := 0; i < perProducer; i++ {
+				b := s.AllocBytes(1)
+				b[0] = id
+				q.Push(b)
+			}
+		}(byte(p))
+	}
+
+	counts := make([]int, producers)
+	received := 0
+	for received < producers*perProducer {
+		b, ok := q.Pop()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		counts[b[0]]++
+		q.Done()
+		received++
+	}
+
+	wg.Wait()
+	for id, n := range counts {
+		if n != perProducer {
+			t.Errorf("producer %d: received %d messages, want %d", id, n, perProducer)
+		}
+	}
+	if !q.TryReset() {
+		t.Error("TryReset() failed after draining the queue")
+	}
+}
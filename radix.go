@@ -0,0 +1,197 @@
+package arena
+
+import (
+	"math"
+	"slices"
+	"strings"
+)
+
+// radixNode is a node of a built Radix tree. Nodes are stored contiguously
+// in arena memory and refer to each other and to their key bytes by index,
+// so they hold no Go pointers.
+type radixNode struct {
+	off, len uint32 // edge label: keys[off : off+len]
+	child    uint32 // index of the first child; children are contiguous
+	numChild uint32 // sorted by the first byte of their label
+	value    int32  // index into values, or -1 if no key ends here
+}
+
+// Radix is an immutable radix tree mapping string keys to values of type V,
+// built with a RadixBuilder. Nodes and key bytes live in the arena passed to
+// Build, values in a single heap slice; lookups do not allocate. A Radix is
+// safe for concurrent readers and is valid until that arena is reset or
+// released. To update a table, build a new tree (typically in a fresh arena)
+// and publish it with an atomic.Pointer, releasing the old arena once no
+// reader uses it.
+type Radix[V any] struct {
+	nodes  []radixNode
+	keys   []byte
+	values []V
+}
+
+// RadixBuilder collects entries for a Radix. The zero value is ready to use.
+type RadixBuilder[V any] struct {
+	entries map[string]V
+}
+
+// NewRadixBuilder returns an empty RadixBuilder.
+func NewRadixBuilder[V any]() *RadixBuilder[V] {
+	return &RadixBuilder[V]{}
+}
+
+// Insert adds key with value v, replacing any previous value for key.
+func (b *RadixBuilder[V]) Insert(key string, v V) {
+	if b.entries == nil {
+		b.entries = make(map[string]V)
+	}
+	b.entries[key] = v
+}
+
+// Len returns the number of distinct keys inserted.
+func (b *RadixBuilder[V]) Len() int {
+	return len(b.entries)
+}
+
+// Build lays the collected entries out as a Radix tree in a. The builder
+// can be reused or extended afterwards to build further versions.
+func (b *RadixBuilder[V]) Build(a *Arena) *Radix[V] {
+	keys := make([]string, 0, len(b.entries))
+	size := 0
+	for k := range b.entries {
+		keys = append(keys, k)
+		size += len(k)
+	}
+	if uint64(size) > math.MaxUint32 || uint64(len(keys)) > math.MaxInt32 {
+		panic("arena: Radix too large")
+	}
+	slices.Sort(keys)
+
+	r := &Radix[V]{values: make([]V, 0, len(keys))}
+	if len(keys) == 0 {
+		return r
+	}
+	rb := radixBuild[V]{
+		r:     r,
+		src:   b.entries,
+		keys:  AllocSlice[byte](a, size)[:0],
+		nodes: make([]radixNode, 1, 2*len(keys)),
+	}
+	rb.build(0, keys, 0)
+	r.keys = rb.keys
+	r.nodes = AllocSlice[radixNode](a, len(rb.nodes))
+	copy(r.nodes, rb.nodes)
+	return r
+}
+
+// radixBuild holds the state of a single Build.
+type radixBuild[V any] struct {
+	r     *Radix[V]
+	src   map[string]V
+	keys  []byte
+	nodes []radixNode
+}
+
+// build fills node i from sorted keys that all share their first depth
+// bytes, allocating its children contiguously before recursing into them.
+func (rb *radixBuild[V]) build(i int, keys []string, depth int) {
+	first, last := keys[0], keys[len(keys)-1]
+	n := depth
+	for n < len(first) && first[n] == last[n] {
+		n++
+	}
+	nd := radixNode{off: uint32(len(rb.keys)), len: uint32(n - depth), value: -1}
+	rb.keys = append(rb.keys, first[depth:n]...)
+	if len(first) == n {
+		nd.value = int32(len(rb.r.values))
+		rb.r.values = append(rb.r.values, rb.src[first])
+		keys = keys[1:]
+	}
+
+	// Group the remaining keys by their next byte.
+	var groups [][]string
+	for len(keys) > 0 {
+		c := keys[0][n]
+		j := 1
+		for j < len(keys) && keys[j][n] == c {
+			j++
+		}
+		groups = append(groups, keys[:j])
+		keys = keys[j:]
+	}
+	nd.child = uint32(len(rb.nodes))
+	nd.numChild = uint32(len(groups))
+	rb.nodes[i] = nd
+	rb.nodes = append(rb.nodes, make([]radixNode, len(groups))...)
+	for g, group := range groups {
+		rb.build(int(nd.child)+g, group, n)
+	}
+}
+
+// Len returns the number of keys in the tree.
+func (r *Radix[V]) Len() int {
+	return len(r.values)
+}
+
+// Get returns the value stored for key.
+func (r *Radix[V]) Get(key string) (V, bool) {
+	if i, n := r.walk(key); i >= 0 && n == len(key) {
+		return r.values[i], true
+	}
+	var zero V
+	return zero, false
+}
+
+// LongestPrefix returns the longest key in the tree that is a prefix of key,
+// together with its value. This is the lookup used by routing tables.
+func (r *Radix[V]) LongestPrefix(key string) (prefix string, v V, ok bool) {
+	if i, n := r.walk(key); i >= 0 {
+		return key[:n], r.values[i], true
+	}
+	return "", v, false
+}
+
+// walk follows key down the tree and returns the value index and length of
+// the deepest key that is a prefix of key, or -1 if there is none.
+func (r *Radix[V]) walk(key string) (value int32, matched int) {
+	value = -1
+	if len(r.nodes) == 0 {
+		return value, 0
+	}
+	nd := &r.nodes[0]
+	depth := 0
+	for {
+		label := r.keys[nd.off : nd.off+nd.len]
+		if !strings.HasPrefix(key[depth:], string(label)) {
+			return value, matched
+		}
+		depth += len(label)
+		if nd.value >= 0 {
+			value, matched = nd.value, depth
+		}
+		if depth == len(key) {
+			return value, matched
+		}
+		nd = r.child(nd, key[depth])
+		if nd == nil {
+			return value, matched
+		}
+	}
+}
+
+// child returns the child of nd whose label starts with c, or nil.
+func (r *Radix[V]) child(nd *radixNode, c byte) *radixNode {
+	children := r.nodes[nd.child : nd.child+nd.numChild]
+	lo, hi := 0, len(children)
+	for lo < hi {
+		m := int(uint(lo+hi) >> 1)
+		if r.keys[children[m].off] < c {
+			lo = m + 1
+		} else {
+			hi = m
+		}
+	}
+	if lo < len(children) && r.keys[children[lo].off] == c {
+		return &children[lo]
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package arena
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestRadixGet(t *testing.T) {
+	a := NewArena(1024)
+	b := NewRadixBuilder[int]()
+	keys := []string{"", "a", "ab", "abc", "abd", "b", "/api/v1/users", "/api/v1/orders", "/api/v2", "/"}
+	for i, k := range keys {
+		b.Insert(k, i)
+	}
+	b.Insert("ab", 100) // replaces
+	r := b.Build(a)
+
+	if r.Len() != len(keys) {
+		t.Errorf("Len() = %d, want %d", r.Len(), len(keys))
+	}
+	for i, k := range keys {
+		want := i
+		if k == "ab" {
+			want = 100
+		}
+		if v, ok := r.Get(k); !ok || v != want {
+			t.Errorf("Get(%q) = %d, %v, want %d, true", k, v, ok, want)
+		}
+	}
+	for _, k := range []string{"abe", "ac", "c", "/api", "/api/v1", "/api/v1/users/42"} {
+		if v, ok := r.Get(k); ok {
+			t.Errorf("Get(%q) = %d, true, want not found", k, v)
+		}
+	}
+}
+
+func TestRadixLongestPrefix(t *testing.T) {
+	a := NewArena(1024)
+	b := NewRadixBuilder[string]()
+	b.Insert("/", "root")
+	b.Insert("/api/", "api")
+	b.Insert("/api/v1/", "v1")
+	b.Insert("/static/", "static")
+	r := b.Build(a)
+
+	tests := []struct {
+		key, prefix, v string
+		ok             bool
+	}{
+		{"/api/v1/users", "/api/v1/", "v1", true},
+		{"/api/v2/users", "/api/", "api", true},
+		{"/api", "/", "root", true},
+		{"/static/app.js", "/static/", "static", true},
+		{"", "", "", false},
+		{"x", "", "", false},
+	}
+	for _, tt := range tests {
+		prefix, v, ok := r.LongestPrefix(tt.key)
+		if prefix != tt.prefix || v != tt.v || ok != tt.ok {
+			t.Errorf("LongestPrefix(%q) = %q, %q, %v, want %q, %q, %v",
+				tt.key, prefix, v, ok, tt.prefix, tt.v, tt.ok)
+		}
+	}
+}
+
+func TestRadixEmpty(t *testing.T) {
+	r := NewRadixBuilder[int]().Build(NewArena(1024))
+	if _, ok := r.Get(""); ok {
+		t.Error("Get on empty tree found a key")
+	}
+	if _, _, ok := r.LongestPrefix("abc"); ok {
+		t.Error("LongestPrefix on empty tree found a key")
+	}
+}
+
+func TestRadixRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	want := make(map[string]int)
+	b := NewRadixBuilder[int]()
+	for i := 0; i < 2000; i++ {
+		k := strconv.FormatInt(rng.Int63n(1<<20), 36)
+		want[k] = i
+		b.Insert(k, i)
+	}
+	r := b.Build(NewArena(0))
+	for k, v := range want {
+		if got, ok := r.Get(k); !ok || got != v {
+			t.Fatalf("Get(%q) = %d, %v, want %d, true", k, got, ok, v)
+		}
+	}
+}
+
+func TestRadixLookupNoAlloc(t *testing.T) {
+	b := NewRadixBuilder[int]()
+	for i := 0; i < 100; i++ {
+		b.Insert("/tenant/"+strconv.Itoa(i), i)
+	}
+	r := b.Build(NewArena(0))
+	allocs := testing.AllocsPerRun(100, func() {
+		r.Get("/tenant/42")
+		r.LongestPrefix("/tenant/42/config")
+	})
+	if allocs != 0 {
+		t.Errorf("lookup allocs = %v, want 0", allocs)
+	}
+}
+
+func BenchmarkRadixGet(b *testing.B) {
+	rb := NewRadixBuilder[int]()
+	keys := make([]string, 1000)
+	for i := range keys {
+		keys[i] = "/tenant/" + strconv.Itoa(i) + "/route"
+		rb.Insert(keys[i], i)
+	}
+	r := rb.Build(NewArena(0))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		r.Get(keys[i%len(keys)])
+	}
+}
@@ -0,0 +1,80 @@
+package arena
+
+import "runtime"
+
+// ReadEpoch identifies the reset epoch a reader entered with BeginRead.
+type ReadEpoch uint64
+
+// BeginRead marks the start of a read-side section. Memory allocated before
+// the call stays valid until the matching EndRead, even if Reset runs
+// concurrently: Reset swaps in a fresh chunk set and only recycles the old
+// one once every reader that could still see it has left. Readers never
+// take the arena lock, so they do not stall allocations or Reset.
+//
+//	r := s.BeginRead()
+//	defer s.EndRead(r)
+func (s *SafeArena) BeginRead() ReadEpoch {
+	for {
+		e := s.epoch.Load()
+		s.readers[e&1].Add(1)
+		if s.epoch.Load() == e {
+			return ReadEpoch(e)
+		}
+		// Reset flipped the epoch in between; retry in the new one.
+		s.readers[e&1].Add(-1)
+	}
+}
+
+// EndRead ends a read-side section started by BeginRead.
+func (s *SafeArena) EndRead(r ReadEpoch) {
+	if s.readers[r&1].Add(-1) < 0 {
+		panic("arena: EndRead without matching BeginRead")
+	}
+}
+
+// ResetRetiring reports whether the chunk set swapped out by the last Reset
+// is still held back for readers.
+func (s *SafeArena) ResetRetiring() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.a.ResetPending()
+}
+
+// Reclaim recycles the chunk set retired by the last Reset if no reader
+// from before that Reset remains, and reports whether nothing is left
+// retiring. Reset calls it itself; calling it after readers finish returns
+// the memory sooner.
+func (s *SafeArena) Reclaim() bool {
+	s.mu.Lock()
+	defer s.unlock()
+	return s.reclaim(false)
+}
+
+// reset swaps in a fresh chunk set and starts a new epoch. A set retired by
+// the previous Reset is recycled first, waiting for its readers if needed,
+// so at most two chunk sets are live. s.mu must be held.
+func (s *SafeArena) reset() {
+	s.a.panicIfReleased()
+	s.dropShardBlocks()
+	s.reclaim(true)
+	s.a.ResetPrepare()
+	s.epoch.Add(1)
+	s.reclaim(false)
+}
+
+// reclaim commits a pending reset once the readers of the retired epoch have
+// drained, spinning until they have if wait is set. s.mu must be held.
+func (s *SafeArena) reclaim(wait bool) bool {
+	if !s.a.ResetPending() {
+		return true
+	}
+	old := &s.readers[(s.epoch.Load()-1)&1]
+	for old.Load() != 0 {
+		if !wait {
+			return false
+		}
+		runtime.Gosched()
+	}
+	s.a.ResetCommit()
+	return true
+}
@@ -0,0 +1,120 @@
+package arena
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSafeArenaResetKeepsReaders(t *testing.T) {
+	s := NewSafeArena(1024)
+	b := s.AllocBytes(8)
+	copy(b, "snapshot")
+
+	r := s.BeginRead()
+	s.Reset()
+	if !s.ResetRetiring() {
+		t.Fatal("ResetRetiring() = false with an active reader")
+	}
+	// New allocations must not reuse the memory the reader can see.
+	for i := 0; i < 10; i++ {
+		n := s.AllocBytes(512)
+		for j := range n {
+			n[j] = 0xFF
+		}
+	}
+	if string(b) != "snapshot" {
+		t.Errorf("reader memory = %q, want %q", b, "snapshot")
+	}
+	if s.Reclaim() {
+		t.Error("Reclaim() = true with an active reader")
+	}
+
+	s.EndRead(r)
+	if !s.Reclaim() {
+		t.Error("Reclaim() = false after EndRead")
+	}
+	if s.ResetRetiring() {
+		t.Error("ResetRetiring() = true after Reclaim")
+	}
+}
+
+func TestSafeArenaResetWithoutReaders(t *testing.T) {
+	s := NewSafeArena(1024)
+	for i := 0; i < 5; i++ {
+		s.AllocBytes(100)
+		s.Reset()
+		if s.ResetRetiring() {
+			t.Fatalf("cycle %d: ResetRetiring() = true without readers", i)
+		}
+	}
+	if s.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after Reset() = %d, want 0", s.SizeInUse())
+	}
+	// Recycled chunk sets do not accumulate.
+	if n := s.NumChunks() + len(s.a.spare); n > 2 {
+		t.Errorf("chunks held = %d, want <= 2", n)
+	}
+}
+
+func TestSafeArenaResetWaitsForOldReaders(t *testing.T) {
+	s := NewSafeArena(1024)
+	r := s.BeginRead()
+	s.Reset() // retires the first set behind r
+
+	var done atomic.Bool
+	go func() {
+		s.Reset() // must wait for r before recycling the first set
+		done.Store(true)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if done.Load() {
+		t.Fatal("second Reset() did not wait for the old reader")
+	}
+	s.EndRead(r)
+	for !done.Load() {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestSafeArenaEndReadUnbalanced(t *testing.T) {
+	s := NewSafeArena(1024)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("Expected panic on EndRead without BeginRead")
+		}
+	}()
+	s.EndRead(0)
+}
+
+func TestSafeArenaConcurrentReadersAndReset(t *testing.T) {
+	s := NewSafeArena(4096)
+	var stop atomic.Bool
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for !stop.Load() {
+				r := s.BeginRead()
+				b := s.AllocBytes(16)
+				for i := range b {
+					b[i] = byte(g)
+				}
+				for i := range b {
+					if b[i] != byte(g) {
+						t.Errorf("reader %d saw memory reused during read", g)
+						break
+					}
+				}
+				s.EndRead(r)
+			}
+		}(g)
+	}
+	for i := 0; i < 200; i++ {
+		s.Reset()
+	}
+	stop.Store(true)
+	wg.Wait()
+}
@@ -0,0 +1,37 @@
+package arena
+
+import "io"
+
+// copyNPrealloc bounds what CopyN allocates up front on the strength of n
+// alone, since n often comes from an untrusted length header.
+const copyNPrealloc = 64 << 10
+
+// ReadAll is io.ReadAll reading into the arena: data goes straight into
+// arena memory, which grows in place while it is the most recent
+// allocation in its chunk, instead of into a heap buffer that is then
+// copied. Capacity left over at EOF is handed back to the chunk. As with
+// io.ReadAll, a successful call returns err == nil, not io.EOF, and on
+// error the data read so far is returned.
+func ReadAll(a *Arena, r io.Reader) ([]byte, error) {
+	b := NewBuffer(a)
+	_, err := b.ReadFrom(r)
+	return a.trimInPlace(b.buf), err
+}
+
+// CopyN reads exactly n bytes from r into the arena, for bodies of known
+// length. Up to 64 KiB is allocated up front and the rest as data
+// arrives, so a bogus n cannot make it allocate memory the reader never
+// fills. As with io.CopyN, the result is n bytes long if and only if err
+// is nil, and err is io.EOF if r ended early.
+func CopyN(a *Arena, r io.Reader, n int64) ([]byte, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	b := NewBuffer(a)
+	b.Grow(int(min(n, copyNPrealloc)))
+	read, err := b.ReadFrom(io.LimitReader(r, n))
+	if read < n && err == nil {
+		err = io.EOF
+	}
+	return a.trimInPlace(b.buf), err
+}
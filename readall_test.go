@@ -0,0 +1,80 @@
+package arena
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadAll(t *testing.T) {
+	a := NewArena(1 << 20)
+	data := bytes.Repeat([]byte("0123456789"), 10000)
+	got, err := ReadAll(a, iotest.HalfReader(bytes.NewReader(data)))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("ReadAll = %d bytes, %v, want %d bytes", len(got), err, len(data))
+	}
+	// Grown in place, with the spare capacity handed back.
+	if a.SizeInUse() != len(data) || cap(got) != len(got) {
+		t.Errorf("SizeInUse = %d, cap = %d, want both %d", a.SizeInUse(), cap(got), len(data))
+	}
+
+	boom := errors.New("boom")
+	got, err = ReadAll(a, io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(boom)))
+	if !errors.Is(err, boom) || string(got) != "partial" {
+		t.Errorf("ReadAll = %q, %v, want the partial data and the error", got, err)
+	}
+
+	got, err = ReadAll(a, strings.NewReader(""))
+	if err != nil || len(got) != 0 {
+		t.Errorf("ReadAll of nothing = %q, %v", got, err)
+	}
+}
+
+func TestCopyN(t *testing.T) {
+	a := NewArena(4096)
+	got, err := CopyN(a, strings.NewReader("hello, world"), 5)
+	if err != nil || string(got) != "hello" {
+		t.Errorf("CopyN(5) = %q, %v, want \"hello\", nil", got, err)
+	}
+	got, err = CopyN(a, strings.NewReader("short"), 100)
+	if err != io.EOF || string(got) != "short" {
+		t.Errorf("CopyN past the end = %q, %v, want \"short\", io.EOF", got, err)
+	}
+	// A huge claimed length only costs what the reader delivers.
+	before := a.Capacity()
+	if got, _ := CopyN(a, strings.NewReader("tiny"), 1<<40); string(got) != "tiny" {
+		t.Errorf("CopyN(1<<40) = %q", got)
+	}
+	if a.Capacity()-before > copyNPrealloc+4096 {
+		t.Errorf("CopyN(1<<40) grew the arena by %d bytes", a.Capacity()-before)
+	}
+	if got, err := CopyN(a, strings.NewReader("x"), 0); got != nil || err != nil {
+		t.Errorf("CopyN(0) = %q, %v", got, err)
+	}
+}
+
+func BenchmarkReadAll(b *testing.B) {
+	data := bytes.Repeat([]byte("x"), 64<<10)
+	b.Run("io", func(b *testing.B) {
+		a := NewArena(1 << 20)
+		for i := 0; i < b.N; i++ {
+			body, _ := io.ReadAll(bytes.NewReader(data))
+			CloneBytes(a, body)
+			if i%10 == 9 {
+				a.Reset()
+			}
+		}
+	})
+	b.Run("Arena", func(b *testing.B) {
+		a := NewArena(1 << 20)
+		for i := 0; i < b.N; i++ {
+			ReadAll(a, bytes.NewReader(data))
+			if i%10 == 9 {
+				a.Reset()
+			}
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package arena
+
+import "unsafe"
+
+// WithRealtime bounds the worst-case latency of allocation for soft
+// real-time code such as audio callbacks and trading loops. A background
+// goroutine keeps up to depth chunks of the arena's chunk size allocated
+// ahead of time, and the slow path only takes chunks from there or from
+// the arena's spares: it never calls make on the allocating goroutine.
+// When no chunk is ready, TryAllocBytes and TryAlloc fail immediately and
+// AllocBytes and the other allocation functions panic.
+//
+// Chunks are fixed-size in this mode, so allocations larger than a chunk
+// always fail. Only the first chunk, added by NewArena or on first use
+// with WithLazyInit, is allocated directly. The prefetcher may hold one
+// chunk beyond depth while it waits for room. Release stops it.
+func WithRealtime(depth int) Option {
+	return func(a *Arena) {
+		a.rt = &prefetcher{ready: make(chan []byte, max(depth, 1))}
+	}
+}
+
+// prefetcher allocates chunk buffers ahead of time on its own goroutine.
+type prefetcher struct {
+	size  int
+	ready chan []byte
+	stop  chan struct{}
+	done  chan struct{}
+}
+
+// start begins prefetching size-byte chunk buffers.
+func (p *prefetcher) start(size int) {
+	p.size = size
+	p.stop = make(chan struct{})
+	p.done = make(chan struct{})
+	go p.run()
+}
+
+func (p *prefetcher) run() {
+	defer close(p.done)
+	for {
+		buf := make([]byte, p.size)
+		select {
+		case p.ready <- buf:
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// take returns a prefetched buffer of at least size bytes without
+// blocking, or false if none is ready.
+func (p *prefetcher) take(size int) ([]byte, bool) {
+	if size > p.size {
+		return nil, false
+	}
+	select {
+	case buf := <-p.ready:
+		return buf, true
+	default:
+		return nil, false
+	}
+}
+
+// release stops the prefetcher and drops its buffers.
+func (p *prefetcher) release() {
+	close(p.stop)
+	<-p.done
+	for len(p.ready) > 0 {
+		<-p.ready
+	}
+}
+
+// TryAllocBytes is AllocBytes for arenas in real-time mode: instead of
+// waiting for memory it returns false if the allocation needs a chunk and
+// no pre-provisioned one is available. Without WithRealtime it is
+// AllocBytes. Returns nil, false if n <= 0.
+func (a *Arena) TryAllocBytes(n int) ([]byte, bool) {
+	if n > a.maxAlloc {
+		a.allocTooLarge(n)
+	}
+	if b := a.bump(n); b != nil {
+		a.countAlloc(n)
+		return b, true
+	}
+	if n <= 0 {
+		return nil, false
+	}
+	a.panicIfReleased()
+	if a.rt != nil && !a.reserve(n) {
+		return nil, false
+	}
+	return a.allocBytesSlow(n), true
+}
+
+// TryAlloc is Alloc for arenas in real-time mode; see TryAllocBytes.
+func TryAlloc[T any](a *Arena) (*T, bool) {
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	if size > 0 && !a.canBump(size) {
+		a.panicIfReleased()
+		if a.rt != nil && !a.reserve(size) {
+			return nil, false
+		}
+	}
+	return Alloc[T](a), true
+}
+
+// canBump reports whether n bytes fit in the current chunk on the fast path.
+func (a *Arena) canBump(n int) bool {
+	c := a.currentChunk
+	return c != nil && a.debug == nil && alignPtr(c.offset)+uintptr(n) <= uintptr(len(c.buf))
+}
+
+// reserve reports whether an n-byte allocation can be served without
+// allocating a chunk, moving a prefetched chunk to the spares if the
+// allocation needs one.
+func (a *Arena) reserve(n int) bool {
+	total := n
+	if d := a.debug; d != nil {
+		if d.canaries {
+			total += canarySize
+		}
+		if d.stamps {
+			total += stampSize
+		}
+	}
+	for i := max(a.chunkIndex(a.currentChunk), 0); i < len(a.chunks); i++ {
+		c := &a.chunks[i]
+		if alignPtr(c.offset)+uintptr(total) <= uintptr(len(c.buf)) {
+			return true
+		}
+	}
+	need := total + int(a.chunkBase)
+	if a.zeroer != nil {
+		a.spare, _ = a.zeroer.collect(a.spare, false)
+	}
+	for _, c := range a.spare {
+		if len(c.buf) >= need {
+			return true
+		}
+	}
+	buf, ok := a.takePrefetched(need)
+	if ok {
+		a.spare = append(a.spare, chunk{buf: buf, zeroed: true})
+	}
+	return ok
+}
+
+// takePrefetched takes a prefetched chunk buffer of at least size bytes
+// and charges it to the budget, or returns false.
+func (a *Arena) takePrefetched(size int) ([]byte, bool) {
+	if a.maxChunks > 0 && len(a.chunks)+len(a.retired)+len(a.spare)+len(a.pinned) >= a.maxChunks {
+		return nil, false
+	}
+	if !a.chargeGovernor(a.rt.size, false) {
+		return nil, false
+	}
+	if a.budget != nil && !a.budget.charge(int64(a.rt.size)) {
+		a.refundGovernor(a.rt.size)
+		return nil, false
+	}
+	buf, ok := a.rt.take(size)
+	if !ok {
+		a.refundGovernor(a.rt.size)
+		if a.budget != nil {
+			a.budget.refund(int64(a.rt.size))
+		}
+		return nil, false
+	}
+	if a.budget != nil {
+		a.budgetHeld += int64(len(buf))
+	}
+	return buf, true
+}
@@ -0,0 +1,98 @@
+package arena
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// waitPrefetched waits until the arena's prefetcher has n chunks ready.
+func waitPrefetched(t *testing.T, a *Arena, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(a.rt.ready) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("prefetcher has %d chunks ready, want %d", len(a.rt.ready), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestRealtimeTryAlloc(t *testing.T) {
+	a := NewArena(1024, WithRealtime(2))
+	defer a.Release()
+	waitPrefetched(t, a, 2)
+
+	// The first chunk plus two prefetched ones serve three chunks' worth.
+	for i := 0; i < 3; i++ {
+		if _, ok := a.TryAllocBytes(1000); !ok {
+			t.Fatalf("TryAllocBytes %d failed with chunks prefetched", i)
+		}
+	}
+	// Drain whatever the prefetcher refilled meanwhile, then fail fast.
+	ok := true
+	for i := 0; i < 10 && ok; i++ {
+		_, ok = a.TryAllocBytes(1000)
+	}
+	if ok {
+		t.Error("TryAllocBytes never failed with the prefetcher drained")
+	}
+	if _, ok := a.TryAllocBytes(4096); ok {
+		t.Error("TryAllocBytes larger than a chunk succeeded")
+	}
+	if _, ok := TryAlloc[[2000]byte](a); ok {
+		t.Error("TryAlloc larger than a chunk succeeded")
+	}
+
+	// After a Reset the arena refills its own chunks without new ones.
+	a.Reset()
+	for i := 0; i < 3; i++ {
+		if p, ok := TryAlloc[[1000]byte](a); !ok || p == nil {
+			t.Fatalf("TryAlloc %d after Reset failed", i)
+		}
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic from AllocBytes larger than a real-time chunk")
+			}
+		}()
+		a.AllocBytes(1 << 20)
+	}()
+}
+
+func TestRealtimeLatencyBudget(t *testing.T) {
+	if testing.Short() || testing.CoverMode() != "" || raceEnabled {
+		t.Skip("skipping latency budget in short, coverage or race mode")
+	}
+	const (
+		chunkSize = 64 << 10
+		allocSize = 256
+		samples   = 100_000
+		budget    = 50 * time.Microsecond // p999; generous for noisy machines
+	)
+	a := NewArena(chunkSize, WithRealtime(64))
+	defer a.Release()
+	waitPrefetched(t, a, 64)
+
+	// One chunk change every 256 allocations, served from the prefetcher.
+	lat := make([]time.Duration, 0, samples)
+	for i := 0; i < samples; i++ {
+		start := time.Now()
+		_, ok := a.TryAllocBytes(allocSize)
+		lat = append(lat, time.Since(start))
+		if !ok {
+			t.Fatalf("TryAllocBytes failed after %d allocations", i)
+		}
+		if i%(20*chunkSize/allocSize) == 0 {
+			a.Reset()
+		}
+	}
+	slices.Sort(lat)
+	p999 := lat[len(lat)*999/1000]
+	t.Logf("p50=%v p999=%v max=%v", lat[len(lat)/2], p999, lat[len(lat)-1])
+	if p999 > budget {
+		t.Errorf("p999 allocation latency = %v, want <= %v", p999, budget)
+	}
+}
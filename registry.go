@@ -0,0 +1,83 @@
+package arena
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// TypeInfo is the cached layout information for a type used with the
+// generic allocation functions.
+type TypeInfo struct {
+	Name          string // Type name as printed by reflect
+	Size          int    // unsafe.Sizeof of the type
+	Align         int    // unsafe.Alignof of the type
+	HasPointers   bool   // Whether values contain Go pointers
+	AllowPointers bool   // Exempt from PointerPolicy checks (set by Register)
+	Registered    bool   // Whether the type was registered explicitly
+}
+
+// TypeOption configures a type passed to Register.
+type TypeOption func(*TypeInfo)
+
+// AllowPointers exempts a registered pointer-containing type from
+// PointerWarn and PointerForbid. Use it for types whose pointers only ever
+// refer to memory kept alive elsewhere, such as the same arena.
+func AllowPointers() TypeOption {
+	return func(ti *TypeInfo) {
+		ti.AllowPointers = true
+	}
+}
+
+// typeEntry is a registry entry. info is immutable once stored.
+type typeEntry struct {
+	info   TypeInfo
+	warned atomic.Bool
+}
+
+// typeRegistry maps reflect.Type to *typeEntry. Types are added on first
+// use by the generic allocation functions, or up front by Register.
+var typeRegistry sync.Map
+
+// Register precomputes and caches layout information for T so that the
+// generic allocation functions and per-type accounting can look it up
+// instead of deriving it on first use. Registering a type again replaces
+// its options. Safe for concurrent use; typically called from init.
+func Register[T any](opts ...TypeOption) TypeInfo {
+	t := reflect.TypeFor[T]()
+	info := newTypeInfo[T](t)
+	info.Registered = true
+	for _, opt := range opts {
+		opt(&info)
+	}
+	typeRegistry.Store(t, &typeEntry{info: info})
+	return info
+}
+
+// LookupType returns the cached information for T and whether T has been
+// registered explicitly.
+func LookupType[T any]() (TypeInfo, bool) {
+	info := typeEntryFor[T]().info
+	return info, info.Registered
+}
+
+// typeEntryFor returns the registry entry for T, creating it on first use.
+func typeEntryFor[T any]() *typeEntry {
+	t := reflect.TypeFor[T]()
+	if e, ok := typeRegistry.Load(t); ok {
+		return e.(*typeEntry)
+	}
+	e, _ := typeRegistry.LoadOrStore(t, &typeEntry{info: newTypeInfo[T](t)})
+	return e.(*typeEntry)
+}
+
+func newTypeInfo[T any](t reflect.Type) TypeInfo {
+	var zero T
+	return TypeInfo{
+		Name:        t.String(),
+		Size:        int(unsafe.Sizeof(zero)),
+		Align:       int(unsafe.Alignof(zero)),
+		HasPointers: typeHasPointers(t),
+	}
+}
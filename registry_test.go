@@ -0,0 +1,71 @@
+package arena
+
+import (
+	"reflect"
+	"testing"
+)
+
+// forgetType drops T's registry entry for the duration of the test, so
+// tests that observe first use or registration pass under -count.
+func forgetType[T any](t *testing.T) {
+	t.Helper()
+	typeRegistry.Delete(reflect.TypeFor[T]())
+	t.Cleanup(func() { typeRegistry.Delete(reflect.TypeFor[T]()) })
+}
+
+func TestRegister(t *testing.T) {
+	type point struct {
+		x, y int32
+	}
+	forgetType[point](t)
+
+	if _, ok := LookupType[point](); ok {
+		t.Error("LookupType reports unregistered type as registered")
+	}
+
+	info := Register[point]()
+	if info.Size != 8 || info.Align != 4 || info.HasPointers || !info.Registered {
+		t.Errorf("Register[point]() = %+v", info)
+	}
+	if got, ok := LookupType[point](); !ok || got != info {
+		t.Errorf("LookupType[point]() = %+v, %v; want %+v, true", got, ok, info)
+	}
+}
+
+func TestRegisterAllowPointers(t *testing.T) {
+	defer SetPointerPolicy(PointerAllow)
+	type node struct {
+		next *node
+		val  int
+	}
+	Register[node](AllowPointers())
+
+	SetPointerPolicy(PointerForbid)
+	a := NewArena(1024)
+	n := Alloc[node](a)
+	n.next = n // registered pointer types are exempt
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for unregistered pointer type under PointerForbid")
+		}
+	}()
+	Alloc[*node](a)
+}
+
+func BenchmarkAllocStrictRegistered(b *testing.B) {
+	defer SetPointerPolicy(PointerAllow)
+	type rec struct{ a, b int64 }
+	Register[rec]()
+	SetPointerPolicy(PointerForbid)
+	a := NewArena(1024 * 1024)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Alloc[rec](a)
+		if i%10000 == 9999 {
+			a.Reset()
+		}
+	}
+}
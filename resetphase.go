@@ -0,0 +1,67 @@
+package arena
+
+// ResetPrepare begins a two-phase reset. The current chunks are retired:
+// memory handed out so far stays valid, while new allocations are served
+// from a fresh chunk set (reusing chunks recycled by an earlier ResetCommit
+// where possible). Call ResetCommit once every consumer of the retired
+// memory has finished. At most two chunk sets are live at any time.
+//
+// Panics if a previous ResetPrepare has not been committed.
+func (a *Arena) ResetPrepare() {
+	a.panicIfReleased()
+	if a.retired != nil {
+		panic("arena: ResetPrepare called before ResetCommit")
+	}
+	if a.debug != nil {
+		a.verifyCanaries()
+	}
+	a.retiredBytes += uint64(a.usedBytes(a.chunks))
+	a.recordCycle()
+	a.endCycleMetrics()
+	a.sampleAccess()
+	a.dropInterned()
+	a.retired = a.chunks
+	a.chunks = make([]chunk, 0, 1)
+	a.grow(max(a.chunkSize, len(a.template)))
+	if a.template != nil {
+		a.applyTemplate()
+	}
+	a.recordEvent(OpReset, 0, a.currentChunk, 0)
+}
+
+// ResetCommit completes a two-phase reset started by ResetPrepare. The
+// retired chunks are recycled for future growth; any memory allocated from
+// them must no longer be in use.
+//
+// Panics if there is no pending ResetPrepare.
+func (a *Arena) ResetCommit() {
+	a.panicIfReleased()
+	if a.retired == nil {
+		panic("arena: ResetCommit called without ResetPrepare")
+	}
+	if a.pins != nil {
+		a.retired = a.holdPinned(a.retired)
+	}
+	if a.debug != nil && a.debug.poison {
+		poisonChunks(a.retired, a.keepStamps())
+	}
+	for i := range a.retired {
+		if a.retired[i].offset > a.chunkBase {
+			a.retired[i].lastUsed = a.generation
+			if a.maps != nil {
+				a.maps.discard(a.retired[i].buf, int(a.retired[i].offset))
+			}
+		}
+		a.retired[i].offset = a.chunkBase
+		a.retired[i].zeroed = false
+	}
+	a.spare = append(a.spare, a.retired...)
+	a.retired = nil
+	a.resets++
+	a.invalidate()
+}
+
+// ResetPending reports whether a ResetPrepare is awaiting ResetCommit.
+func (a *Arena) ResetPending() bool {
+	return a.retired != nil
+}
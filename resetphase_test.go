@@ -0,0 +1,67 @@
+package arena
+
+import "testing"
+
+func TestTwoPhaseReset(t *testing.T) {
+	a := NewArena(1024)
+	old := a.AllocBytes(16)
+	copy(old, "in flight")
+
+	a.ResetPrepare()
+	if !a.ResetPending() {
+		t.Error("ResetPending() = false after ResetPrepare()")
+	}
+	if a.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after ResetPrepare() = %d, want 0", a.SizeInUse())
+	}
+
+	// New allocations must not overlap retired memory
+	fresh := a.AllocBytes(16)
+	copy(fresh, "new generation")
+	if string(old[:9]) != "in flight" {
+		t.Errorf("retired memory overwritten: %q", old[:9])
+	}
+
+	a.ResetCommit()
+	if a.ResetPending() {
+		t.Error("ResetPending() = true after ResetCommit()")
+	}
+	if len(a.spare) != 1 {
+		t.Fatalf("spare chunks after ResetCommit() = %d, want 1", len(a.spare))
+	}
+
+	// Next cycle reuses the recycled chunk instead of allocating
+	a.ResetPrepare()
+	if len(a.spare) != 0 {
+		t.Errorf("spare chunks after second ResetPrepare() = %d, want 0", len(a.spare))
+	}
+	a.ResetCommit()
+
+	// Growth also draws from spare chunks
+	a.AllocBytes(1000)
+	a.AllocBytes(1000)
+	if len(a.spare) != 0 {
+		t.Errorf("spare chunks after growth = %d, want 0", len(a.spare))
+	}
+}
+
+func TestTwoPhaseResetMisuse(t *testing.T) {
+	a := NewArena(1024)
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected panic on ResetCommit() without ResetPrepare()")
+			}
+		}()
+		a.ResetCommit()
+	}()
+
+	a.ResetPrepare()
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic on double ResetPrepare()")
+		}
+	}()
+	a.ResetPrepare()
+}
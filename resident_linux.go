@@ -0,0 +1,45 @@
+package arena
+
+import (
+	"encoding/binary"
+	"os"
+	"unsafe"
+)
+
+// pagemapPresent is the "page present in RAM" bit of a pagemap entry.
+const pagemapPresent = 1 << 63
+
+// residentBytes returns how many bytes of bufs are resident in RAM
+// according to /proc/self/pagemap, counting pages shared by two buffers or
+// extending past one in full, or -1 if the pagemap cannot be read.
+func residentBytes(bufs [][]byte) int {
+	f, err := os.Open("/proc/self/pagemap")
+	if err != nil {
+		return -1
+	}
+	defer f.Close()
+	page := uintptr(os.Getpagesize())
+	var entries []byte
+	total := 0
+	for _, b := range bufs {
+		if len(b) == 0 {
+			continue
+		}
+		start := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+		first, last := start/page, (start+uintptr(len(b))-1)/page
+		n := int(last-first) + 1
+		if cap(entries) < 8*n {
+			entries = make([]byte, 8*n)
+		}
+		entries = entries[:8*n]
+		if _, err := f.ReadAt(entries, int64(first)*8); err != nil {
+			return -1
+		}
+		for i := 0; i < n; i++ {
+			if binary.LittleEndian.Uint64(entries[8*i:])&pagemapPresent != 0 {
+				total += int(page)
+			}
+		}
+	}
+	return total
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package arena
+
+// residentBytes reports that residency is unknown on this platform.
+func residentBytes(bufs [][]byte) int {
+	return -1
+}
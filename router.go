@@ -0,0 +1,82 @@
+package arena
+
+// DefaultRouteThreshold is the default size boundary used by SizeRouter.
+const DefaultRouteThreshold = 1024
+
+// SizeRouter is an Allocator that sends allocations of at most threshold
+// bytes to a small-object allocator and larger ones to a separate large
+// allocator. Keeping sizes apart lets each side be reset on its own cadence,
+// so a few large buffers don't pin a chunk full of short-lived small objects.
+// SizeRouter is as goroutine-safe as the allocators it wraps.
+type SizeRouter struct {
+	small     Allocator
+	large     Allocator
+	threshold int
+}
+
+// NewSizeRouter creates a SizeRouter. If threshold <= 0,
+// DefaultRouteThreshold is used. A nil small or large allocator is replaced
+// by a new Arena with DefaultChunkSize.
+func NewSizeRouter(threshold int, small, large Allocator) *SizeRouter {
+	if threshold <= 0 {
+		threshold = DefaultRouteThreshold
+	}
+	if small == nil {
+		small = NewArena(0)
+	}
+	if large == nil {
+		large = NewArena(0)
+	}
+	return &SizeRouter{small: small, large: large, threshold: threshold}
+}
+
+// AllocBytes allocates n bytes from the small or large allocator.
+// Returns nil if n <= 0.
+func (r *SizeRouter) AllocBytes(n int) []byte {
+	if n <= 0 {
+		return nil
+	}
+	if n <= r.threshold {
+		return r.small.AllocBytes(n)
+	}
+	return r.large.AllocBytes(n)
+}
+
+// Reset resets both allocators.
+func (r *SizeRouter) Reset() {
+	r.small.Reset()
+	r.large.Reset()
+}
+
+// ResetSmall resets only the small-object allocator.
+func (r *SizeRouter) ResetSmall() {
+	r.small.Reset()
+}
+
+// ResetLarge resets only the large-object allocator.
+func (r *SizeRouter) ResetLarge() {
+	r.large.Reset()
+}
+
+// Release releases both allocators.
+func (r *SizeRouter) Release() {
+	r.small.Release()
+	r.large.Release()
+}
+
+// Threshold returns the largest size routed to the small allocator.
+func (r *SizeRouter) Threshold() int {
+	return r.threshold
+}
+
+// Small returns the small-object allocator.
+func (r *SizeRouter) Small() Allocator {
+	return r.small
+}
+
+// Large returns the large-object allocator.
+func (r *SizeRouter) Large() Allocator {
+	return r.large
+}
+
+var _ Allocator = (*SizeRouter)(nil)
@@ -0,0 +1,50 @@
+package arena
+
+import "testing"
+
+func TestSizeRouter(t *testing.T) {
+	small := NewArena(1024)
+	large := NewArena(8192)
+	r := NewSizeRouter(128, small, large)
+	defer r.Release()
+
+	r.AllocBytes(64)
+	r.AllocBytes(128)
+	r.AllocBytes(4096)
+
+	if small.SizeInUse() != 192 {
+		t.Errorf("small SizeInUse = %d, want 192", small.SizeInUse())
+	}
+	if large.SizeInUse() != 4096 {
+		t.Errorf("large SizeInUse = %d, want 4096", large.SizeInUse())
+	}
+	if r.AllocBytes(0) != nil {
+		t.Error("AllocBytes(0) should return nil")
+	}
+
+	// Independent reset cadences
+	r.ResetSmall()
+	if small.SizeInUse() != 0 || large.SizeInUse() != 4096 {
+		t.Errorf("after ResetSmall: small=%d large=%d, want 0 and 4096", small.SizeInUse(), large.SizeInUse())
+	}
+	r.AllocBytes(32)
+	r.ResetLarge()
+	if small.SizeInUse() != 32 || large.SizeInUse() != 0 {
+		t.Errorf("after ResetLarge: small=%d large=%d, want 32 and 0", small.SizeInUse(), large.SizeInUse())
+	}
+	r.Reset()
+	if small.SizeInUse() != 0 {
+		t.Errorf("after Reset: small=%d, want 0", small.SizeInUse())
+	}
+}
+
+func TestSizeRouterDefaults(t *testing.T) {
+	r := NewSizeRouter(0, nil, nil)
+	defer r.Release()
+	if r.Threshold() != DefaultRouteThreshold {
+		t.Errorf("Threshold() = %d, want %d", r.Threshold(), DefaultRouteThreshold)
+	}
+	if r.Small() == nil || r.Large() == nil {
+		t.Error("default allocators not created")
+	}
+}
@@ -2,48 +2,147 @@ package arena
 
 import (
 	"runtime"
-	"sync"
+	"sync/atomic"
 )
 
-// SafeArena is a mutex-protected wrapper around Arena for concurrent access.
-// All operations are thread-safe but come with the overhead of mutex locking.
+// SafeArena is a goroutine-safe wrapper around Arena. Small allocations
+// are served lock-free from per-shard blocks of arena memory (see
+// WithShards); everything else takes a lock around the underlying Arena.
+// Operations that only read, such as SafePtrAndKeepAlive, share the lock,
+// and metrics are read without it.
 type SafeArena struct {
-	mu sync.Mutex
-	a  *Arena
+	mu      safeLock
+	a       *Arena
+	epoch   atomic.Uint64   // incremented by every Reset
+	readers [2]atomic.Int64 // active BeginRead sections by epoch parity
+	frozen  atomic.Bool     // set by Freeze
+
+	shards       []shard // nil if sharding is disabled
+	shardMask    uint32
+	numShards    int          // requested shard count; set by WithShards
+	blockSize    int          // bytes carved from the arena per shard block
+	shardMax     int          // largest allocation served by the shards
+	shardLargest atomic.Int64 // largest allocation served by the shards so far
+
+	arenaOpts []Option // options for the underlying Arena; set by WithArenaOptions
+
+	stats safeStats // metrics published for lock-free reads
+}
+
+// SafeOption configures a SafeArena at construction time.
+type SafeOption func(*SafeArena)
+
+// WithSpinLock makes the SafeArena guard its critical sections with a
+// spinlock instead of sync.RWMutex. This can win when critical sections are
+// very short (a bump allocation is ~10ns) and contention is moderate, since
+// it avoids parking goroutines. The spinlock has no shared mode, so
+// read-only operations such as SafePtrAndKeepAlive no longer run in
+// parallel with each other. Under heavy oversubscription or read-mostly
+// use prefer the RWMutex.
+func WithSpinLock() SafeOption {
+	return func(s *SafeArena) {
+		s.mu.spin = true
+	}
+}
+
+// WithArenaOptions applies opts to the Arena underlying a SafeArena, so
+// it is configured as NewArena(chunkSize, opts...) would configure it.
+func WithArenaOptions(opts ...Option) SafeOption {
+	return func(s *SafeArena) {
+		s.arenaOpts = append(s.arenaOpts, opts...)
+	}
 }
 
 // NewSafeArena creates a new thread-safe arena with the specified chunk size.
 // If chunkSize <= 0, DefaultChunkSize is used.
-func NewSafeArena(chunkSize int) *SafeArena {
-	return &SafeArena{a: NewArena(chunkSize)}
+func NewSafeArena(chunkSize int, opts ...SafeOption) *SafeArena {
+	s := &SafeArena{numShards: runtime.GOMAXPROCS(0)}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.a = NewArena(chunkSize, s.arenaOpts...)
+	s.arenaOpts = nil
+	s.initShards()
+	s.publish()
+	return s
 }
 
 // AllocBytes thread-safely allocates n bytes and returns a slice pointing to them.
 // Returns nil if n <= 0.
 func (s *SafeArena) AllocBytes(n int) []byte {
+	s.checkWritable("AllocBytes")
+	if b := s.shardAlloc(n); b != nil {
+		return b
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	return s.a.AllocBytes(n)
 }
 
 // EnsureCapacity thread-safely ensures the current chunk has at least n free bytes.
 func (s *SafeArena) EnsureCapacity(n int) {
+	s.checkWritable("EnsureCapacity")
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	s.a.EnsureCapacity(n)
 }
 
-// Reset thread-safely resets allocation offsets to zero for arena reuse.
+// Grow thread-safely guarantees room for an n-byte allocation and returns the resulting capacity.
+func (s *SafeArena) Grow(n int) int {
+	s.checkWritable("Grow")
+	s.mu.Lock()
+	defer s.unlock()
+	return s.a.Grow(n)
+}
+
+// Reset thread-safely starts a new allocation cycle. It swaps in a fresh
+// chunk set and recycles the old one as soon as no reader from before the
+// Reset remains (see BeginRead), so readers are never invalidated. Without
+// readers the old set is recycled immediately.
 func (s *SafeArena) Reset() {
+	s.checkWritable("Reset")
+	s.mu.Lock()
+	defer s.unlock()
+	s.reset()
+}
+
+// TrimCold thread-safely frees cold empty chunks as Arena.TrimCold does.
+// Chunks that shards are allocating from hold data and are never freed,
+// nor is the chunk set retired by a Reset while its readers remain, so
+// trimming never touches memory in use. It holds the lock only while
+// unlinking chunks. Returns the number of bytes freed.
+func (s *SafeArena) TrimCold(minIdleResets uint64) int {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.a.Reset()
+	defer s.unlock()
+	return s.a.TrimCold(minIdleResets)
+}
+
+// ShrinkTo thread-safely frees empty chunks, coldest first, until the
+// capacity is at most capacity bytes, under the rules of TrimCold.
+// Returns the number of bytes freed.
+func (s *SafeArena) ShrinkTo(capacity int) int {
+	s.mu.Lock()
+	defer s.unlock()
+	return s.a.ShrinkTo(capacity)
+}
+
+// ResetAndShrink thread-safely resets the arena as Reset does and then
+// frees spare chunks and chunks beyond keepBytes. A chunk set still held
+// by readers is left alone and recycled by Reclaim as usual. Returns the
+// number of bytes freed.
+func (s *SafeArena) ResetAndShrink(keepBytes int) int {
+	s.checkWritable("ResetAndShrink")
+	s.mu.Lock()
+	defer s.unlock()
+	s.reset()
+	return s.a.shrinkAfterReset(keepBytes)
 }
 
 // Release thread-safely drops all chunks and makes the arena unusable.
 func (s *SafeArena) Release() {
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
+	s.dropShardBlocks()
 	s.a.Release()
 }
 
@@ -51,8 +150,12 @@ func (s *SafeArena) Release() {
 
 // SafeAlloc thread-safely returns a pointer to a T stored inside the arena with zeroed memory.
 func SafeAlloc[T any](s *SafeArena) *T {
+	s.checkWritable("SafeAlloc")
+	if v := shardSlice[T](s, 1, true); v != nil {
+		return &v[0]
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	return Alloc[T](s.a)
 }
 
@@ -63,29 +166,71 @@ func SafeAllocZeroed[T any](s *SafeArena) *T {
 
 // SafeAllocUninitialized thread-safely returns a *T without zeroing memory.
 func SafeAllocUninitialized[T any](s *SafeArena) *T {
+	s.checkWritable("SafeAllocUninitialized")
+	if v := shardSlice[T](s, 1, false); v != nil {
+		return &v[0]
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	return AllocUninitialized[T](s.a)
 }
 
 // SafeAllocSlice thread-safely allocates a slice of n elements of type T.
 func SafeAllocSlice[T any](s *SafeArena, n int) []T {
+	s.checkWritable("SafeAllocSlice")
+	if v := shardSlice[T](s, n, false); v != nil {
+		return v
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	return AllocSlice[T](s.a, n)
 }
 
 // SafeAllocSliceZeroed thread-safely allocates a slice of n elements with zeroed memory.
 func SafeAllocSliceZeroed[T any](s *SafeArena, n int) []T {
+	s.checkWritable("SafeAllocSliceZeroed")
+	if v := shardSlice[T](s, n, true); v != nil {
+		return v
+	}
 	s.mu.Lock()
-	defer s.mu.Unlock()
+	defer s.unlock()
 	return AllocSliceZeroed[T](s.a, n)
 }
 
 // SafePtrAndKeepAlive thread-safely returns t and calls runtime.KeepAlive on the arena.
+// Concurrent calls share the lock, and calls on a frozen arena take none.
 func SafePtrAndKeepAlive[T any](s *SafeArena, t *T) *T {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	if !s.frozen.Load() {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+	}
 	runtime.KeepAlive(s.a)
 	return t
 }
+
+// Freeze makes the arena read-only at the end of a build phase, so what
+// was built in it can be shared by any number of goroutines without
+// further locking. Afterwards allocating from the arena or resetting it
+// panics. Since readers of a frozen arena take no lock, Release cannot
+// wait for them: callers must make sure every reader is done, for example
+// with a sync.WaitGroup, before calling Release. Allocations racing with
+// Freeze may or may not succeed.
+func (s *SafeArena) Freeze() {
+	s.mu.Lock()
+	defer s.unlock()
+	s.a.panicIfReleased()
+	s.dropShardBlocks()
+	s.frozen.Store(true)
+}
+
+// Frozen reports whether Freeze has been called.
+func (s *SafeArena) Frozen() bool {
+	return s.frozen.Load()
+}
+
+// checkWritable panics if the arena is frozen.
+func (s *SafeArena) checkWritable(op string) {
+	if s.frozen.Load() {
+		panic("arena: " + op + " on a frozen SafeArena")
+	}
+}
@@ -2,6 +2,7 @@ package arena
 
 import (
 	"runtime"
+	"strings"
 	"sync"
 	"testing"
 )
@@ -43,6 +44,9 @@ func TestSafeArenaOperations(t *testing.T) {
 	}
 
 	s.EnsureCapacity(200)
+	if got := s.Grow(2000); got != 1024+2000 {
+		t.Errorf("Grow(2000) = %d, want %d", got, 1024+2000)
+	}
 	s.Reset()
 	if s.SizeInUse() != 0 {
 		t.Error("Expected zero size in use after Reset")
@@ -267,3 +271,200 @@ func BenchmarkSafeArenaConcurrent(b *testing.B) {
 		}
 	})
 }
+
+func TestSafeArenaShards(t *testing.T) {
+	s := NewSafeArena(1<<16, WithShards(4))
+	if len(s.shards) != 4 {
+		t.Fatalf("len(shards) = %d, want 4", len(s.shards))
+	}
+
+	const workers, perWorker = 8, 500
+	results := make([][][]byte, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				b := s.AllocBytes(24)
+				for j := range b {
+					b[j] = byte(w)
+				}
+				results[w] = append(results[w], b)
+			}
+		}()
+	}
+	wg.Wait()
+	for w, bufs := range results {
+		for _, b := range bufs {
+			for _, v := range b {
+				if v != byte(w) {
+					t.Fatalf("allocation of worker %d overwritten by worker %d", w, v)
+				}
+			}
+		}
+	}
+
+	m := s.Metrics()
+	// Tails of filled blocks count as used, like the tail of a full chunk.
+	if want := workers * perWorker * 24; m.SizeInUse < want || m.SizeInUse > want+want/50 {
+		t.Errorf("SizeInUse = %d, want about %d", m.SizeInUse, want)
+	}
+	if m.TotalAllocs != workers*perWorker {
+		t.Errorf("TotalAllocs = %d, want %d", m.TotalAllocs, workers*perWorker)
+	}
+
+	s.Reset()
+	if s.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after Reset = %d, want 0", s.SizeInUse())
+	}
+}
+
+func TestSafeArenaShardAccounting(t *testing.T) {
+	s := NewSafeArena(1<<16, WithShards(4))
+	s.AllocBytes(24)
+	s.AllocBytes(40)
+	SafeAllocSlice[int64](s, 3)
+
+	m := s.Metrics()
+	if m.TotalAllocs != 3 {
+		t.Errorf("TotalAllocs = %d, want 3", m.TotalAllocs)
+	}
+	if m.LargestAllocation != 40 {
+		t.Errorf("LargestAllocation = %d, want 40", m.LargestAllocation)
+	}
+	if m.SizeInUse < 88 || m.BytesWasted != m.SizeInUse-88 {
+		t.Errorf("SizeInUse, BytesWasted = %d, %d, want padding only", m.SizeInUse, m.BytesWasted)
+	}
+
+	s.Reset()
+	s.AllocBytes(8)
+	if m := s.Metrics(); m.BytesWasted != 0 {
+		t.Errorf("BytesWasted after Reset = %d, want 0", m.BytesWasted)
+	}
+}
+
+func TestSafeArenaArenaOptions(t *testing.T) {
+	s := NewSafeArena(1<<16, WithShards(4), WithArenaOptions(WithMaxAllocSize(64)))
+	if s.shards == nil {
+		t.Fatal("WithMaxAllocSize disabled the shards")
+	}
+	s.AllocBytes(64)
+	if msg := panicMessage(func() { s.AllocBytes(65) }); !strings.Contains(msg, ErrAllocTooLarge.Error()) {
+		t.Errorf("AllocBytes over the limit panicked with %q, want %v", msg, ErrAllocTooLarge)
+	}
+	if msg := panicMessage(func() { SafeAllocSlice[int64](s, 9) }); !strings.Contains(msg, ErrAllocTooLarge.Error()) {
+		t.Errorf("SafeAllocSlice over the limit panicked with %q, want %v", msg, ErrAllocTooLarge)
+	}
+
+	// The hook needs every allocation counted, so it keeps the locked path.
+	calls := 0
+	s = NewSafeArena(1<<16, WithShards(4), WithArenaOptions(WithAllocBudgetHook(10, func() { calls++ })))
+	if s.shards != nil {
+		t.Error("WithAllocBudgetHook kept the shards")
+	}
+	for range 100 {
+		s.AllocBytes(16)
+	}
+	if calls != 10 {
+		t.Errorf("alloc hook ran %d times, want 10", calls)
+	}
+}
+
+func TestSafeArenaWithoutShards(t *testing.T) {
+	s := NewSafeArena(1024, WithShards(0))
+	if s.shards != nil {
+		t.Fatal("WithShards(0) kept shards")
+	}
+	s.AllocBytes(10)
+	if s.SizeInUse() != 10 {
+		t.Errorf("SizeInUse = %d, want 10", s.SizeInUse())
+	}
+}
+
+func TestSafeArenaTrim(t *testing.T) {
+	s := NewSafeArena(1024, WithShards(0))
+	for i := 0; i < 4; i++ {
+		s.AllocBytes(1000)
+	}
+	s.Reset()
+	if freed := s.TrimCold(1); freed != 4*1024 {
+		t.Errorf("TrimCold(1) freed %d bytes, want %d", freed, 4*1024)
+	}
+	if m := s.Metrics(); m.Trims != 1 || m.TrimmedBytes != 4*1024 {
+		t.Errorf("Trims, TrimmedBytes = %d, %d, want 1, %d", m.Trims, m.TrimmedBytes, 4*1024)
+	}
+
+	// A chunk set held by a reader survives ResetAndShrink.
+	for i := 0; i < 4; i++ {
+		s.AllocBytes(1000)
+	}
+	b := s.AllocBytes(4)
+	copy(b, "held")
+	r := s.BeginRead()
+	s.ResetAndShrink(0)
+	if s.Capacity() != 1024 {
+		t.Errorf("Capacity after ResetAndShrink(0) = %d, want 1024", s.Capacity())
+	}
+	for i := 0; i < 4; i++ {
+		clear(s.AllocBytes(1000))
+	}
+	if string(b) != "held" {
+		t.Errorf("reader memory = %q, want %q", b, "held")
+	}
+	s.EndRead(r)
+	if !s.Reclaim() {
+		t.Error("Reclaim() = false after the reader ended")
+	}
+}
+
+func TestSafeArenaFreeze(t *testing.T) {
+	s := NewSafeArena(1024)
+	v := SafeAlloc[int64](s)
+	*v = 42
+	s.Freeze()
+	if !s.Frozen() {
+		t.Fatal("Frozen() = false after Freeze")
+	}
+
+	// Readers share the arena without locking.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if *SafePtrAndKeepAlive(s, v) != 42 || s.SizeInUse() == 0 {
+					t.Error("frozen arena lost its contents")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for name, f := range map[string]func(){
+		"AllocBytes": func() { s.AllocBytes(8) },
+		"SafeAlloc":  func() { SafeAlloc[int64](s) },
+		"Reset":      func() { s.Reset() },
+	} {
+		if msg := panicMessage(f); !strings.Contains(msg, name+" on a frozen SafeArena") {
+			t.Errorf("%s on a frozen arena panicked with %q", name, msg)
+		}
+	}
+	s.Release()
+}
+
+func TestSafeArenaSharedReadLock(t *testing.T) {
+	s := NewSafeArena(1024)
+	v := SafeAlloc[int64](s)
+	s.mu.RLock()
+	done := make(chan struct{})
+	go func() {
+		SafePtrAndKeepAlive(s, v)
+		s.ResetRetiring()
+		close(done)
+	}()
+	<-done // would deadlock if readers excluded each other
+	s.mu.RUnlock()
+}
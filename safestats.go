@@ -0,0 +1,135 @@
+package arena
+
+import (
+	"runtime"
+	"sync/atomic"
+	"time"
+)
+
+// safeStats publishes a SafeArena's metrics so they can be read without
+// taking the lock. Every locked operation republishes on unlock: the
+// figures that change with each allocation are stored in atomics, and the
+// rest, which only change when the arena adds a chunk, moves to another
+// chunk or is reset, in an immutable safeSnapshot that is replaced then.
+// seq makes the set consistent, seqlock style: it is odd while a publish
+// is in progress, and readers retry if it changed under them.
+type safeStats struct {
+	seq       atomic.Uint64
+	snap      atomic.Pointer[safeSnapshot]
+	inUse     atomic.Int64
+	allocs    atomic.Uint64
+	requested atomic.Int64
+
+	key safeSnapshotKey // what snap was computed from; s.mu must be held
+}
+
+// safeSnapshotKey identifies the arena state a safeSnapshot describes.
+type safeSnapshotKey struct {
+	cur            *chunk
+	chunks         int
+	grows, resets  uint64
+	trims          uint64
+	largest, peak  int
+	pendingRetired bool
+}
+
+// safeSnapshot holds the metrics that stay fixed between chunk changes and
+// what is needed to derive the rest from the published counters.
+type safeSnapshot struct {
+	m           ArenaMetrics
+	prefix      int // SizeInUse of every chunk but the current one
+	headers     int // bytes reserved at the start of chunks
+	template    int // Preload template bytes
+	tails       int // chunk tails skipped this cycle
+	cycleAllocs uint64
+	retired     uint64 // bytes used by cycles that ended
+}
+
+// unlock publishes the arena's metrics and releases s.mu. Locked paths
+// that may change the arena use it in place of s.mu.Unlock.
+func (s *SafeArena) unlock() {
+	s.publish()
+	s.mu.Unlock()
+}
+
+// publish makes the arena's current metrics visible to lock-free readers.
+// s.mu must be held.
+func (s *SafeArena) publish() {
+	a, st := s.a, &s.stats
+	st.seq.Add(1)
+	if key := s.snapshotKey(); st.snap.Load() == nil || key != st.key {
+		m := a.Metrics() // may raise the peak, so take the key afterwards
+		st.key = s.snapshotKey()
+		snap := &safeSnapshot{
+			m:           m,
+			prefix:      m.SizeInUse,
+			headers:     int(a.chunkBase) * len(a.chunks),
+			template:    len(a.template),
+			tails:       a.chunkTails(),
+			cycleAllocs: a.cycleAllocs,
+			retired:     a.retiredBytes,
+		}
+		if c := a.currentChunk; c != nil && a.chunks != nil {
+			snap.prefix -= int(c.offset)
+		}
+		st.snap.Store(snap)
+	}
+	inUse := st.snap.Load().prefix
+	if c := a.currentChunk; c != nil && a.chunks != nil {
+		inUse += int(c.offset)
+	}
+	st.inUse.Store(int64(inUse))
+	st.allocs.Store(a.allocs)
+	st.requested.Store(int64(a.requested))
+	st.seq.Add(1)
+}
+
+// snapshotKey returns the key of the arena's current state. s.mu must be
+// held.
+func (s *SafeArena) snapshotKey() safeSnapshotKey {
+	a := s.a
+	return safeSnapshotKey{
+		cur:            a.currentChunk,
+		chunks:         len(a.chunks),
+		grows:          a.grows,
+		resets:         a.resets,
+		trims:          a.trims,
+		largest:        a.largest,
+		peak:           a.peak,
+		pendingRetired: a.retired != nil,
+	}
+}
+
+// loadMetrics returns the published metrics, retrying until it reads a
+// consistent set.
+func (s *SafeArena) loadMetrics() ArenaMetrics {
+	st := &s.stats
+	for {
+		seq := st.seq.Load()
+		if seq&1 != 0 {
+			runtime.Gosched() // a publish is in progress
+			continue
+		}
+		snap := st.snap.Load()
+		inUse := int(st.inUse.Load())
+		allocs := st.allocs.Load()
+		requested := int(st.requested.Load())
+		if st.seq.Load() != seq {
+			continue
+		}
+		m := snap.m
+		used := max(inUse-snap.headers, 0)
+		m.SizeInUse = inUse
+		m.TotalAllocs = allocs
+		m.TotalBytes = snap.retired + uint64(used)
+		m.NumAllocations = int(allocs - snap.cycleAllocs)
+		m.BytesWasted = max(used-snap.template-requested, 0) + snap.tails
+		m.PeakSizeInUse = max(m.PeakSizeInUse, inUse)
+		m.Utilization = 0
+		if m.Capacity > 0 {
+			m.Utilization = float64(inUse) / float64(m.Capacity)
+		}
+		m.Time = time.Now()
+		return m
+	}
+}
@@ -0,0 +1,110 @@
+package arena
+
+import "math/bits"
+
+// sampleWindow is the number of most recent samples WithAccessSampling
+// keeps: one bit per sample in each chunk's hits.
+const sampleWindow = 64
+
+// WithAccessSampling records, at every every-th reset, which chunks held
+// data in the cycle that just ended and how many bytes it used, keeping the
+// last 64 samples. WorkingSet turns the samples into an estimate of the
+// memory the arena actually needs, as opposed to the capacity it retains;
+// ShrinkTo and ResetAndShrink free chunks that were not used in any sample
+// first, and Diagnose reports retained capacity outside the working set.
+// Sampling costs one pass over the chunks per sampled reset. every < 1 is
+// treated as 1.
+func WithAccessSampling(every int) Option {
+	return func(a *Arena) {
+		a.sampler = &accessSampler{every: max(every, 1)}
+	}
+}
+
+// accessSampler holds the per-arena state of WithAccessSampling; the
+// per-chunk state is chunk.hits.
+type accessSampler struct {
+	every   int
+	pending int // resets since the last sample
+	used    [sampleWindow]int
+	next    int
+	n       int // samples taken, up to sampleWindow
+}
+
+// sampleAccess samples the cycle ending now if it is due.
+func (a *Arena) sampleAccess() {
+	s := a.sampler
+	if s == nil {
+		return
+	}
+	if s.pending++; s.pending < s.every {
+		return
+	}
+	s.pending = 0
+	for i := range a.chunks {
+		c := &a.chunks[i]
+		c.hits <<= 1
+		if c.offset > a.chunkBase {
+			c.hits |= 1
+		}
+	}
+	for i := range a.spare {
+		a.spare[i].hits <<= 1
+	}
+	s.used[s.next] = a.usedBytes(a.chunks)
+	s.next = (s.next + 1) % sampleWindow
+	s.n = min(s.n+1, sampleWindow)
+}
+
+// WorkingSet estimates how much of an arena's memory is in use.
+type WorkingSet struct {
+	Bytes    int // Capacity of the chunks that held data in any sampled cycle
+	Peak     int // Most bytes used by a sampled cycle
+	Capacity int // Chunk memory retained, including chunks kept for reuse
+	Resident int // Bytes of Capacity backed by physical memory; -1 if unknown
+	Samples  int // Cycles sampled, up to 64
+}
+
+// WorkingSet returns the arena's working set as sampled by
+// WithAccessSampling over its last 64 samples; without it, or before the
+// first sample, Bytes, Peak and Samples are 0. Resident is read from
+// /proc/self/pagemap on Linux, where pages of chunks that were never
+// written are typically not resident yet, and is -1 elsewhere or if the
+// file cannot be read. It is meant for occasional monitoring: computing
+// Resident reads 8 bytes per page of capacity.
+func (a *Arena) WorkingSet() WorkingSet {
+	ws := a.sampledWorkingSet()
+	bufs := make([][]byte, 0, len(a.chunks)+len(a.spare))
+	for _, set := range [][]chunk{a.chunks, a.spare} {
+		for _, c := range set {
+			bufs = append(bufs, c.buf)
+		}
+	}
+	ws.Resident = residentBytes(bufs)
+	return ws
+}
+
+// sampledWorkingSet returns the working set without Resident, which is
+// left at -1.
+func (a *Arena) sampledWorkingSet() WorkingSet {
+	ws := WorkingSet{Resident: -1}
+	for _, set := range [][]chunk{a.chunks, a.spare} {
+		for _, c := range set {
+			ws.Capacity += len(c.buf)
+			if c.hits != 0 {
+				ws.Bytes += len(c.buf)
+			}
+		}
+	}
+	if s := a.sampler; s != nil {
+		ws.Samples = s.n
+		for _, n := range s.used[:s.n] {
+			ws.Peak = max(ws.Peak, n)
+		}
+	}
+	return ws
+}
+
+// sampledHits returns the number of samples in which c held data.
+func (c *chunk) sampledHits() int {
+	return bits.OnesCount64(c.hits)
+}
@@ -0,0 +1,96 @@
+package arena
+
+import "testing"
+
+func TestAccessSampling(t *testing.T) {
+	a := NewArena(1024, WithAccessSampling(2))
+	a.AllocBytes(100)
+	a.Reset() // not sampled
+	for range 3 {
+		a.AllocBytes(1000) // a spike over three chunks, sampled
+	}
+	a.Reset()
+	for range 2 {
+		a.AllocBytes(100)
+		a.Reset()
+	}
+
+	ws := a.WorkingSet()
+	if ws.Samples != 2 || ws.Peak != 3000 || ws.Capacity != a.Capacity() {
+		t.Errorf("WorkingSet = %+v, want 2 samples peaking at 3000 bytes of %d", ws, a.Capacity())
+	}
+	if ws.Bytes != ws.Capacity {
+		t.Errorf("WorkingSet.Bytes = %d, want %d while the spike is in the window", ws.Bytes, ws.Capacity)
+	}
+	if infos := a.ChunkInfos(); infos[0].Hits != 2 || infos[1].Hits != 1 || infos[2].Hits != 1 {
+		t.Errorf("ChunkInfos = %+v, want Hits 2, 1, 1", infos)
+	}
+
+	// Once the spike leaves the window only the first chunk is working.
+	for range 2 * sampleWindow {
+		a.AllocBytes(100)
+		a.Reset()
+	}
+	ws = a.WorkingSet()
+	if ws.Samples != sampleWindow || ws.Peak != 100 || ws.Bytes != len(a.chunks[0].buf) {
+		t.Errorf("WorkingSet = %+v, want %d samples, peak 100 and the first chunk", ws, sampleWindow)
+	}
+	found := false
+	for _, f := range a.Diagnose() {
+		found = found || f.Kind == FindingColdCapacity
+	}
+	if !found {
+		t.Errorf("Diagnose() = %v, want a cold-capacity finding", a.Diagnose())
+	}
+}
+
+func TestAccessSamplingShrinkOrder(t *testing.T) {
+	for _, sampled := range []bool{false, true} {
+		var opts []Option
+		if sampled {
+			opts = append(opts, WithAccessSampling(1))
+		}
+		a := NewArena(1024, opts...)
+		a.AllocBytes(1000)
+		a.AllocBytes(1000) // chunk 1, 1024 bytes
+		a.AllocBytes(2000) // chunk 2, used by every cycle below
+		for range 8 {
+			a.Reset()
+			a.AllocBytes(1000)
+			a.AllocBytes(2000) // skips chunk 1
+		}
+		a.Reset()
+		a.AllocBytes(1000)
+		a.AllocBytes(1000) // chunk 1 again, so chunk 2 was idle longer
+		a.Reset()
+
+		a.ShrinkTo(a.Capacity() - 1)
+		// Unsampled, the longest-idle chunk goes; sampled, the one used
+		// in the fewest cycles does.
+		want := 1024
+		if !sampled {
+			want = 2000
+		}
+		if got := a.ChunkInfos(); len(got) != 2 || got[1].Size == want {
+			t.Errorf("sampled=%v: chunks after ShrinkTo = %+v, want the %d-byte chunk freed", sampled, got, want)
+		}
+	}
+}
+
+func TestWorkingSetResident(t *testing.T) {
+	a := NewArena(1 << 20)
+	ws := a.WorkingSet()
+	if ws.Resident == -1 {
+		t.Skip("residency unknown on this platform")
+	}
+	if ws.Resident < 0 || ws.Resident > ws.Capacity+2*4096*len(a.chunks) {
+		t.Errorf("Resident = %d for %d bytes of capacity", ws.Resident, ws.Capacity)
+	}
+	b := a.AllocBytes(1 << 19)
+	for i := range b {
+		b[i] = 1
+	}
+	if got := a.WorkingSet().Resident; got < 1<<19 {
+		t.Errorf("Resident = %d after writing %d bytes, want at least that", got, 1<<19)
+	}
+}
@@ -0,0 +1,72 @@
+package arena
+
+import "unsafe"
+
+// Scratch allocates a temporary n-byte buffer and returns it together with a
+// function that gives the space back. The space is reclaimed only if the
+// buffer is still the most recent allocation and the arena has not been
+// reset since; otherwise the release function does nothing and the memory is
+// recovered at the next Reset. Typical use:
+//
+//	buf, done := a.Scratch(4096)
+//	defer done()
+//
+// The buffer must not be used after the release function runs.
+func (a *Arena) Scratch(n int) ([]byte, func()) {
+	b, m := a.allocScratch(n)
+	return b, func() { a.releaseScratch(m) }
+}
+
+// scratchMark records the bump pointer around a scratch allocation.
+type scratchMark struct {
+	c    *chunk  // chunk the scratch was served from; nil if not reclaimable
+	mark uintptr // offset before the allocation
+	end  uintptr // offset after the allocation
+	gen  uint64
+	prev int // chunk to make current again on release; -1 to keep c
+	n    int // bytes requested
+}
+
+// allocScratch allocates n bytes and returns a mark for releaseScratch.
+// Internal callers use it directly to avoid allocating a closure.
+func (a *Arena) allocScratch(n int) ([]byte, scratchMark) {
+	c := a.currentChunk
+	prev := a.chunkIndex(c)
+	var mark uintptr
+	if c != nil {
+		mark = c.offset
+	}
+	b := a.AllocBytes(n)
+	if b == nil {
+		return b, scratchMark{}
+	}
+	if a.currentChunk == c {
+		return b, scratchMark{c: c, mark: mark, end: c.offset, gen: a.generation, prev: -1, n: n}
+	}
+	// Served from the next chunk: releasing rewinds that chunk and makes
+	// the previous one current again, so a following scratch of similar
+	// size lands on exactly the same memory.
+	c = a.currentChunk
+	start := uintptr(unsafe.Pointer(&b[0])) - uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+	return b, scratchMark{c: c, mark: start, end: c.offset, gen: a.generation, prev: prev, n: n}
+}
+
+// releaseScratch rewinds the bump pointer to m if the scratch allocation is
+// still the tail of the current chunk.
+func (a *Arena) releaseScratch(m scratchMark) {
+	c := m.c
+	if c == nil || a.generation != m.gen || a.currentChunk != c || c.offset != m.end {
+		return
+	}
+	if a.debug != nil && a.debug.canaries {
+		// The canary guard would be overwritten by the next allocation.
+		return
+	}
+	a.notePeak()
+	a.requested -= m.n
+	c.offset = m.mark
+	c.zeroed = false
+	if m.prev >= 0 && m.prev < len(a.chunks) {
+		a.currentChunk = &a.chunks[m.prev]
+	}
+}
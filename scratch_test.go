@@ -0,0 +1,76 @@
+package arena
+
+import "testing"
+
+func TestScratchReclaimsTail(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(10)
+	before := a.SizeInUse()
+
+	buf, done := a.Scratch(256)
+	if len(buf) != 256 {
+		t.Fatalf("Scratch(256) length = %d, want 256", len(buf))
+	}
+	done()
+	if a.SizeInUse() != before {
+		t.Errorf("SizeInUse after release = %d, want %d", a.SizeInUse(), before)
+	}
+}
+
+func TestScratchNotTail(t *testing.T) {
+	a := NewArena(1024)
+
+	_, done := a.Scratch(64)
+	a.AllocBytes(8) // scratch is no longer the tail
+	used := a.SizeInUse()
+	done()
+	if a.SizeInUse() != used {
+		t.Errorf("SizeInUse = %d, want %d (non-tail scratch must not be reclaimed)", a.SizeInUse(), used)
+	}
+
+	// Release after Reset is a no-op
+	_, done = a.Scratch(64)
+	a.Reset()
+	a.AllocBytes(16)
+	done()
+	if a.SizeInUse() != 16 {
+		t.Errorf("SizeInUse = %d, want 16 after stale release", a.SizeInUse())
+	}
+}
+
+func TestScratchNewChunk(t *testing.T) {
+	a := NewArena(128)
+	buf, done := a.Scratch(512)
+	if len(buf) != 512 {
+		t.Fatalf("Scratch(512) length = %d, want 512", len(buf))
+	}
+	done()
+
+	if _, done := a.Scratch(0); done == nil {
+		t.Error("Scratch(0) returned nil release func")
+	}
+}
+
+func TestScratchNewChunkReused(t *testing.T) {
+	a := NewArena(1024)
+	a.AllocBytes(900)
+
+	buf, done := a.Scratch(512)
+	done()
+	if a.SizeInUse() != 900 {
+		t.Errorf("SizeInUse after release = %d, want 900", a.SizeInUse())
+	}
+	again, done := a.Scratch(512)
+	done()
+	if &again[0] != &buf[0] {
+		t.Error("scratch after a spilled release did not reuse the same memory")
+	}
+	if a.NumChunks() != 2 {
+		t.Errorf("NumChunks = %d, want 2", a.NumChunks())
+	}
+
+	// The previous chunk stays current for small allocations
+	if b := a.AllocBytes(16); &b[0] == &buf[0] {
+		t.Error("small allocation after release went to the spill chunk")
+	}
+}
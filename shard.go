@@ -0,0 +1,195 @@
+package arena
+
+import (
+	"math/bits"
+	"math/rand/v2"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Shard block sizing. Blocks are carved from the arena under the lock and
+// then bumped lock-free; allocations larger than a quarter block bypass the
+// shards so the tail wasted when a block is replaced stays small.
+const (
+	minShardBlock = 256
+	maxShardBlock = 64 << 10
+)
+
+// WithShards sets the number of allocation shards of a SafeArena. Each
+// shard holds a block of arena memory that AllocBytes and the SafeAlloc
+// functions bump with atomic operations; the lock is only taken to carve
+// a new block when one fills up. The default is GOMAXPROCS. n <= 0
+// disables sharding so every allocation takes the lock.
+func WithShards(n int) SafeOption {
+	return func(s *SafeArena) {
+		s.numShards = n
+	}
+}
+
+// shard is one lock-free allocation slot. Goroutines pick a shard at
+// random, which spreads them over the shards much like per-P caches
+// without needing to know the current P.
+type shard struct {
+	blk       atomic.Pointer[shardBlock]
+	allocs    atomic.Uint64 // allocations served from this shard's blocks
+	requested atomic.Int64  // bytes requested from this shard this cycle
+	_         [40]byte      // keep shards on separate cache lines
+}
+
+// shardBlock is a block of arena memory bumped by an atomic offset.
+type shardBlock struct {
+	buf []byte
+	off atomic.Uintptr
+}
+
+// alloc bumps n bytes off the block, or returns nil if it is full.
+func (b *shardBlock) alloc(n int) []byte {
+	for {
+		off := b.off.Load()
+		start := alignPtr(off)
+		end := start + uintptr(n)
+		if end > uintptr(len(b.buf)) {
+			return nil
+		}
+		if b.off.CompareAndSwap(off, end) {
+			return b.buf[start:end:end]
+		}
+	}
+}
+
+// initShards sets up the shards once options are applied. Arenas with
+// diagnostics or an allocation hook keep the locked path so every
+// allocation is seen, and the hook runs at its exact count. Sizes over
+// the WithMaxAllocSize limit are never eligible, so the locked path
+// rejects them as usual.
+func (s *SafeArena) initShards() {
+	if s.numShards <= 0 || s.a.debug != nil || s.a.allocHook != nil {
+		s.numShards = 0
+		return
+	}
+	n := 1 << bits.Len(uint(s.numShards-1)) // round up to a power of two
+	s.shards = make([]shard, n)
+	s.shardMask = uint32(n - 1)
+	s.blockSize = min(max(s.a.chunkSize/16, minShardBlock), maxShardBlock, s.a.chunkSize)
+	s.shardMax = min(s.blockSize/4, s.a.maxAlloc)
+}
+
+// shardAlloc serves n bytes from a shard, or returns nil if n is not
+// eligible for the shards.
+func (s *SafeArena) shardAlloc(n int) []byte {
+	if s.shards == nil || n <= 0 || n > s.shardMax {
+		return nil
+	}
+	sh := &s.shards[rand.Uint32()&s.shardMask]
+	if blk := sh.blk.Load(); blk != nil {
+		if b := blk.alloc(n); b != nil {
+			s.countShardAlloc(sh, n)
+			return b
+		}
+	}
+	return s.refillShard(sh, n)
+}
+
+// countShardAlloc records an n-byte allocation served by sh, as
+// countAlloc does for the locked path.
+func (s *SafeArena) countShardAlloc(sh *shard, n int) {
+	sh.allocs.Add(1)
+	sh.requested.Add(int64(n))
+	for {
+		largest := s.shardLargest.Load()
+		if int64(n) <= largest || s.shardLargest.CompareAndSwap(largest, int64(n)) {
+			return
+		}
+	}
+}
+
+// refillShard carves a new block for sh from the arena and allocates n
+// bytes from it, unless another goroutine refilled sh first.
+func (s *SafeArena) refillShard(sh *shard, n int) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b := []byte(nil)
+	if blk := sh.blk.Load(); blk != nil {
+		b = blk.alloc(n)
+	}
+	if b == nil {
+		blk := &shardBlock{buf: s.carveBlock()}
+		b = blk.alloc(n)
+		// Publish the carve before the block so readers never subtract
+		// its free tail from a size that does not include it yet.
+		s.publish()
+		sh.blk.Store(blk)
+	}
+	s.countShardAlloc(sh, n)
+	return b
+}
+
+// carveBlock takes a block for a shard from the arena. The block is not
+// counted as an allocation; the allocations served from it are counted
+// per shard instead. s.mu must be held.
+func (s *SafeArena) carveBlock() []byte {
+	a := s.a
+	b := a.bump(s.blockSize)
+	if b == nil {
+		a.panicIfReleased()
+		a.advanceChunk(s.blockSize)
+		b = a.bump(s.blockSize)
+	}
+	return b
+}
+
+// dropShardBlocks detaches every shard from its block, so allocations
+// after a Reset or Release are carved from the new chunk set, and starts
+// the per-cycle counters over. s.mu must be held.
+func (s *SafeArena) dropShardBlocks() {
+	for i := range s.shards {
+		s.shards[i].blk.Store(nil)
+		s.shards[i].requested.Store(0)
+	}
+}
+
+// adjustShardMetrics adds the allocations served by the shards to arena
+// metrics, which see carved blocks only as used bytes: the unused tails
+// of blocks are not in use, and the bytes requested from blocks are not
+// wasted. It only reads atomics and needs no lock.
+func (s *SafeArena) adjustShardMetrics(m *ArenaMetrics) {
+	if s.shards == nil {
+		return
+	}
+	notWasted := 0
+	for i := range s.shards {
+		sh := &s.shards[i]
+		m.TotalAllocs += sh.allocs.Load()
+		notWasted += int(sh.requested.Load())
+		if blk := sh.blk.Load(); blk != nil {
+			tail := len(blk.buf) - int(blk.off.Load())
+			m.SizeInUse -= tail
+			notWasted += tail
+		}
+	}
+	m.BytesWasted = max(m.BytesWasted-notWasted, 0)
+	m.LargestAllocation = max(m.LargestAllocation, int(s.shardLargest.Load()))
+	m.Utilization = 0
+	if m.Capacity > 0 {
+		m.Utilization = float64(m.SizeInUse) / float64(m.Capacity)
+	}
+}
+
+// shardSlice allocates n values of T from the shards, zeroed if zero is
+// set, or returns nil if the allocation is not eligible for the shards.
+func shardSlice[T any](s *SafeArena, n int, zero bool) []T {
+	var v T
+	size := int(unsafe.Sizeof(v))
+	if n <= 0 || size == 0 || n > s.blockSize/size {
+		return nil
+	}
+	checkPointers[T]()
+	b := s.shardAlloc(size * n)
+	if b == nil {
+		return nil
+	}
+	if zero {
+		clear(b)
+	}
+	return unsafe.Slice((*T)(unsafe.Pointer(&b[0])), n)
+}
@@ -0,0 +1,134 @@
+package arena
+
+import (
+	"cmp"
+	"slices"
+	"unsafe"
+)
+
+// insertionSortThreshold is the run length below which stable sorting
+// falls back to insertion sort.
+const insertionSortThreshold = 12
+
+// SortSlice sorts s in ascending order. The sort is stable and uses a
+// scratch buffer taken from the arena, which is handed back afterwards when
+// possible, so no heap temporaries are created.
+func SortSlice[T cmp.Ordered](a *Arena, s []T) {
+	SortSliceFunc(a, s, cmp.Compare[T])
+}
+
+// SortSliceFunc stably sorts s using cmp, like slices.SortStableFunc, but
+// with an O(n log n) merge sort backed by an arena scratch buffer. Types
+// containing Go pointers (see HasPointers) are sorted with
+// slices.SortStableFunc instead, since the GC cannot see copies of them
+// held in arena memory.
+func SortSliceFunc[T any](a *Arena, s []T, cmp func(x, y T) int) {
+	if len(s) <= insertionSortThreshold {
+		insertionSort(s, cmp)
+		return
+	}
+	if HasPointers[T]() {
+		slices.SortStableFunc(s, cmp)
+		return
+	}
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	if size == 0 {
+		return
+	}
+	b, m := a.allocScratch(size * len(s))
+	buf := unsafe.Slice((*T)(unsafe.Pointer(&b[0])), len(s))
+	mergeSort(s, buf, cmp)
+	clear(buf)
+	a.releaseScratch(m)
+}
+
+// Compact replaces consecutive runs of equal elements with a single copy,
+// in place. It is slices.Compact, re-exported so arena slice helpers live in
+// one package.
+func Compact[T comparable](s []T) []T {
+	return slices.Compact(s)
+}
+
+// CompactFunc is like Compact but uses eq to compare elements.
+func CompactFunc[T any](s []T, eq func(x, y T) bool) []T {
+	return slices.CompactFunc(s, eq)
+}
+
+// Unique sorts s and removes duplicate elements in place, returning the
+// shortened slice.
+func Unique[T cmp.Ordered](a *Arena, s []T) []T {
+	SortSlice(a, s)
+	return slices.Compact(s)
+}
+
+// Append appends elems to s like the built-in append, but when s lacks
+// capacity it grows inside the arena instead of on the heap: in place if s
+// is the most recent allocation in the current chunk, otherwise by moving
+// to a larger arena allocation. Capacity at least doubles on each move.
+func Append[T any](a *Arena, s []T, elems ...T) []T {
+	if n := len(s) + len(elems); n > cap(s) {
+		s = GrowSlice(a, s, max(n, 2*cap(s)))
+	}
+	return append(s, elems...)
+}
+
+// GrowSlice returns s with capacity for at least newCap elements, growing
+// inside the arena like Append. The length is unchanged and elements
+// beyond it are not initialized.
+func GrowSlice[T any](a *Arena, s []T, newCap int) []T {
+	if newCap <= cap(s) {
+		return s
+	}
+	var zero T
+	size := int(unsafe.Sizeof(zero))
+	if size == 0 {
+		return slices.Grow(s, newCap-len(s))
+	}
+	if cap(s) > 0 {
+		b := unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(s))), cap(s)*size)
+		if ext, ok := a.extendInPlace(b, (newCap-cap(s))*size); ok {
+			return unsafe.Slice((*T)(unsafe.Pointer(&ext[0])), newCap)[:len(s)]
+		}
+	}
+	grown := AllocSlice[T](a, newCap)
+	return grown[:copy(grown, s)]
+}
+
+// mergeSort stably sorts s using buf (same length) as scratch space.
+func mergeSort[T any](s, buf []T, cmp func(x, y T) int) {
+	if len(s) <= insertionSortThreshold {
+		insertionSort(s, cmp)
+		return
+	}
+	mid := len(s) / 2
+	mergeSort(s[:mid], buf[:mid], cmp)
+	mergeSort(s[mid:], buf[mid:], cmp)
+	if cmp(s[mid-1], s[mid]) <= 0 {
+		return // halves already in order
+	}
+
+	copy(buf, s)
+	i, j, k := 0, mid, 0
+	for i < mid && j < len(s) {
+		if cmp(buf[j], buf[i]) < 0 {
+			s[k] = buf[j]
+			j++
+		} else {
+			s[k] = buf[i]
+			i++
+		}
+		k++
+	}
+	k += copy(s[k:], buf[i:mid])
+	copy(s[k:], buf[j:])
+}
+
+// insertionSort stably sorts a short slice in place.
+func insertionSort[T any](s []T, cmp func(x, y T) int) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && cmp(s[j], s[j-1]) < 0; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
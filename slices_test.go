@@ -0,0 +1,146 @@
+package arena
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSortSlice(t *testing.T) {
+	a := NewArena(1 << 16)
+	rng := rand.New(rand.NewSource(1))
+
+	for _, n := range []int{0, 1, 5, 12, 13, 100, 1000} {
+		s := make([]int, n)
+		for i := range s {
+			s[i] = rng.Intn(50)
+		}
+		SortSlice(a, s)
+		if !slices.IsSorted(s) {
+			t.Errorf("SortSlice(n=%d) not sorted", n)
+		}
+	}
+
+	// Scratch space is handed back
+	if a.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after sorting = %d, want 0", a.SizeInUse())
+	}
+}
+
+func TestSortSliceFuncStable(t *testing.T) {
+	type rec struct {
+		key, seq int
+	}
+	a := NewArena(1 << 16)
+	rng := rand.New(rand.NewSource(2))
+
+	s := make([]rec, 500)
+	for i := range s {
+		s[i] = rec{key: rng.Intn(10), seq: i}
+	}
+	SortSliceFunc(a, s, func(x, y rec) int { return x.key - y.key })
+
+	for i := 1; i < len(s); i++ {
+		if s[i-1].key > s[i].key || (s[i-1].key == s[i].key && s[i-1].seq > s[i].seq) {
+			t.Fatalf("not stably sorted at %d: %+v, %+v", i, s[i-1], s[i])
+		}
+	}
+}
+
+func TestCompactAndUnique(t *testing.T) {
+	a := NewArena(1024)
+
+	if got := Compact([]int{1, 1, 2, 2, 2, 1}); !slices.Equal(got, []int{1, 2, 1}) {
+		t.Errorf("Compact() = %v, want [1 2 1]", got)
+	}
+	if got := Unique(a, []string{"b", "a", "b", "c", "a"}); !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("Unique() = %v, want [a b c]", got)
+	}
+}
+
+func TestSortSlicePointers(t *testing.T) {
+	a := NewArena(1 << 16)
+	s := make([]string, 100)
+	for i := range s {
+		s[i] = fmt.Sprint(len(s) - i)
+	}
+	SortSlice(a, s)
+	if !slices.IsSortedFunc(s, strings.Compare) {
+		t.Error("SortSlice([]string) not sorted")
+	}
+	if m := a.Metrics(); m.LargestAllocation != 0 {
+		t.Errorf("LargestAllocation = %d after sorting strings, want 0 (no arena scratch)", m.LargestAllocation)
+	}
+}
+
+func BenchmarkSortSlice(b *testing.B) {
+	a := NewArena(1 << 20)
+	src := make([]int, 4096)
+	rng := rand.New(rand.NewSource(3))
+	for i := range src {
+		src[i] = rng.Int()
+	}
+	s := make([]int, len(src))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		copy(s, src)
+		SortSlice(a, s)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	a := NewArena(4096)
+	var s []int
+	for i := 0; i < 100; i++ {
+		s = Append(a, s, i)
+	}
+	for i, v := range s {
+		if v != i {
+			t.Fatalf("s[%d] = %d, want %d", i, v, i)
+		}
+	}
+	// Growing as the tail allocation never left holes behind.
+	if got, want := a.SizeInUse(), cap(s)*8; got != want {
+		t.Errorf("SizeInUse = %d, want %d (grown in place)", got, want)
+	}
+
+	s = Append(a, s[:2], 7, 8, 9)
+	if len(s) != 5 || s[4] != 9 {
+		t.Errorf("Append within capacity = %v", s)
+	}
+
+	// A slice that is not the tail is moved.
+	u := AllocSlice[int](a, 2)
+	a.AllocBytes(8)
+	v := Append(a, u, 1, 2, 3)
+	if &v[0] == &u[0] || len(v) != 5 || v[4] != 3 {
+		t.Errorf("Append to non-tail slice = %v, want a moved copy", v)
+	}
+}
+
+func TestGrowSlice(t *testing.T) {
+	a := NewArena(1024)
+	s := AllocSlice[int32](a, 3)
+	copy(s, []int32{1, 2, 3})
+	g := GrowSlice(a, s, 10)
+	if len(g) != 3 || cap(g) != 10 || &g[0] != &s[0] {
+		t.Errorf("GrowSlice in place: len %d cap %d moved %v", len(g), cap(g), &g[0] != &s[0])
+	}
+	if GrowSlice(a, g, 5); cap(g) != 10 {
+		t.Error("GrowSlice shrank the slice")
+	}
+
+	heap := []int32{4, 5}
+	g = GrowSlice(a, heap, 8)
+	if cap(g) != 8 || g[1] != 5 {
+		t.Errorf("GrowSlice(heap slice) = %v cap %d", g, cap(g))
+	}
+	type empty struct{}
+	if e := GrowSlice(a, []empty{}, 4); cap(e) < 4 {
+		t.Errorf("GrowSlice zero-size cap = %d, want >= 4", cap(e))
+	}
+}
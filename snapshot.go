@@ -0,0 +1,578 @@
+package arena
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Snapshot file layout. All integers are little endian.
+//
+//	header (24 bytes):
+//	  [0:8]   magic "ARENASNP"
+//	  [8:12]  format version (uint32)
+//	  [12:16] feature bitmap (uint32, SnapshotFlag bits)
+//	  [16:20] number of chunks (uint32)
+//	  [20:24] CRC-32C of the header bytes before it and the chunk table
+//	chunk table (24 bytes per chunk):
+//	  [0:8]   chunk capacity in bytes, excluding any memory tag header
+//	  [8:16]  bytes of data stored for the chunk
+//	  [16:20] CRC-32C of the chunk data (0 without SnapshotChunkCRC)
+//	  [20:24] reserved, zero
+//	nonce seed (SnapshotEncrypted only): the AEAD's nonce size in bytes,
+//	  padded to snapshotAlign bytes
+//	chunk data, each chunk padded to snapshotAlign bytes
+//	  (SnapshotCompressed: each chunk split into frames of up to
+//	  snapshotFrameSize bytes, each stored as a uint32 compressed length
+//	  followed by the compressed bytes, without padding)
+//
+// The table precedes the data so a reader can locate any chunk without
+// scanning, which is what makes lazy per-chunk loading possible.
+//
+// Encrypted snapshots store each chunk sealed with the AEAD: the ciphertext
+// is the table's data size plus the AEAD overhead, the nonce is the seed
+// with chunk i XORed into its last four bytes, and the additional data is
+// the header and chunk table, so they are authenticated along with the data.
+//
+// The magic and version never move, and every feature that changes how a
+// snapshot must be read sets a bit in the feature bitmap. A reader rejects
+// versions newer than its own and bits it does not implement instead of
+// misreading them, so snapshots kept on disk either load correctly after a
+// package upgrade or fail with ErrSnapshotIncompatible. Version 1 snapshots
+// predate the ref-width and alignment bits and are read without checking
+// them.
+const (
+	snapshotHeaderSize = 24
+	snapshotEntrySize  = 24
+	snapshotVersion    = 2
+	snapshotAlign      = 8
+)
+
+var snapshotMagic = [8]byte{'A', 'R', 'E', 'N', 'A', 'S', 'N', 'P'}
+
+// crcTable is the Castagnoli table used for snapshot checksums.
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// SnapshotFlag is a feature bit recorded in a snapshot header.
+type SnapshotFlag uint32
+
+const (
+	// SnapshotChunkCRC stamps every chunk with a CRC-32C so corruption is
+	// detected when the chunk is loaded.
+	SnapshotChunkCRC SnapshotFlag = 1 << iota
+	// SnapshotEncrypted marks chunk data sealed with an AEAD. Chunk CRCs
+	// are not recorded since the AEAD tag authenticates each chunk.
+	SnapshotEncrypted
+	// SnapshotCompressed marks chunk data compressed frame by frame with
+	// a Compressor. Chunk CRCs cover the uncompressed data.
+	SnapshotCompressed
+	// SnapshotRef64 records that the writer's int, and so the Off and Len
+	// of a Ref, is 64 bits wide. Its absence in a version 2 snapshot means
+	// 32 bits. Offsets stored in the data are only meaningful to readers
+	// of the same width.
+	SnapshotRef64
+	// SnapshotAlign8 records that allocations in the chunk data are
+	// aligned to 8 bytes, as they are on 64-bit platforms.
+	SnapshotAlign8
+)
+
+// supportedSnapshotFlags are the feature bits this package can read.
+const supportedSnapshotFlags = SnapshotChunkCRC | SnapshotEncrypted | SnapshotCompressed | SnapshotRef64 | SnapshotAlign8
+
+// snapshotFlagNames names the feature bits for SnapshotFlag.String.
+var snapshotFlagNames = []string{"chunk-crc", "encrypted", "compressed", "ref64", "align8"}
+
+// String returns the names of the bits set in f joined by "|", with
+// unknown bits in hex, or "0" if none are set.
+func (f SnapshotFlag) String() string {
+	if f == 0 {
+		return "0"
+	}
+	var names []string
+	for i, name := range snapshotFlagNames {
+		if bit := SnapshotFlag(1) << i; f&bit != 0 {
+			names = append(names, name)
+			f &^= bit
+		}
+	}
+	if f != 0 {
+		names = append(names, fmt.Sprintf("%#x", uint32(f)))
+	}
+	return strings.Join(names, "|")
+}
+
+// platformSnapshotFlags returns the ref-width and alignment bits describing
+// snapshots written on this platform.
+func platformSnapshotFlags() SnapshotFlag {
+	var flags SnapshotFlag
+	if strconv.IntSize == 64 {
+		flags |= SnapshotRef64
+	}
+	if ptrAlign >= 8 {
+		flags |= SnapshotAlign8
+	}
+	return flags
+}
+
+var (
+	// ErrSnapshotFormat is returned for data that is not a snapshot in a
+	// supported format, or whose header or chunk table is damaged.
+	ErrSnapshotFormat = errors.New("arena: invalid snapshot format")
+	// ErrSnapshotChecksum is returned when a chunk's data does not match
+	// its recorded CRC.
+	ErrSnapshotChecksum = errors.New("arena: snapshot chunk checksum mismatch")
+	// ErrSnapshotAuth is returned when an encrypted snapshot fails
+	// authentication: it was modified or the key is wrong.
+	ErrSnapshotAuth = errors.New("arena: snapshot authentication failed")
+	// ErrSnapshotEncrypted is returned when an encrypted snapshot is read
+	// without an AEAD, or a plaintext one is read with an AEAD.
+	ErrSnapshotEncrypted = errors.New("arena: snapshot encryption mismatch")
+	// ErrSnapshotIncompatible is returned for a well-formed snapshot this
+	// build cannot load: a newer format version, a feature it does not
+	// implement, or data written for a different ref width or alignment.
+	ErrSnapshotIncompatible = errors.New("arena: incompatible snapshot")
+)
+
+// SnapshotInfo describes a snapshot without its data.
+type SnapshotInfo struct {
+	Version  int          // format version
+	Flags    SnapshotFlag // feature bitmap
+	Chunks   int          // number of chunks
+	Size     int64        // bytes of chunk data, excluding encryption overhead
+	Capacity int64        // total capacity of the chunks when loaded
+}
+
+// Compatible returns nil if this build can load the snapshot, or an error
+// wrapping ErrSnapshotIncompatible saying why not. Encryption is not
+// considered: an encrypted snapshot also needs its AEAD.
+func (info SnapshotInfo) Compatible() error {
+	if info.Version < 1 || info.Version > snapshotVersion {
+		return fmt.Errorf("%w: version %d, want 1 to %d", ErrSnapshotIncompatible, info.Version, snapshotVersion)
+	}
+	if f := info.Flags &^ supportedSnapshotFlags; f != 0 {
+		return fmt.Errorf("%w: unsupported features %v", ErrSnapshotIncompatible, f)
+	}
+	if info.Version < 2 {
+		return nil
+	}
+	platform := platformSnapshotFlags()
+	if info.Flags&SnapshotRef64 != platform&SnapshotRef64 {
+		return fmt.Errorf("%w: written with %d-bit refs, this platform uses %d", ErrSnapshotIncompatible, refWidth(info.Flags), refWidth(platform))
+	}
+	if platform&SnapshotAlign8 != 0 && info.Flags&SnapshotAlign8 == 0 {
+		return fmt.Errorf("%w: data not 8-byte aligned", ErrSnapshotIncompatible)
+	}
+	return nil
+}
+
+// refWidth returns the ref width in bits recorded by flags.
+func refWidth(flags SnapshotFlag) int {
+	if flags&SnapshotRef64 != 0 {
+		return 64
+	}
+	return 32
+}
+
+// InspectSnapshot reads the header and chunk table of the snapshot in r and
+// returns its metadata without reading any chunk data, so tooling can
+// validate snapshots before use. The header and table checksum is
+// verified; chunk checksums are not. An error wrapping ErrSnapshotFormat
+// means r does not hold an intact snapshot. A snapshot this build cannot
+// load is still described: check Compatible before loading it.
+func InspectSnapshot(r io.Reader) (SnapshotInfo, error) {
+	var hdr [snapshotHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("%w: %v", ErrSnapshotFormat, err)
+	}
+	if [8]byte(hdr[0:8]) != snapshotMagic {
+		return SnapshotInfo{}, fmt.Errorf("%w: bad magic", ErrSnapshotFormat)
+	}
+	info := SnapshotInfo{
+		Version: int(binary.LittleEndian.Uint32(hdr[8:12])),
+		Flags:   SnapshotFlag(binary.LittleEndian.Uint32(hdr[12:16])),
+	}
+	if info.Version > snapshotVersion {
+		// A newer layout may differ past the version; report what is known.
+		return info, nil
+	}
+	_, entries, _, err := readSnapshotMeta(io.MultiReader(bytes.NewReader(hdr[:]), r), false)
+	if err != nil {
+		return info, err
+	}
+	info.Chunks = len(entries)
+	for _, e := range entries {
+		info.Size += int64(e.size)
+		info.Capacity += int64(e.capacity)
+	}
+	return info, nil
+}
+
+// SnapshotOptions configures WriteSnapshot.
+type SnapshotOptions struct {
+	// NoChunkCRC skips the per-chunk CRCs. The header and chunk table are
+	// always checksummed.
+	NoChunkCRC bool
+	// AEAD, if set, encrypts the chunk data and authenticates the header
+	// and chunk table, for snapshots that may hold sensitive data. The
+	// same AEAD (key) must be passed to ReadSnapshot. Its nonce size must
+	// be at least 4 bytes; AES-GCM and ChaCha20-Poly1305 both qualify.
+	AEAD cipher.AEAD
+	// Compressor, if set, compresses the chunk data, for snapshots shipped
+	// between hosts. Reading a compressed snapshot needs a Compressor for
+	// the same codec; it is ignored for uncompressed ones. Compression
+	// cannot be combined with AEAD.
+	Compressor Compressor
+}
+
+// snapshotEntry is a decoded chunk table entry.
+type snapshotEntry struct {
+	capacity uint64
+	size     uint64
+	crc      uint32
+	hasCRC   bool
+}
+
+// WriteTo writes the arena's chunk contents to w in the snapshot format,
+// with per-chunk CRCs. It implements io.WriterTo. Only bytes are saved: Go
+// pointers stored in the arena are meaningless once loaded, so snapshots
+// suit offset-based data (see Ref and ContiguousArena). Memory tag headers
+// are not saved.
+func (a *Arena) WriteTo(w io.Writer) (int64, error) {
+	return a.WriteSnapshot(w, SnapshotOptions{})
+}
+
+// WriteSnapshot is WriteTo with options.
+func (a *Arena) WriteSnapshot(w io.Writer, opts SnapshotOptions) (int64, error) {
+	a.panicIfReleased()
+	flags := platformSnapshotFlags()
+	var seed []byte
+	if opts.Compressor != nil {
+		if opts.AEAD != nil {
+			return 0, errors.New("arena: snapshot compression cannot be combined with encryption")
+		}
+		flags |= SnapshotCompressed
+	}
+	switch {
+	case opts.AEAD != nil:
+		flags |= SnapshotEncrypted
+		seed = make([]byte, opts.AEAD.NonceSize())
+		if len(seed) < 4 {
+			return 0, fmt.Errorf("arena: AEAD nonce size %d too small for snapshots", len(seed))
+		}
+		if _, err := rand.Read(seed); err != nil {
+			return 0, err
+		}
+	case !opts.NoChunkCRC:
+		flags |= SnapshotChunkCRC
+	}
+	meta := make([]byte, snapshotHeaderSize+snapshotEntrySize*len(a.chunks))
+	copy(meta[0:8], snapshotMagic[:])
+	binary.LittleEndian.PutUint32(meta[8:12], snapshotVersion)
+	binary.LittleEndian.PutUint32(meta[12:16], uint32(flags))
+	binary.LittleEndian.PutUint32(meta[16:20], uint32(len(a.chunks)))
+	for i := range a.chunks {
+		data := a.chunkData(i)
+		e := meta[snapshotHeaderSize+i*snapshotEntrySize:]
+		binary.LittleEndian.PutUint64(e[0:8], uint64(len(a.chunks[i].buf)-int(a.chunkBase)))
+		binary.LittleEndian.PutUint64(e[8:16], uint64(len(data)))
+		if flags&SnapshotChunkCRC != 0 {
+			binary.LittleEndian.PutUint32(e[16:20], crc32.Checksum(data, crcTable))
+		}
+	}
+	binary.LittleEndian.PutUint32(meta[20:24], snapshotMetaCRC(meta))
+
+	n, err := w.Write(meta)
+	total := int64(n)
+	if err != nil {
+		return total, err
+	}
+	var pad [snapshotAlign]byte
+	if seed != nil {
+		n, err = w.Write(append(seed, pad[:snapshotPadding(len(seed))]...))
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	var sealed []byte
+	for i := range a.chunks {
+		data := a.chunkData(i)
+		if opts.Compressor != nil {
+			n, err := writeCompressedChunk(w, opts.Compressor, data, &sealed)
+			total += n
+			if err != nil {
+				return total, err
+			}
+			continue
+		}
+		if seed != nil {
+			sealed = opts.AEAD.Seal(sealed[:0], snapshotNonce(seed, i), data, meta)
+			data = sealed
+		}
+		n, err = w.Write(data)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+		n, err = w.Write(pad[:snapshotPadding(len(data))])
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// ReadFrom replaces the arena's contents with a snapshot read from r,
+// verifying every checksum before anything is made visible. Like Reset, it
+// invalidates all previous allocations; afterwards allocation continues
+// after the loaded data. It implements io.ReaderFrom. On error the arena
+// is left reset.
+func (a *Arena) ReadFrom(r io.Reader) (int64, error) {
+	return a.ReadSnapshot(r, SnapshotOptions{})
+}
+
+// ReadSnapshot is ReadFrom with options. Encrypted snapshots must be read
+// with the AEAD they were written with; a snapshot that was modified or is
+// read with the wrong key fails with ErrSnapshotAuth. Compressed
+// snapshots need opts.Compressor. Only opts.AEAD and opts.Compressor are
+// used. A chunk's memory is allocated only once its data has been read and
+// verified, so an encrypted snapshot's chunk sizes are authenticated before
+// they are trusted.
+func (a *Arena) ReadSnapshot(r io.Reader, opts SnapshotOptions) (int64, error) {
+	a.Reset()
+	cr := &countingReader{r: bufio.NewReader(r)}
+	flags, entries, meta, err := readSnapshotMeta(cr, true)
+	if err != nil {
+		return cr.n, err
+	}
+	if (flags&SnapshotEncrypted != 0) != (opts.AEAD != nil) {
+		return cr.n, ErrSnapshotEncrypted
+	}
+	compressed := flags&SnapshotCompressed != 0
+	if compressed && flags&SnapshotEncrypted != 0 {
+		return cr.n, fmt.Errorf("%w: both compressed and encrypted", ErrSnapshotFormat)
+	}
+	if compressed && opts.Compressor == nil {
+		return cr.n, fmt.Errorf("%w: compressed snapshot read without a Compressor", ErrSnapshotIncompatible)
+	}
+	var pad [snapshotAlign]byte
+	var seed, sealed []byte
+	if opts.AEAD != nil {
+		seed = make([]byte, opts.AEAD.NonceSize())
+		if _, err := io.ReadFull(cr, seed); err != nil {
+			return cr.n, fmt.Errorf("%w: %v", ErrSnapshotFormat, err)
+		}
+		if _, err := io.ReadFull(cr, pad[:snapshotPadding(len(seed))]); err != nil {
+			return cr.n, fmt.Errorf("%w: %v", ErrSnapshotFormat, err)
+		}
+	}
+	chunks := make([]chunk, len(entries))
+	loaded := false
+	defer func() {
+		if !loaded {
+			for _, c := range chunks {
+				a.freeChunkBuf(c.buf)
+			}
+		}
+	}()
+	// Each chunk is read, authenticated and verified in scratch before its
+	// buffer is allocated, so neither a damaged file nor one forged without
+	// the key can make the arena reserve more than the data it contains.
+	var scratch []byte
+	for i, e := range entries {
+		var data []byte
+		if compressed {
+			data, err = readCompressedChunk(cr, opts.Compressor, scratch[:0], e.size, &sealed)
+			if err != nil {
+				return cr.n, fmt.Errorf("%w: chunk %d: %v", ErrSnapshotFormat, i, err)
+			}
+		} else {
+			stored := e.size
+			if seed != nil {
+				stored += uint64(opts.AEAD.Overhead())
+			}
+			data, err = readSnapshotData(cr, scratch[:0], stored)
+			if err != nil {
+				return cr.n, fmt.Errorf("%w: chunk %d: %v", ErrSnapshotFormat, i, err)
+			}
+			if _, err := io.ReadFull(cr, pad[:snapshotPadding(len(data))]); err != nil {
+				return cr.n, fmt.Errorf("%w: chunk %d: %v", ErrSnapshotFormat, i, err)
+			}
+			if seed != nil {
+				// The header and table are the additional data, so this
+				// also authenticates the capacity allocated below.
+				if data, err = opts.AEAD.Open(data[:0], snapshotNonce(seed, i), data, meta); err != nil {
+					return cr.n, fmt.Errorf("%w: chunk %d", ErrSnapshotAuth, i)
+				}
+			}
+		}
+		scratch = data
+		if err := e.verify(data); err != nil {
+			return cr.n, fmt.Errorf("%w: chunk %d", err, i)
+		}
+		c := &chunks[i]
+		c.buf = a.newChunkBuf(int(a.chunkBase) + int(e.capacity))
+		c.offset = a.chunkBase + uintptr(copy(c.buf[a.chunkBase:], data))
+		c.lastUsed = a.generation
+		c.zeroed = true
+	}
+	if len(chunks) == 0 {
+		return cr.n, nil
+	}
+	for _, c := range a.chunks {
+		a.freeChunkBuf(c.buf)
+	}
+	loaded = true
+	a.chunks = chunks
+	a.currentChunk = &a.chunks[len(a.chunks)-1]
+	a.requested = a.usedBytes(chunks) // loaded data counts as requested
+	if a.debug != nil && a.debug.tags {
+		a.writeChunkTags()
+	}
+	return cr.n, nil
+}
+
+// chunkData returns the data of chunk i saved in snapshots.
+func (a *Arena) chunkData(i int) []byte {
+	c := &a.chunks[i]
+	return c.buf[a.chunkBase:c.offset]
+}
+
+// readSnapshotData appends n bytes read from r to buf. It grows buf as the
+// data arrives rather than up front, so a corrupt length fails at the end
+// of the file instead of forcing a huge allocation.
+func readSnapshotData(r io.Reader, buf []byte, n uint64) ([]byte, error) {
+	for n > 0 {
+		step := int(min(n, snapshotFrameSize))
+		buf = slices.Grow(buf, step)
+		if _, err := io.ReadFull(r, buf[len(buf):len(buf)+step]); err != nil {
+			return buf, err
+		}
+		buf = buf[:len(buf)+step]
+		n -= uint64(step)
+	}
+	return buf, nil
+}
+
+// verify checks data against the entry's CRC if the snapshot has one.
+func (e snapshotEntry) verify(data []byte) error {
+	if e.hasCRC && crc32.Checksum(data, crcTable) != e.crc {
+		return ErrSnapshotChecksum
+	}
+	return nil
+}
+
+// readSnapshotMeta reads and validates the header and chunk table, and
+// returns them along with their raw bytes. If compat is set, snapshots
+// this build cannot load are rejected.
+func readSnapshotMeta(r io.Reader, compat bool) (SnapshotFlag, []snapshotEntry, []byte, error) {
+	var hdr [snapshotHeaderSize]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return 0, nil, nil, fmt.Errorf("%w: %v", ErrSnapshotFormat, err)
+	}
+	n, err := checkSnapshotHeader(hdr[:], compat)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	// Grow the table as it is read so a corrupt count cannot force a huge
+	// allocation up front.
+	meta := append([]byte(nil), hdr[:]...)
+	var e [snapshotEntrySize]byte
+	for i := 0; i < n; i++ {
+		if _, err := io.ReadFull(r, e[:]); err != nil {
+			return 0, nil, nil, fmt.Errorf("%w: %v", ErrSnapshotFormat, err)
+		}
+		meta = append(meta, e[:]...)
+	}
+	flags, entries, err := parseSnapshotMeta(meta)
+	return flags, entries, meta, err
+}
+
+// checkSnapshotHeader validates the fixed header and returns the chunk
+// count. If compat is set, it also checks that this build can load the
+// snapshot.
+func checkSnapshotHeader(hdr []byte, compat bool) (int, error) {
+	if [8]byte(hdr[0:8]) != snapshotMagic {
+		return 0, fmt.Errorf("%w: bad magic", ErrSnapshotFormat)
+	}
+	v := binary.LittleEndian.Uint32(hdr[8:12])
+	if v == 0 {
+		return 0, fmt.Errorf("%w: version 0", ErrSnapshotFormat)
+	}
+	if compat {
+		info := SnapshotInfo{Version: int(v), Flags: SnapshotFlag(binary.LittleEndian.Uint32(hdr[12:16]))}
+		if err := info.Compatible(); err != nil {
+			return 0, err
+		}
+	}
+	return int(binary.LittleEndian.Uint32(hdr[16:20])), nil
+}
+
+// parseSnapshotMeta verifies the header CRC and decodes the chunk table
+// from meta, which holds the header followed by the complete table.
+func parseSnapshotMeta(meta []byte) (SnapshotFlag, []snapshotEntry, error) {
+	if snapshotMetaCRC(meta) != binary.LittleEndian.Uint32(meta[20:24]) {
+		return 0, nil, fmt.Errorf("%w: header checksum mismatch", ErrSnapshotFormat)
+	}
+	flags := SnapshotFlag(binary.LittleEndian.Uint32(meta[12:16]))
+	entries := make([]snapshotEntry, (len(meta)-snapshotHeaderSize)/snapshotEntrySize)
+	for i := range entries {
+		e := meta[snapshotHeaderSize+i*snapshotEntrySize:]
+		entries[i] = snapshotEntry{
+			capacity: binary.LittleEndian.Uint64(e[0:8]),
+			size:     binary.LittleEndian.Uint64(e[8:16]),
+			crc:      binary.LittleEndian.Uint32(e[16:20]),
+		}
+		if entries[i].size > entries[i].capacity || entries[i].capacity > maxSnapshotChunk {
+			return 0, nil, fmt.Errorf("%w: chunk %d has invalid size", ErrSnapshotFormat, i)
+		}
+		entries[i].hasCRC = flags&SnapshotChunkCRC != 0
+	}
+	return flags, entries, nil
+}
+
+// maxSnapshotChunk bounds chunk sizes accepted from snapshots.
+const maxSnapshotChunk = 1 << 40
+
+// snapshotMetaCRC returns the CRC of the header (excluding its CRC field)
+// and chunk table.
+func snapshotMetaCRC(meta []byte) uint32 {
+	crc := crc32.Update(0, crcTable, meta[:20])
+	return crc32.Update(crc, crcTable, meta[snapshotHeaderSize:])
+}
+
+// snapshotNonce returns the nonce chunk i is sealed with: the seed with i
+// XORed into its last four bytes.
+func snapshotNonce(seed []byte, i int) []byte {
+	nonce := bytes.Clone(seed)
+	tail := nonce[len(nonce)-4:]
+	binary.BigEndian.PutUint32(tail, binary.BigEndian.Uint32(tail)^uint32(i))
+	return nonce
+}
+
+// snapshotPadding returns the padding after n bytes of chunk data.
+func snapshotPadding(n int) int {
+	return (snapshotAlign - n%snapshotAlign) % snapshotAlign
+}
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,342 @@
+package arena
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// snapshotArena returns an arena with recognizable data in two chunks.
+func snapshotArena() *Arena {
+	a := NewArena(1024)
+	copy(a.AllocBytes(11), "hello world")
+	copy(a.AllocBytes(2000), bytes.Repeat([]byte("x"), 2000))
+	return a
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	a := snapshotArena()
+	var buf bytes.Buffer
+	n, err := a.WriteTo(&buf)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteTo = %d, %v, want %d, nil", n, err, buf.Len())
+	}
+
+	b := NewArena(512)
+	stale := b.Generation()
+	if n, err := b.ReadFrom(bytes.NewReader(buf.Bytes())); err != nil || n != int64(buf.Len()) {
+		t.Fatalf("ReadFrom = %d, %v, want %d, nil", n, err, buf.Len())
+	}
+	if b.Generation() == stale {
+		t.Error("ReadFrom did not invalidate previous allocations")
+	}
+	if b.NumChunks() != 2 || b.SizeInUse() != a.SizeInUse() {
+		t.Errorf("NumChunks, SizeInUse = %d, %d, want 2, %d", b.NumChunks(), b.SizeInUse(), a.SizeInUse())
+	}
+	if got := string(b.chunkData(0)[:11]); got != "hello world" {
+		t.Errorf("chunk 0 = %q, want %q", got, "hello world")
+	}
+	// Allocation continues after the loaded data without overwriting it.
+	copy(b.AllocBytes(100), bytes.Repeat([]byte("y"), 100))
+	if got := string(b.chunkData(0)[:11]); got != "hello world" {
+		t.Errorf("chunk 0 after allocation = %q, want %q", got, "hello world")
+	}
+	if !bytes.Equal(b.chunkData(1), bytes.Repeat([]byte("x"), 2000)) {
+		t.Error("chunk 1 overwritten by allocation after ReadFrom")
+	}
+}
+
+func TestSnapshotCorruption(t *testing.T) {
+	var buf bytes.Buffer
+	snapshotArena().WriteTo(&buf)
+	good := buf.Bytes()
+
+	data := bytes.Clone(good)
+	data[len(data)-1] ^= 0xFF // last chunk's data
+	if _, err := NewArena(0).ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotChecksum) {
+		t.Errorf("ReadFrom(corrupt chunk) error = %v, want ErrSnapshotChecksum", err)
+	}
+
+	data = bytes.Clone(good)
+	data[snapshotHeaderSize+8] ^= 1 // chunk table
+	if _, err := NewArena(0).ReadFrom(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("ReadFrom(corrupt table) error = %v, want ErrSnapshotFormat", err)
+	}
+
+	if _, err := NewArena(0).ReadFrom(bytes.NewReader(good[:len(good)-100])); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("ReadFrom(truncated) error = %v, want ErrSnapshotFormat", err)
+	}
+	if _, err := NewArena(0).ReadFrom(bytes.NewReader([]byte("not a snapshot at all..."))); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("ReadFrom(garbage) error = %v, want ErrSnapshotFormat", err)
+	}
+
+	// Without chunk CRCs, data corruption is not detected.
+	buf.Reset()
+	snapshotArena().WriteSnapshot(&buf, SnapshotOptions{NoChunkCRC: true})
+	data = buf.Bytes()
+	data[len(data)-1] ^= 0xFF
+	if _, err := NewArena(0).ReadFrom(bytes.NewReader(data)); err != nil {
+		t.Errorf("ReadFrom without chunk CRCs error = %v, want nil", err)
+	}
+}
+
+func snapshotAEAD(t *testing.T, key byte) cipher.AEAD {
+	t.Helper()
+	block, err := aes.NewCipher(bytes.Repeat([]byte{key}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return aead
+}
+
+func TestSnapshotEncrypted(t *testing.T) {
+	aead := snapshotAEAD(t, 1)
+	var buf bytes.Buffer
+	if _, err := snapshotArena().WriteSnapshot(&buf, SnapshotOptions{AEAD: aead}); err != nil {
+		t.Fatalf("WriteSnapshot error = %v", err)
+	}
+	good := buf.Bytes()
+	if bytes.Contains(good, []byte("hello world")) {
+		t.Error("encrypted snapshot contains plaintext")
+	}
+
+	b := NewArena(512)
+	if n, err := b.ReadSnapshot(bytes.NewReader(good), SnapshotOptions{AEAD: aead}); err != nil || n != int64(len(good)) {
+		t.Fatalf("ReadSnapshot = %d, %v, want %d, nil", n, err, len(good))
+	}
+	if got := string(b.chunkData(0)[:11]); got != "hello world" {
+		t.Errorf("chunk 0 = %q, want %q", got, "hello world")
+	}
+	if !bytes.Equal(b.chunkData(1), bytes.Repeat([]byte("x"), 2000)) {
+		t.Error("chunk 1 not decrypted")
+	}
+
+	if _, err := NewArena(0).ReadSnapshot(bytes.NewReader(good), SnapshotOptions{AEAD: snapshotAEAD(t, 2)}); !errors.Is(err, ErrSnapshotAuth) {
+		t.Errorf("ReadSnapshot(wrong key) error = %v, want ErrSnapshotAuth", err)
+	}
+	if _, err := NewArena(0).ReadFrom(bytes.NewReader(good)); !errors.Is(err, ErrSnapshotEncrypted) {
+		t.Errorf("ReadFrom(encrypted) error = %v, want ErrSnapshotEncrypted", err)
+	}
+
+	// The chunk table is authenticated: a consistent edit that passes the
+	// CRC still fails to open.
+	data := bytes.Clone(good)
+	e := data[snapshotHeaderSize:]
+	e[0]++ // capacity of chunk 0
+	binary.LittleEndian.PutUint32(data[20:24], snapshotMetaCRC(data[:snapshotHeaderSize+2*snapshotEntrySize]))
+	if _, err := NewArena(0).ReadSnapshot(bytes.NewReader(data), SnapshotOptions{AEAD: aead}); !errors.Is(err, ErrSnapshotAuth) {
+		t.Errorf("ReadSnapshot(edited table) error = %v, want ErrSnapshotAuth", err)
+	}
+	// A forged capacity is rejected before anything is allocated for it.
+	data = bytes.Clone(good)
+	binary.LittleEndian.PutUint64(data[snapshotHeaderSize:], maxSnapshotChunk)
+	binary.LittleEndian.PutUint32(data[20:24], snapshotMetaCRC(data[:snapshotHeaderSize+2*snapshotEntrySize]))
+	if _, err := NewArena(512, WithBudget(1<<20)).ReadSnapshot(bytes.NewReader(data), SnapshotOptions{AEAD: aead}); !errors.Is(err, ErrSnapshotAuth) {
+		t.Errorf("ReadSnapshot(forged capacity) error = %v, want ErrSnapshotAuth", err)
+	}
+	data = bytes.Clone(good)
+	data[len(data)-20] ^= 1
+	if _, err := NewArena(0).ReadSnapshot(bytes.NewReader(data), SnapshotOptions{AEAD: aead}); !errors.Is(err, ErrSnapshotAuth) {
+		t.Errorf("ReadSnapshot(corrupt chunk) error = %v, want ErrSnapshotAuth", err)
+	}
+
+	var plain bytes.Buffer
+	snapshotArena().WriteTo(&plain)
+	if _, err := NewArena(0).ReadSnapshot(&plain, SnapshotOptions{AEAD: aead}); !errors.Is(err, ErrSnapshotEncrypted) {
+		t.Errorf("ReadSnapshot(plaintext) error = %v, want ErrSnapshotEncrypted", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "enc.snap")
+	os.WriteFile(path, good, 0o600)
+	if _, err := OpenSnapshot(path); !errors.Is(err, ErrSnapshotEncrypted) {
+		t.Errorf("OpenSnapshot(encrypted) error = %v, want ErrSnapshotEncrypted", err)
+	}
+}
+
+func TestSnapshotImageLazyVerify(t *testing.T) {
+	var buf bytes.Buffer
+	snapshotArena().WriteTo(&buf)
+	data := buf.Bytes()
+	data[len(data)-1] ^= 0xFF // corrupt chunk 1 only
+
+	path := filepath.Join(t.TempDir(), "arena.snap")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	img, err := OpenSnapshot(path)
+	if err != nil {
+		t.Fatalf("OpenSnapshot error = %v", err)
+	}
+	defer img.Close()
+
+	if img.NumChunks() != 2 || img.Flags()&SnapshotChunkCRC == 0 {
+		t.Errorf("NumChunks, Flags = %d, %b, want 2 with chunk CRCs", img.NumChunks(), img.Flags())
+	}
+	c0, err := img.Chunk(0)
+	if err != nil || string(c0[:11]) != "hello world" {
+		t.Errorf("Chunk(0) = %q, %v, want hello world", c0[:min(len(c0), 11)], err)
+	}
+	if img.ChunkCapacity(0) != 1024 {
+		t.Errorf("ChunkCapacity(0) = %d, want 1024", img.ChunkCapacity(0))
+	}
+	for i := 0; i < 2; i++ { // deterministic on every access
+		if _, err := img.Chunk(1); !errors.Is(err, ErrSnapshotChecksum) {
+			t.Errorf("Chunk(1) error = %v, want ErrSnapshotChecksum", err)
+		}
+	}
+	if err := img.Verify(); !errors.Is(err, ErrSnapshotChecksum) {
+		t.Errorf("Verify() error = %v, want ErrSnapshotChecksum", err)
+	}
+	if _, err := img.Chunk(2); err == nil {
+		t.Error("Chunk(2) out of range returned no error")
+	}
+}
+
+func TestSnapshotImageTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	snapshotArena().WriteTo(&buf)
+	data := buf.Bytes()
+	for n := 0; n < len(data); n++ {
+		// An exact-capacity copy so reads past the end cannot hide in spare capacity.
+		short := make([]byte, n)
+		copy(short, data)
+		if _, err := newSnapshotImage(short); !errors.Is(err, ErrSnapshotFormat) {
+			t.Fatalf("newSnapshotImage(%d of %d bytes) error = %v, want ErrSnapshotFormat", n, len(data), err)
+		}
+	}
+}
+
+func TestOpenSnapshotInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bad.snap")
+	os.WriteFile(path, []byte("short"), 0o600)
+	if _, err := OpenSnapshot(path); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("OpenSnapshot(short file) error = %v, want ErrSnapshotFormat", err)
+	}
+	if _, err := OpenSnapshot(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("OpenSnapshot(missing) error = %v, want not exist", err)
+	}
+}
+
+// withHeader returns a copy of the snapshot data with the given version and
+// flags and a recomputed header checksum.
+func withHeader(data []byte, version uint32, flags SnapshotFlag) []byte {
+	data = bytes.Clone(data)
+	binary.LittleEndian.PutUint32(data[8:12], version)
+	binary.LittleEndian.PutUint32(data[12:16], uint32(flags))
+	n := int(binary.LittleEndian.Uint32(data[16:20]))
+	meta := data[:snapshotHeaderSize+n*snapshotEntrySize]
+	binary.LittleEndian.PutUint32(meta[20:24], snapshotMetaCRC(meta))
+	return data
+}
+
+func TestInspectSnapshot(t *testing.T) {
+	a := snapshotArena()
+	var buf bytes.Buffer
+	a.WriteTo(&buf)
+	r := bytes.NewReader(buf.Bytes())
+	info, err := InspectSnapshot(r)
+	if err != nil {
+		t.Fatalf("InspectSnapshot error = %v", err)
+	}
+	want := SnapshotInfo{
+		Version:  snapshotVersion,
+		Flags:    SnapshotChunkCRC | platformSnapshotFlags(),
+		Chunks:   2,
+		Size:     int64(a.SizeInUse()),
+		Capacity: int64(a.Capacity()),
+	}
+	if info != want {
+		t.Errorf("InspectSnapshot = %+v, want %+v", info, want)
+	}
+	if err := info.Compatible(); err != nil {
+		t.Errorf("Compatible() = %v, want nil", err)
+	}
+	if read := int64(buf.Len()) - int64(r.Len()); read != snapshotHeaderSize+2*snapshotEntrySize {
+		t.Errorf("InspectSnapshot read %d bytes, want only the header and chunk table", read)
+	}
+
+	// Chunk data is not verified.
+	data := bytes.Clone(buf.Bytes())
+	data[len(data)-1] ^= 0xFF
+	if _, err := InspectSnapshot(bytes.NewReader(data)); err != nil {
+		t.Errorf("InspectSnapshot(corrupt chunk) error = %v, want nil", err)
+	}
+	data = bytes.Clone(buf.Bytes())
+	data[snapshotHeaderSize] ^= 0xFF
+	if _, err := InspectSnapshot(bytes.NewReader(data)); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("InspectSnapshot(corrupt table) error = %v, want ErrSnapshotFormat", err)
+	}
+	if _, err := InspectSnapshot(bytes.NewReader([]byte("ARENA"))); !errors.Is(err, ErrSnapshotFormat) {
+		t.Errorf("InspectSnapshot(short) error = %v, want ErrSnapshotFormat", err)
+	}
+}
+
+func TestSnapshotCompatibility(t *testing.T) {
+	var buf bytes.Buffer
+	snapshotArena().WriteTo(&buf)
+	good := buf.Bytes()
+	platform := platformSnapshotFlags()
+	otherWidth := platform ^ SnapshotRef64
+
+	tests := []struct {
+		name    string
+		version uint32
+		flags   SnapshotFlag
+		ok      bool
+	}{
+		{"current", snapshotVersion, SnapshotChunkCRC | platform, true},
+		{"version 1", 1, SnapshotChunkCRC, true},
+		{"newer version", snapshotVersion + 1, SnapshotChunkCRC | platform, false},
+		{"unknown feature", snapshotVersion, SnapshotChunkCRC | 1<<20 | platform, false},
+		{"other ref width", snapshotVersion, SnapshotChunkCRC | otherWidth, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := withHeader(good, tt.version, tt.flags)
+			info, err := InspectSnapshot(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("InspectSnapshot error = %v", err)
+			}
+			if info.Version != int(tt.version) || info.Flags != tt.flags {
+				t.Errorf("InspectSnapshot = version %d flags %v, want %d %v", info.Version, info.Flags, tt.version, tt.flags)
+			}
+			_, readErr := NewArena(512).ReadFrom(bytes.NewReader(data))
+			path := filepath.Join(t.TempDir(), "x.snap")
+			os.WriteFile(path, data, 0o600)
+			img, openErr := OpenSnapshot(path)
+			if openErr == nil {
+				img.Close()
+			}
+			for _, err := range []error{info.Compatible(), readErr, openErr} {
+				if tt.ok && err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+				if !tt.ok && !errors.Is(err, ErrSnapshotIncompatible) {
+					t.Errorf("error = %v, want ErrSnapshotIncompatible", err)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotFlagString(t *testing.T) {
+	for f, want := range map[SnapshotFlag]string{
+		0:                                  "0",
+		SnapshotChunkCRC | SnapshotRef64:   "chunk-crc|ref64",
+		SnapshotCompressed | 1<<20:         "compressed|0x100000",
+		SnapshotEncrypted | SnapshotAlign8: "encrypted|align8",
+	} {
+		if got := f.String(); got != want {
+			t.Errorf("SnapshotFlag(%#x).String() = %q, want %q", uint32(f), got, want)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package arena
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// Compressor compresses snapshot chunk data one frame at a time. Chunks
+// are split into frames of at most snapshotFrameSize bytes, so memory use
+// stays bounded however large the arena, and a reader never has to hold
+// more than one frame of compressed data. Block codecs plug in directly:
+// zstd's EncodeAll and DecodeAll, or lz4's CompressBlock and
+// UncompressBlock. Implementations need not be safe for concurrent use.
+type Compressor interface {
+	// Compress appends the compressed form of src to dst and returns the
+	// extended buffer.
+	Compress(dst, src []byte) ([]byte, error)
+	// Decompress decompresses src into dst, which is exactly as long as
+	// the data that was compressed. It must return an error if src does
+	// not decompress to len(dst) bytes.
+	Decompress(dst, src []byte) error
+}
+
+// FlateCompressor returns a Compressor using DEFLATE from the standard
+// library at the given level (see compress/flate).
+func FlateCompressor(level int) Compressor {
+	return &flateCompressor{level: level}
+}
+
+// flateCompressor reuses one flate writer and reader across frames.
+type flateCompressor struct {
+	level int
+	buf   bytes.Buffer
+	w     *flate.Writer
+	src   bytes.Reader
+	r     io.ReadCloser
+}
+
+func (f *flateCompressor) Compress(dst, src []byte) ([]byte, error) {
+	f.buf.Reset()
+	if f.w == nil {
+		w, err := flate.NewWriter(&f.buf, f.level)
+		if err != nil {
+			return dst, err
+		}
+		f.w = w
+	} else {
+		f.w.Reset(&f.buf)
+	}
+	if _, err := f.w.Write(src); err != nil {
+		return dst, err
+	}
+	if err := f.w.Close(); err != nil {
+		return dst, err
+	}
+	return append(dst, f.buf.Bytes()...), nil
+}
+
+func (f *flateCompressor) Decompress(dst, src []byte) error {
+	f.src.Reset(src)
+	if f.r == nil {
+		f.r = flate.NewReader(&f.src)
+	} else if err := f.r.(flate.Resetter).Reset(&f.src, nil); err != nil {
+		return err
+	}
+	if _, err := io.ReadFull(f.r, dst); err != nil {
+		return err
+	}
+	var extra [1]byte
+	if n, _ := f.r.Read(extra[:]); n != 0 {
+		return fmt.Errorf("frame decompresses to more than %d bytes", len(dst))
+	}
+	return nil
+}
+
+// snapshotFrameSize is the uncompressed size of each frame of a compressed
+// chunk; the last frame of a chunk may be shorter.
+const snapshotFrameSize = 1 << 20
+
+// maxSnapshotFrame bounds the compressed frame lengths accepted from
+// snapshots, leaving room for codecs that expand incompressible data.
+const maxSnapshotFrame = 2*snapshotFrameSize + 4096
+
+// writeCompressedChunk writes data to w as frames, each a 4-byte length
+// followed by that many bytes of compressed data, and returns the bytes
+// written. scratch is reused across calls.
+func writeCompressedChunk(w io.Writer, comp Compressor, data []byte, scratch *[]byte) (int64, error) {
+	var total int64
+	for len(data) > 0 {
+		frame := data[:min(len(data), snapshotFrameSize)]
+		data = data[len(frame):]
+		out, err := comp.Compress(append((*scratch)[:0], 0, 0, 0, 0), frame)
+		if err != nil {
+			return total, fmt.Errorf("arena: compressing snapshot: %w", err)
+		}
+		*scratch = out
+		binary.LittleEndian.PutUint32(out[0:4], uint32(len(out)-4))
+		n, err := w.Write(out)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// readCompressedChunk appends the size bytes of chunk data decoded from
+// the frames written by writeCompressedChunk to dst. dst grows a frame at a
+// time, so a corrupt size cannot force a huge allocation up front. scratch
+// is reused across calls. Errors describe the damage; the caller wraps them
+// in ErrSnapshotFormat.
+func readCompressedChunk(r io.Reader, comp Compressor, dst []byte, size uint64, scratch *[]byte) ([]byte, error) {
+	var hdr [4]byte
+	for size > 0 {
+		frameLen := int(min(size, snapshotFrameSize))
+		size -= uint64(frameLen)
+		if _, err := io.ReadFull(r, hdr[:]); err != nil {
+			return dst, err
+		}
+		n := binary.LittleEndian.Uint32(hdr[:])
+		if n > maxSnapshotFrame {
+			return dst, fmt.Errorf("compressed frame of %d bytes", n)
+		}
+		if cap(*scratch) < int(n) {
+			*scratch = make([]byte, n)
+		}
+		src := (*scratch)[:n]
+		if _, err := io.ReadFull(r, src); err != nil {
+			return dst, err
+		}
+		dst = slices.Grow(dst, frameLen)
+		frame := dst[len(dst) : len(dst)+frameLen]
+		if err := comp.Decompress(frame, src); err != nil {
+			return dst, fmt.Errorf("decompressing: %v", err)
+		}
+		dst = dst[:len(dst)+frameLen]
+	}
+	return dst, nil
+}
@@ -0,0 +1,136 @@
+package arena
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// compressibleArena returns an arena with a chunk spanning several frames
+// and a small one.
+func compressibleArena(size int) *Arena {
+	a := NewArena(1024)
+	copy(a.AllocBytes(11), "hello world")
+	b := a.AllocBytes(size)
+	for i := range b {
+		b[i] = byte(i / 4096)
+	}
+	return a
+}
+
+func TestSnapshotCompressed(t *testing.T) {
+	a := compressibleArena(2*snapshotFrameSize + 100)
+	opts := SnapshotOptions{Compressor: FlateCompressor(flate.BestSpeed)}
+	var buf bytes.Buffer
+	n, err := a.WriteSnapshot(&buf, opts)
+	if err != nil || n != int64(buf.Len()) {
+		t.Fatalf("WriteSnapshot = %d, %v, want %d, nil", n, err, buf.Len())
+	}
+	if buf.Len() >= a.SizeInUse()/10 {
+		t.Errorf("compressed snapshot is %d bytes for %d bytes of data", buf.Len(), a.SizeInUse())
+	}
+	info, err := InspectSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil || info.Flags&SnapshotCompressed == 0 || info.Size != int64(a.SizeInUse()) {
+		t.Errorf("InspectSnapshot = %+v, %v, want compressed with size %d", info, err, a.SizeInUse())
+	}
+
+	b := NewArena(512)
+	if n, err := b.ReadSnapshot(bytes.NewReader(buf.Bytes()), opts); err != nil || n != int64(buf.Len()) {
+		t.Fatalf("ReadSnapshot = %d, %v, want %d, nil", n, err, buf.Len())
+	}
+	for i := range a.chunks {
+		if !bytes.Equal(b.chunkData(i), a.chunkData(i)) {
+			t.Errorf("chunk %d differs after round trip", i)
+		}
+	}
+
+	if _, err := NewArena(512).ReadFrom(bytes.NewReader(buf.Bytes())); !errors.Is(err, ErrSnapshotIncompatible) {
+		t.Errorf("ReadFrom without Compressor error = %v, want ErrSnapshotIncompatible", err)
+	}
+	path := filepath.Join(t.TempDir(), "c.snap")
+	os.WriteFile(path, buf.Bytes(), 0o600)
+	if _, err := OpenSnapshot(path); !errors.Is(err, ErrSnapshotIncompatible) {
+		t.Errorf("OpenSnapshot(compressed) error = %v, want ErrSnapshotIncompatible", err)
+	}
+
+	// An uncompressed snapshot ignores the Compressor.
+	buf.Reset()
+	a.WriteTo(&buf)
+	if _, err := NewArena(512).ReadSnapshot(&buf, opts); err != nil {
+		t.Errorf("ReadSnapshot(uncompressed) with Compressor error = %v", err)
+	}
+}
+
+func TestSnapshotCompressedCorruption(t *testing.T) {
+	opts := SnapshotOptions{Compressor: FlateCompressor(flate.DefaultCompression)}
+	var buf bytes.Buffer
+	compressibleArena(100000).WriteSnapshot(&buf, opts)
+	good := buf.Bytes()
+	metaLen := snapshotHeaderSize + 2*snapshotEntrySize
+
+	tests := []struct {
+		name string
+		data []byte
+		want error
+	}{
+		{"truncated", good[:len(good)-10], ErrSnapshotFormat},
+		{"frame length", func() []byte {
+			d := bytes.Clone(good)
+			d[metaLen+3] = 0xFF // first frame's length
+			return d
+		}(), ErrSnapshotFormat},
+		{"frame data", func() []byte {
+			d := bytes.Clone(good)
+			d[len(d)-20] ^= 0xFF
+			return d
+		}(), ErrSnapshotFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewArena(512).ReadSnapshot(bytes.NewReader(tt.data), opts)
+			// Damage the codec does not notice is caught by the chunk CRC.
+			if !errors.Is(err, tt.want) && !errors.Is(err, ErrSnapshotChecksum) {
+				t.Errorf("ReadSnapshot error = %v, want %v or ErrSnapshotChecksum", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotCompressedEncrypted(t *testing.T) {
+	opts := SnapshotOptions{Compressor: FlateCompressor(flate.BestSpeed), AEAD: snapshotAEAD(t, 1)}
+	if _, err := snapshotArena().WriteSnapshot(&bytes.Buffer{}, opts); err == nil {
+		t.Error("WriteSnapshot with Compressor and AEAD succeeded")
+	}
+}
+
+// BenchmarkSnapshotLoad compares loading a 64 MiB arena from the raw and
+// compressed formats; the compressed figures include decompression.
+func BenchmarkSnapshotLoad(b *testing.B) {
+	a := compressibleArena(64 << 20)
+	for _, bc := range []struct {
+		name string
+		opts SnapshotOptions
+	}{
+		{"raw", SnapshotOptions{}},
+		{"flate-speed", SnapshotOptions{Compressor: FlateCompressor(flate.BestSpeed)}},
+		{"flate-default", SnapshotOptions{Compressor: FlateCompressor(flate.DefaultCompression)}},
+	} {
+		var buf bytes.Buffer
+		if _, err := a.WriteSnapshot(&buf, bc.opts); err != nil {
+			b.Fatal(err)
+		}
+		b.Run(bc.name, func(b *testing.B) {
+			dst := NewArena(1024)
+			b.SetBytes(int64(a.SizeInUse()))
+			b.ReportMetric(float64(buf.Len()), "file-bytes")
+			for b.Loop() {
+				if _, err := dst.ReadSnapshot(bytes.NewReader(buf.Bytes()), bc.opts); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
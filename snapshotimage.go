@@ -0,0 +1,151 @@
+package arena
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// Chunk verification states of a SnapshotImage.
+const (
+	chunkUnverified uint32 = iota
+	chunkVerified
+	chunkCorrupt
+)
+
+// SnapshotImage is a snapshot file mapped into memory for lazy loading.
+// Opening it reads only the header and chunk table; each chunk is checked
+// against its CRC the first time it is accessed, so a multi-GB snapshot can
+// start serving before it has been fully verified, and corruption is
+// reported deterministically by the access that would have used it.
+// A SnapshotImage is safe for concurrent use.
+type SnapshotImage struct {
+	data    []byte // whole file
+	unmap   func() error
+	entries []snapshotEntry
+	offsets []int // start of each chunk's data within data
+	state   []atomic.Uint32
+	flags   SnapshotFlag
+}
+
+// OpenSnapshot maps the snapshot file at path and validates its header and
+// chunk table. Chunk data is not read until accessed. On platforms without
+// mmap the file is read into memory instead; verification stays lazy.
+// Encrypted snapshots cannot be mapped and return ErrSnapshotEncrypted;
+// compressed ones return ErrSnapshotIncompatible.
+func OpenSnapshot(path string) (*SnapshotImage, error) {
+	data, unmap, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	img, err := newSnapshotImage(data)
+	if err != nil {
+		unmap()
+		return nil, err
+	}
+	img.unmap = unmap
+	return img, nil
+}
+
+// newSnapshotImage parses the snapshot held in data.
+func newSnapshotImage(data []byte) (*SnapshotImage, error) {
+	if len(data) < snapshotHeaderSize {
+		return nil, fmt.Errorf("%w: file too short", ErrSnapshotFormat)
+	}
+	n, err := checkSnapshotHeader(data, true)
+	if err != nil {
+		return nil, err
+	}
+	if n < 0 || n > (len(data)-snapshotHeaderSize)/snapshotEntrySize {
+		return nil, fmt.Errorf("%w: truncated chunk table", ErrSnapshotFormat)
+	}
+	metaLen := snapshotHeaderSize + n*snapshotEntrySize
+	flags, entries, err := parseSnapshotMeta(data[:metaLen])
+	if err != nil {
+		return nil, err
+	}
+	if flags&SnapshotEncrypted != 0 {
+		// Chunks cannot be served in place; load with ReadSnapshot.
+		return nil, ErrSnapshotEncrypted
+	}
+	if flags&SnapshotCompressed != 0 {
+		return nil, fmt.Errorf("%w: compressed snapshots cannot be mapped; load with ReadSnapshot", ErrSnapshotIncompatible)
+	}
+	img := &SnapshotImage{
+		data:    data,
+		entries: entries,
+		offsets: make([]int, n),
+		state:   make([]atomic.Uint32, n),
+		flags:   flags,
+	}
+	off := metaLen
+	for i, e := range entries {
+		// off may run past the end after the previous chunk's padding.
+		if off > len(data) || e.size > uint64(len(data)-off) {
+			return nil, fmt.Errorf("%w: chunk %d extends past end of file", ErrSnapshotFormat, i)
+		}
+		img.offsets[i] = off
+		off += int(e.size)
+		off += snapshotPadding(int(e.size))
+	}
+	return img, nil
+}
+
+// NumChunks returns the number of chunks in the snapshot.
+func (img *SnapshotImage) NumChunks() int {
+	return len(img.entries)
+}
+
+// Flags returns the feature flags recorded in the snapshot header.
+func (img *SnapshotImage) Flags() SnapshotFlag {
+	return img.flags
+}
+
+// Chunk returns the data of chunk i, verifying its CRC on first access.
+// The returned slice is read-only and valid until Close. Every access to a
+// corrupt chunk returns an error wrapping ErrSnapshotChecksum.
+func (img *SnapshotImage) Chunk(i int) ([]byte, error) {
+	if i < 0 || i >= len(img.entries) {
+		return nil, fmt.Errorf("arena: snapshot chunk %d out of range [0, %d)", i, len(img.entries))
+	}
+	e := img.entries[i]
+	data := img.data[img.offsets[i] : img.offsets[i]+int(e.size) : img.offsets[i]+int(e.size)]
+	switch img.state[i].Load() {
+	case chunkVerified:
+		return data, nil
+	case chunkCorrupt:
+		return nil, fmt.Errorf("%w: chunk %d", ErrSnapshotChecksum, i)
+	}
+	// Concurrent first accesses may both verify; they reach the same result.
+	if err := e.verify(data); err != nil {
+		img.state[i].Store(chunkCorrupt)
+		return nil, fmt.Errorf("%w: chunk %d", err, i)
+	}
+	img.state[i].Store(chunkVerified)
+	return data, nil
+}
+
+// ChunkCapacity returns the capacity chunk i had in the arena it was saved from.
+func (img *SnapshotImage) ChunkCapacity(i int) int {
+	return int(img.entries[i].capacity)
+}
+
+// Verify checks every chunk not yet accessed and returns the first error.
+func (img *SnapshotImage) Verify() error {
+	for i := range img.entries {
+		if _, err := img.Chunk(i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close unmaps the file. Slices returned by Chunk must not be used afterwards.
+func (img *SnapshotImage) Close() error {
+	if img.unmap == nil {
+		return nil
+	}
+	err := img.unmap()
+	img.unmap = nil
+	img.data = nil
+	return err
+}
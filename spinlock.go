@@ -0,0 +1,85 @@
+package arena
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// safeLock is the lock used by SafeArena. It is a sync.RWMutex unless the
+// arena was created with WithSpinLock, in which case readers take the
+// spinlock exclusively like writers.
+type safeLock struct {
+	mu   sync.RWMutex
+	sl   spinLock
+	spin bool // set once at construction, never mutated afterwards
+}
+
+func (l *safeLock) Lock() {
+	if l.spin {
+		l.sl.Lock()
+		return
+	}
+	l.mu.Lock()
+}
+
+func (l *safeLock) Unlock() {
+	if l.spin {
+		l.sl.Unlock()
+		return
+	}
+	l.mu.Unlock()
+}
+
+func (l *safeLock) RLock() {
+	if l.spin {
+		l.sl.Lock()
+		return
+	}
+	l.mu.RLock()
+}
+
+func (l *safeLock) RUnlock() {
+	if l.spin {
+		l.sl.Unlock()
+		return
+	}
+	l.mu.RUnlock()
+}
+
+// maxSpinBackoff caps the number of busy iterations between CAS attempts.
+const maxSpinBackoff = 64
+
+// spinLock is a test-and-test-and-set spinlock with exponential backoff.
+// After the backoff is exhausted it yields the processor so a preempted
+// holder can make progress.
+type spinLock struct {
+	state atomic.Uint32
+}
+
+func (l *spinLock) Lock() {
+	if l.state.CompareAndSwap(0, 1) {
+		return
+	}
+	backoff := 1
+	for {
+		// Spin on a plain load to avoid hammering the cache line with CAS.
+		for i := 0; i < backoff; i++ {
+			if l.state.Load() == 0 {
+				break
+			}
+		}
+		if l.state.CompareAndSwap(0, 1) {
+			return
+		}
+		if backoff < maxSpinBackoff {
+			backoff <<= 1
+		} else {
+			runtime.Gosched()
+		}
+	}
+}
+
+func (l *spinLock) Unlock() {
+	l.state.Store(0)
+}
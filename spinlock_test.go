@@ -0,0 +1,56 @@
+package arena
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestSpinLockMutualExclusion(t *testing.T) {
+	var l spinLock
+	var wg sync.WaitGroup
+	counter := 0
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				l.Lock()
+				counter++
+				l.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if counter != 8000 {
+		t.Errorf("counter = %d, want 8000", counter)
+	}
+}
+
+func TestSafeArenaWithSpinLock(t *testing.T) {
+	s := NewSafeArena(1024, WithSpinLock())
+	defer s.Release()
+	if !s.mu.spin {
+		t.Fatal("WithSpinLock() did not select the spinlock")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b := s.AllocBytes(16)
+				for k := range b {
+					b[k] = byte(k)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.SizeInUse() != 4*100*16 {
+		t.Errorf("SizeInUse = %d, want %d", s.SizeInUse(), 4*100*16)
+	}
+}
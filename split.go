@@ -0,0 +1,130 @@
+package arena
+
+import (
+	"bytes"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// Byte-slice splitting for parsers. Only the slice of headers is allocated
+// from the arena; the pieces alias b, so b should itself be memory
+// allocated from a in the current cycle (use CloneBytes for anything
+// else). Headers in arena memory are invisible to the garbage collector,
+// and pointing them at a heap buffer nothing else references lets the
+// buffer be collected under them. With diagnostics enabled (SetDebugLevel,
+// WithPoisoning and the like) splitting memory the arena does not own, or
+// that a reset has invalidated, panics. As with the bytes package, the
+// capacity of each piece ends at the piece, so appending to one never
+// overwrites the next.
+
+// Split is SplitN(a, b, sep, -1).
+func Split(a *Arena, b []byte, sep byte) [][]byte {
+	return SplitN(a, b, sep, -1)
+}
+
+// SplitN is bytes.SplitN for a single-byte separator with the result in
+// the arena: it slices b into the pieces between occurrences of sep and
+// returns at most n of them, the last holding the unsplit remainder. If
+// n < 0 all pieces are returned; if n == 0 the result is nil.
+func SplitN(a *Arena, b []byte, sep byte, n int) [][]byte {
+	if n == 0 {
+		return nil
+	}
+	a.checkAliased("SplitN", b)
+	if m := bytes.Count(b, []byte{sep}) + 1; n < 0 || n > m {
+		n = m
+	}
+	out := allocSpine[[]byte](a, n)
+	for i := range n - 1 {
+		j := bytes.IndexByte(b, sep)
+		out[i] = b[:j:j]
+		b = b[j+1:]
+	}
+	out[n-1] = b
+	return out
+}
+
+// Fields is bytes.Fields with the result in the arena: it returns the
+// runs of b separated by white space, as defined by unicode.IsSpace, or
+// nil if b holds only white space.
+func Fields(a *Arena, b []byte) [][]byte {
+	return FieldsFunc(a, b, unicode.IsSpace)
+}
+
+// FieldsFunc is bytes.FieldsFunc with the result in the arena: it returns
+// the runs of b separated by runes satisfying f, or nil if every rune does.
+// f is called twice per rune and must be deterministic.
+func FieldsFunc(a *Arena, b []byte, f func(rune) bool) [][]byte {
+	a.checkAliased("FieldsFunc", b)
+	n := 0
+	eachField(b, f, func(int, int) { n++ })
+	if n == 0 {
+		return nil
+	}
+	out := allocSpine[[]byte](a, n)
+	i := 0
+	eachField(b, f, func(start, end int) {
+		out[i] = b[start:end:end]
+		i++
+	})
+	return out
+}
+
+// eachField calls fn with the bounds of every field of b.
+func eachField(b []byte, isSep func(rune) bool, fn func(start, end int)) {
+	start := -1
+	for i := 0; i < len(b); {
+		r, size := rune(b[i]), 1
+		if r >= utf8.RuneSelf {
+			r, size = utf8.DecodeRune(b[i:])
+		}
+		if isSep(r) {
+			if start >= 0 {
+				fn(start, i)
+				start = -1
+			}
+		} else if start < 0 {
+			start = i
+		}
+		i += size
+	}
+	if start >= 0 {
+		fn(start, len(b))
+	}
+}
+
+// checkAliased panics, when diagnostics are enabled, if b is not memory
+// allocated from the arena in the current cycle.
+func (a *Arena) checkAliased(op string, b []byte) {
+	if a.debug == nil || len(b) == 0 {
+		return
+	}
+	if err := a.liveBytes(b); err != nil {
+		a.panicWithEvents(fmt.Sprintf("arena: %s of memory the arena cannot keep alive: %v", op, err))
+	}
+}
+
+// liveBytes reports whether b lies within memory allocated from the
+// arena and not yet invalidated, returning ErrNotOwned or an error
+// wrapping ErrStalePointer if not. Chunks retired by ResetPrepare stay
+// valid until ResetCommit, and pinned chunks until unpinned.
+func (a *Arena) liveBytes(b []byte) error {
+	start := uintptr(unsafe.Pointer(unsafe.SliceData(b)))
+	end := start + uintptr(len(b))
+	for _, set := range [][]chunk{a.chunks, a.retired, a.pinned, a.spare} {
+		for i := range set {
+			c := &set[i]
+			base := uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+			if start < base || start >= base+uintptr(len(c.buf)) {
+				continue
+			}
+			if end > base+c.offset { // spare chunks are empty
+				return a.staleError(0, false)
+			}
+			return nil
+		}
+	}
+	return ErrNotOwned
+}
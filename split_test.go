@@ -0,0 +1,107 @@
+package arena
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestSplitN(t *testing.T) {
+	a := NewArena(4096)
+	tests := []struct {
+		in  string
+		sep byte
+		n   int
+	}{
+		{"a,b,c", ',', -1},
+		{"a,b,c", ',', 2},
+		{"a,b,c", ',', 10},
+		{"a,b,c", ',', 0},
+		{",a,,b,", ',', -1},
+		{"abc", ',', -1},
+		{"", ',', -1},
+	}
+	for _, tt := range tests {
+		b := CloneBytes(a, []byte(tt.in))
+		got := SplitN(a, b, tt.sep, tt.n)
+		want := bytes.SplitN([]byte(tt.in), []byte{tt.sep}, tt.n)
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+			t.Errorf("SplitN(%q, %q, %d) = %q, want %q", tt.in, tt.sep, tt.n, got, want)
+		}
+	}
+
+	// Pieces alias b, and appending to one leaves the next alone.
+	b := CloneBytes(a, []byte("key=value"))
+	kv := Split(a, b, '=')
+	b[0] = 'K'
+	if string(kv[0]) != "Key" {
+		t.Errorf("piece = %q, want it to alias the input", kv[0])
+	}
+	_ = append(kv[0], 'X')
+	if string(kv[1]) != "value" {
+		t.Errorf("second piece = %q after appending to the first", kv[1])
+	}
+}
+
+func TestFields(t *testing.T) {
+	a := NewArena(4096)
+	for _, in := range []string{"  GET /index.html\tHTTP/1.1\r\n", "", "   ", "one", "a b c"} {
+		got := Fields(a, CloneBytes(a, []byte(in)))
+		want := bytes.Fields([]byte(in))
+		if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+			t.Errorf("Fields(%q) = %q, want %q", in, got, want)
+		}
+	}
+	got := FieldsFunc(a, CloneBytes(a, []byte("a1b22c")), unicode.IsDigit)
+	if fmt.Sprintf("%q", got) != `["a" "b" "c"]` {
+		t.Errorf("FieldsFunc = %q", got)
+	}
+}
+
+func TestSplitAliasingChecks(t *testing.T) {
+	a := NewArena(1024, WithPoisoning())
+	b := CloneBytes(a, []byte("x y"))
+	if got := Fields(a, b); len(got) != 2 {
+		t.Fatalf("Fields = %q", got)
+	}
+
+	heap := []byte("x y")
+	msg := panicMessage(func() { Fields(a, heap) })
+	if !strings.Contains(msg, ErrNotOwned.Error()) {
+		t.Errorf("splitting heap memory panicked with %q, want ErrNotOwned", msg)
+	}
+
+	a.Reset()
+	msg = panicMessage(func() { Split(a, b, ' ') })
+	if !strings.Contains(msg, ErrStalePointer.Error()) {
+		t.Errorf("splitting reset memory panicked with %q, want ErrStalePointer", msg)
+	}
+
+	// Without diagnostics nothing is checked.
+	plain := NewArena(1024)
+	if got := Split(plain, heap, ' '); len(got) != 2 {
+		t.Errorf("Split = %q", got)
+	}
+}
+
+func BenchmarkSplit(b *testing.B) {
+	line := []byte("2024-01-02,GET,/api/v1/items,200,512,0.004")
+	b.Run("bytes", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bytes.Split(line, []byte{','})
+		}
+	})
+	b.Run("Arena", func(b *testing.B) {
+		a := NewArena(1 << 20)
+		buf := CloneBytes(a, line)
+		for i := 0; i < b.N; i++ {
+			Split(a, buf, ',')
+			if i%1000 == 999 {
+				a.Reset()
+				buf = CloneBytes(a, line)
+			}
+		}
+	})
+}
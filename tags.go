@@ -0,0 +1,123 @@
+package arena
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// Memory tag layout. Every tagged chunk starts with a fixed-size header:
+//
+//	[0:8]   magic "ARENATAG"
+//	[8:12]  format version (uint32, little endian)
+//	[12:16] chunk index (uint32)
+//	[16:24] arena generation (uint64)
+//	[24:32] chunk size in bytes (uint64)
+//	[32:40] bytes in use, including the header (uint64)
+//	[40:64] arena name, NUL padded
+const (
+	chunkTagSize    = 64
+	chunkTagVersion = 1
+	chunkTagNameLen = 24
+)
+
+var chunkTagMagic = [8]byte{'A', 'R', 'E', 'N', 'A', 'T', 'A', 'G'}
+
+// ChunkTag is a decoded memory tag header.
+type ChunkTag struct {
+	Offset     int    // Position of the header within the scanned image
+	Name       string // Arena name set with WithName
+	Chunk      int    // Chunk index within the arena
+	Generation uint64 // Arena generation when the header was last written
+	Size       int    // Chunk size in bytes
+	Used       int    // Bytes in use when the header was last written
+}
+
+// WithName names the arena. The name is recorded in memory tags.
+func WithName(name string) Option {
+	return func(a *Arena) {
+		a.name = name
+	}
+}
+
+// WithMemoryTags makes the arena start every chunk with a ChunkTag header
+// identifying the arena, chunk and generation and tracking how full the
+// chunk is, so core dumps can be attributed with ScanChunkTags. Tags are
+// also enabled by DebugFull. Tagging takes the diagnostics allocation path.
+func WithMemoryTags() Option {
+	return func(a *Arena) {
+		a.enableMemoryTags()
+	}
+}
+
+// Name returns the name set with WithName.
+func (a *Arena) Name() string {
+	return a.name
+}
+
+func (a *Arena) enableMemoryTags() {
+	a.debugState().tags = true
+	a.chunkBase = chunkTagSize
+}
+
+// writeChunkTags rewrites the headers of all chunks.
+func (a *Arena) writeChunkTags() {
+	for i := range a.chunks {
+		a.writeChunkTag(i)
+	}
+}
+
+// writeChunkTag writes the header of chunk i.
+func (a *Arena) writeChunkTag(i int) {
+	c := &a.chunks[i]
+	h := c.buf[:chunkTagSize]
+	copy(h[0:8], chunkTagMagic[:])
+	binary.LittleEndian.PutUint32(h[8:12], chunkTagVersion)
+	binary.LittleEndian.PutUint32(h[12:16], uint32(i))
+	binary.LittleEndian.PutUint64(h[16:24], a.generation)
+	binary.LittleEndian.PutUint64(h[24:32], uint64(len(c.buf)))
+	name := h[40:chunkTagSize]
+	clear(name)
+	copy(name, a.name)
+	putTagUsed(c)
+}
+
+// putTagUsed refreshes the in-use field of a tagged chunk's header.
+func putTagUsed(c *chunk) {
+	binary.LittleEndian.PutUint64(c.buf[32:40], uint64(c.offset))
+}
+
+// ScanChunkTags finds and decodes arena memory tags in a raw memory image,
+// such as a region of a core dump. Headers are searched at 8-byte aligned
+// positions; candidates with an unknown version are skipped.
+func ScanChunkTags(image []byte) []ChunkTag {
+	var tags []ChunkTag
+	for off := 0; off+chunkTagSize <= len(image); {
+		i := bytes.Index(image[off:], chunkTagMagic[:])
+		if i < 0 {
+			break
+		}
+		pos := off + i
+		off = pos + 1
+		if pos%8 != 0 || pos+chunkTagSize > len(image) {
+			continue
+		}
+		h := image[pos : pos+chunkTagSize]
+		if binary.LittleEndian.Uint32(h[8:12]) != chunkTagVersion {
+			continue
+		}
+		name := h[40:chunkTagSize]
+		if n := bytes.IndexByte(name, 0); n >= 0 {
+			name = name[:n]
+		}
+		tags = append(tags, ChunkTag{
+			Offset:     pos,
+			Name:       string(name),
+			Chunk:      int(binary.LittleEndian.Uint32(h[12:16])),
+			Generation: binary.LittleEndian.Uint64(h[16:24]),
+			Size:       int(binary.LittleEndian.Uint64(h[24:32])),
+			Used:       int(binary.LittleEndian.Uint64(h[32:40])),
+		})
+		off = pos + chunkTagSize
+	}
+	return tags
+}
@@ -0,0 +1,55 @@
+package arena
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMemoryTags(t *testing.T) {
+	a := NewArena(256, WithName("requests"), WithMemoryTags())
+	if a.Name() != "requests" {
+		t.Errorf("Name() = %q, want %q", a.Name(), "requests")
+	}
+
+	a.AllocBytes(100)
+	a.AllocBytes(300) // second chunk
+	a.Reset()
+	a.AllocBytes(10)
+
+	var image bytes.Buffer
+	image.Write(make([]byte, 40)) // unrelated leading memory
+	for _, c := range a.chunks {
+		image.Write(c.buf)
+	}
+
+	tags := ScanChunkTags(image.Bytes())
+	if len(tags) != 2 {
+		t.Fatalf("ScanChunkTags found %d tags, want 2", len(tags))
+	}
+	first := tags[0]
+	if first.Name != "requests" || first.Chunk != 0 || first.Generation != 1 {
+		t.Errorf("first tag = %+v", first)
+	}
+	if first.Offset != 40 || first.Size != 256+chunkTagSize || first.Used != chunkTagSize+10 {
+		t.Errorf("first tag placement = %+v", first)
+	}
+	if tags[1].Chunk != 1 || tags[1].Used != chunkTagSize {
+		t.Errorf("second tag = %+v", tags[1])
+	}
+}
+
+func TestMemoryTagsDoNotOverlapAllocations(t *testing.T) {
+	a := NewArena(128, WithMemoryTags())
+	b := a.AllocBytes(128)
+	for i := range b {
+		b[i] = 0xFF
+	}
+	a.Reset()
+
+	if tags := ScanChunkTags(a.chunks[0].buf); len(tags) != 1 {
+		t.Errorf("header corrupted by allocation: %d tags found", len(tags))
+	}
+	if a.GrowthPolicy().NextChunkSize != 128 {
+		t.Errorf("tags changed the chunk size policy")
+	}
+}
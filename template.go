@@ -0,0 +1,52 @@
+package arena
+
+// NewArenaFromTemplate creates an Arena preloaded with template.
+// See Preload.
+func NewArenaFromTemplate(template []byte, chunkSize int, opts ...Option) *Arena {
+	a := NewArena(chunkSize, opts...)
+	a.Preload(template)
+	return a
+}
+
+// Preload makes template the arena's seed image: after every Reset, the
+// first chunk starts with a copy of template and regular allocations follow
+// it. Use it for per-request structures that are identical every time, such
+// as a prebuilt header block or lookup table. The template is copied, so the
+// caller may reuse it. Preload resets the arena, invalidating all previous
+// allocations. A nil or empty template removes the seed image.
+func (a *Arena) Preload(template []byte) {
+	a.panicIfReleased()
+	if len(template) == 0 {
+		a.template = nil
+		a.Reset()
+		return
+	}
+	a.template = append([]byte(nil), template...)
+	if len(a.chunks) == 0 {
+		a.grow(a.nextChunk) // lazily initialized arena
+	}
+	if c := &a.chunks[0]; len(c.buf) < int(a.chunkBase)+len(template) {
+		a.freeChunkBuf(c.buf)
+		c.buf = a.newChunkBuf(int(a.chunkBase) + len(template))
+	}
+	a.Reset()
+}
+
+// Template returns the seed image inside the arena, or nil if the arena has
+// no template. Changes made through the returned slice last until the next
+// Reset.
+func (a *Arena) Template() []byte {
+	if a.template == nil || len(a.chunks) == 0 {
+		return nil
+	}
+	end := int(a.chunkBase) + len(a.template)
+	return a.chunks[0].buf[a.chunkBase:end:end]
+}
+
+// applyTemplate copies the template to the start of the current chunk,
+// which must be empty and large enough.
+func (a *Arena) applyTemplate() {
+	c := a.currentChunk
+	copy(c.buf[a.chunkBase:], a.template)
+	c.offset = a.chunkBase + uintptr(len(a.template))
+}
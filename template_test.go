@@ -0,0 +1,51 @@
+package arena
+
+import "testing"
+
+func TestPreload(t *testing.T) {
+	a := NewArenaFromTemplate([]byte("HEADER"), 1024)
+
+	if got := string(a.Template()); got != "HEADER" {
+		t.Fatalf("Template() = %q, want %q", got, "HEADER")
+	}
+	if a.SizeInUse() != 6 {
+		t.Errorf("SizeInUse = %d, want 6", a.SizeInUse())
+	}
+
+	// Allocations never overlap the template
+	b := a.AllocBytes(10)
+	for i := range b {
+		b[i] = 'x'
+	}
+	a.Template()[0] = 'h'
+
+	// Reset restores the pristine template
+	a.Reset()
+	if got := string(a.Template()); got != "HEADER" {
+		t.Errorf("Template() after Reset = %q, want %q", got, "HEADER")
+	}
+	if a.SizeInUse() != 6 {
+		t.Errorf("SizeInUse after Reset = %d, want 6", a.SizeInUse())
+	}
+
+	a.Preload(nil)
+	if a.Template() != nil || a.SizeInUse() != 0 {
+		t.Errorf("after Preload(nil): Template() = %v, SizeInUse = %d", a.Template(), a.SizeInUse())
+	}
+}
+
+func TestPreloadLargerThanChunk(t *testing.T) {
+	tmpl := make([]byte, 300)
+	tmpl[299] = 7
+	a := NewArenaFromTemplate(tmpl, 128)
+
+	if got := a.Template(); len(got) != 300 || got[299] != 7 {
+		t.Fatalf("Template() length = %d, want 300 with trailing 7", len(got))
+	}
+
+	a.ResetPrepare()
+	if got := a.Template(); len(got) != 300 || got[299] != 7 {
+		t.Errorf("Template() after ResetPrepare length = %d, want 300", len(got))
+	}
+	a.ResetCommit()
+}
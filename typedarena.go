@@ -0,0 +1,108 @@
+package arena
+
+import (
+	"iter"
+	"unsafe"
+)
+
+// TypedArena allocates values of a single type T from an arena in blocks,
+// for builders that create many values of one node type. New bumps an
+// index within the current block instead of going through AllocBytes, and
+// All iterates every value allocated so far, in allocation order. Blocks
+// take up to an eighth of the arena's chunk size. Like a Vector, a
+// TypedArena used after the arena is reset panics unless Clear is called
+// first. A TypedArena is not goroutine-safe.
+type TypedArena[T any] struct {
+	a        *Arena
+	guard    GenerationGuard
+	blocks   [][]T // used part of each block; the last one is current
+	n        int
+	blockLen int
+}
+
+// NewTypedArena returns a TypedArena allocating from a.
+func NewTypedArena[T any](a *Arena) *TypedArena[T] {
+	checkPointers[T]()
+	var zero T
+	blockLen := 1
+	if size := int(unsafe.Sizeof(zero)); size > 0 {
+		blockLen = max(a.chunkSize/8/size, 1)
+	}
+	return &TypedArena[T]{a: a, guard: a.Guard(), blockLen: blockLen}
+}
+
+// New returns a pointer to a new zeroed T. Blocks are zeroed when they
+// are allocated, so New itself only advances an index.
+func (t *TypedArena[T]) New() *T {
+	t.guard.Check()
+	if k := len(t.blocks); k > 0 {
+		if b := t.blocks[k-1]; len(b) < cap(b) {
+			b = b[:len(b)+1]
+			t.blocks[k-1] = b
+			t.n++
+			return &b[len(b)-1]
+		}
+	}
+	b := AllocSliceZeroed[T](t.a, t.blockLen)[:1]
+	t.blocks = append(t.blocks, b)
+	t.n++
+	return &b[0]
+}
+
+// NewSlice returns n contiguous zeroed values, or nil if n <= 0. Slices
+// that do not fit in the current block start a new one, at least n long.
+func (t *TypedArena[T]) NewSlice(n int) []T {
+	if n <= 0 {
+		return nil
+	}
+	t.guard.Check()
+	if k := len(t.blocks); k > 0 {
+		if b := t.blocks[k-1]; cap(b)-len(b) >= n {
+			t.blocks[k-1] = b[:len(b)+n]
+			t.n += n
+			return b[len(b) : len(b)+n : len(b)+n]
+		}
+	}
+	size := max(n, t.blockLen)
+	b := AllocSliceZeroed[T](t.a, size)[:n]
+	t.blocks = append(t.blocks, b)
+	t.n += n
+	return b[:n:n]
+}
+
+// Len returns the number of values allocated since the TypedArena was
+// created or cleared.
+func (t *TypedArena[T]) Len() int {
+	t.guard.Check()
+	return t.n
+}
+
+// All returns an iterator over pointers to every value allocated since the
+// TypedArena was created or cleared, in allocation order. Values allocated during
+// iteration may or may not be visited. Resetting the arena during
+// iteration makes the next step panic instead of visiting recycled memory.
+func (t *TypedArena[T]) All() iter.Seq[*T] {
+	return func(yield func(*T) bool) {
+		g := t.guard
+		g.Check()
+		for i := 0; i < len(t.blocks); i++ {
+			b := t.blocks[i]
+			for j := range b {
+				if !yield(&b[j]) {
+					return
+				}
+				g.Check()
+			}
+		}
+	}
+}
+
+// Clear forgets every value. Their memory is not reused until the arena
+// is reset. After the arena is reset, Clear makes the TypedArena usable
+// again.
+func (t *TypedArena[T]) Clear() {
+	clear(t.blocks)
+	t.blocks = t.blocks[:0]
+	t.n = 0
+	t.guard = t.a.Guard()
+}
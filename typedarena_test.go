@@ -0,0 +1,113 @@
+package arena
+
+import (
+	"strings"
+	"testing"
+)
+
+type typedNode struct {
+	id          int
+	left, right int32
+}
+
+func TestTypedArena(t *testing.T) {
+	a := NewArena(1024) // blocks of 128 bytes: 8 nodes
+	ta := NewTypedArena[typedNode](a)
+	for i := 0; i < 20; i++ {
+		n := ta.New()
+		if n.id != 0 {
+			t.Fatalf("New() returned non-zero value %+v", *n)
+		}
+		n.id = i
+	}
+	s := ta.NewSlice(3)
+	for i := range s {
+		s[i].id = 20 + i
+	}
+	big := ta.NewSlice(30)
+	for i := range big {
+		big[i].id = 23 + i
+	}
+	ta.New().id = 53
+
+	if ta.Len() != 54 {
+		t.Errorf("Len() = %d, want 54", ta.Len())
+	}
+	want := 0
+	for n := range ta.All() {
+		if n.id != want {
+			t.Fatalf("All() yielded id %d, want %d", n.id, want)
+		}
+		want++
+	}
+	if want != 54 {
+		t.Errorf("All() yielded %d values, want 54", want)
+	}
+	for range ta.All() {
+		break // early exit must not panic
+	}
+
+	a.Reset()
+	for name, f := range map[string]func(){
+		"Len": func() { ta.Len() },
+		"New": func() { ta.New() },
+		"All": func() {
+			for range ta.All() {
+			}
+		},
+	} {
+		if msg := panicMessage(f); !strings.Contains(msg, "used after Reset") {
+			t.Errorf("%s after Reset panicked with %q, want use after Reset", name, msg)
+		}
+	}
+	ta.Clear()
+	if ta.Len() != 0 {
+		t.Errorf("Len() after Reset and Clear = %d, want 0", ta.Len())
+	}
+	for range ta.All() {
+		t.Fatal("All() yielded a value after Reset and Clear")
+	}
+	if n := ta.New(); n.id != 0 {
+		t.Errorf("New() after Reset and Clear = %+v, want zeroed", *n)
+	}
+}
+
+func TestTypedArenaResetDuringAll(t *testing.T) {
+	a := NewArena(1024)
+	ta := NewTypedArena[typedNode](a)
+	for range 3 {
+		ta.New()
+	}
+	msg := panicMessage(func() {
+		for range ta.All() {
+			a.Reset()
+		}
+	})
+	if !strings.Contains(msg, "used after Reset") {
+		t.Errorf("Reset during All() panicked with %q, want use after Reset", msg)
+	}
+}
+
+func BenchmarkTypedArenaNew(b *testing.B) {
+	a := NewArena(1 << 20)
+	ta := NewTypedArena[typedNode](a)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ta.New()
+		if i%10000 == 9999 {
+			a.Reset()
+			ta.Clear()
+		}
+	}
+}
+
+func BenchmarkTypedArenaAlloc(b *testing.B) {
+	a := NewArena(1 << 20)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		Alloc[typedNode](a)
+		if i%10000 == 9999 {
+			a.Reset()
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package arena
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// Generation stamp layout. Under DebugFull every allocation is preceded by:
+//
+//	[0:8]   magic "ARENAGEN"
+//	[8:16]  requested size in bytes (uint64, little endian)
+//	[16:24] arena generation at allocation time (uint64)
+const stampSize = 24
+
+var stampMagic = [8]byte{'A', 'R', 'E', 'N', 'A', 'G', 'E', 'N'}
+
+var (
+	// ErrNotOwned is returned by Validate for pointers outside the arena.
+	ErrNotOwned = errors.New("arena: pointer not owned by this arena")
+	// ErrStalePointer is wrapped by Validate errors for pointers to memory
+	// that was invalidated by a Reset, ResetCommit or Release.
+	ErrStalePointer = errors.New("arena: stale pointer")
+	// ErrNoStamps is returned by Validate on arenas created without DebugFull.
+	ErrNoStamps = errors.New("arena: Validate requires generation stamps (DebugFull)")
+)
+
+// Validate reports whether p points into memory currently allocated from
+// the arena, for tracking down lifetime bugs. It requires an arena created
+// under DebugFull, which stamps every allocation with the generation it
+// was made in. For a pointer into memory handed out before the last reset
+// the error wraps ErrStalePointer and names both generations, e.g.
+// "stale pointer (allocated in generation 3, arena now at 7)"; for a
+// pointer outside the arena it is ErrNotOwned.
+//
+// Validation is best effort: once reset memory has been allocated again,
+// a stale pointer to the start of a new allocation is indistinguishable
+// from a fresh one.
+func (a *Arena) Validate(p unsafe.Pointer) error {
+	if a.debug == nil || !a.debug.stamps {
+		return ErrNoStamps
+	}
+	addr := uintptr(p)
+	for _, set := range [][]chunk{a.chunks, a.retired, a.spare, a.pinned} {
+		for i := range set {
+			c := &set[i]
+			base := uintptr(unsafe.Pointer(unsafe.SliceData(c.buf)))
+			if addr >= base && addr < base+uintptr(len(c.buf)) {
+				return a.validateIn(c, addr-base)
+			}
+		}
+	}
+	return ErrNotOwned
+}
+
+// validateIn validates the pointer at offset off of chunk c by walking the
+// stamped allocations from the start of the chunk.
+func (a *Arena) validateIn(c *chunk, off uintptr) error {
+	pos := a.chunkBase
+	if len(a.chunks) > 0 && c == &a.chunks[0] && a.template != nil {
+		pos = alignPtr(pos + uintptr(len(a.template)))
+	}
+	var guard uintptr
+	if a.debug.canaries {
+		guard = canarySize
+	}
+	for pos+stampSize <= uintptr(len(c.buf)) && off >= pos {
+		hdr := c.buf[pos : pos+stampSize]
+		if [8]byte(hdr[:8]) != stampMagic {
+			break
+		}
+		size := uintptr(binary.LittleEndian.Uint64(hdr[8:]))
+		gen := binary.LittleEndian.Uint64(hdr[16:])
+		start := pos + stampSize
+		end := start + size
+		if off < end {
+			if off < start {
+				break // inside the stamp itself
+			}
+			if gen != a.generation || end > c.offset {
+				return a.staleError(gen, true)
+			}
+			return nil
+		}
+		pos = alignPtr(end + guard)
+	}
+	if off < c.offset {
+		return nil // unstamped live memory, such as a template
+	}
+	return a.staleError(0, false)
+}
+
+// staleError describes a pointer into invalidated memory, naming the
+// generation it was allocated in if known.
+func (a *Arena) staleError(gen uint64, known bool) error {
+	if !known {
+		return fmt.Errorf("%w (arena now at generation %d)", ErrStalePointer, a.generation)
+	}
+	return fmt.Errorf("%w (allocated in generation %d, arena now at %d)", ErrStalePointer, gen, a.generation)
+}
+
+// putStamp writes the generation stamp for an n-byte allocation to the
+// start of b.
+func putStamp(b []byte, n int, gen uint64) {
+	copy(b, stampMagic[:])
+	binary.LittleEndian.PutUint64(b[8:], uint64(n))
+	binary.LittleEndian.PutUint64(b[16:], gen)
+}
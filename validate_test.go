@@ -0,0 +1,43 @@
+package arena
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestValidate(t *testing.T) {
+	SetDebugLevel(DebugFull)
+	a := NewArena(1024)
+	SetDebugLevel(DebugOff)
+
+	p := Alloc[int64](a)
+	s := AllocSlice[int32](a, 8)
+	if err := a.Validate(unsafe.Pointer(p)); err != nil {
+		t.Errorf("Validate(live) = %v, want nil", err)
+	}
+	if err := a.Validate(unsafe.Pointer(&s[5])); err != nil {
+		t.Errorf("Validate(interior) = %v, want nil", err)
+	}
+
+	var heap int64
+	if err := a.Validate(unsafe.Pointer(&heap)); !errors.Is(err, ErrNotOwned) {
+		t.Errorf("Validate(heap) = %v, want ErrNotOwned", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		a.Reset()
+	}
+	err := a.Validate(unsafe.Pointer(&s[5]))
+	if !errors.Is(err, ErrStalePointer) || !strings.Contains(err.Error(), "allocated in generation 0, arena now at 3") {
+		t.Errorf("Validate(stale) = %v, want stale pointer from generation 0", err)
+	}
+}
+
+func TestValidateWithoutStamps(t *testing.T) {
+	a := NewArena(1024)
+	if err := a.Validate(unsafe.Pointer(Alloc[int64](a))); err != ErrNoStamps {
+		t.Errorf("Validate without DebugFull = %v, want ErrNoStamps", err)
+	}
+}
@@ -0,0 +1,90 @@
+package arena
+
+// Vector is a growable slice of T whose storage lives in an arena. Growth
+// goes through Append, so it extends in place when the vector is the most
+// recent allocation in the current chunk and otherwise moves to a larger
+// arena allocation, never touching the heap. Using a Vector after the
+// arena is reset panics, like any GenerationGuard check, unless Clear is
+// called first: Clear starts it over empty, so one Vector can serve every
+// batch of a Reset loop. A Vector is not goroutine-safe.
+type Vector[T any] struct {
+	a     *Arena
+	s     []T
+	guard GenerationGuard
+}
+
+// NewVector returns an empty Vector with room for capHint elements before
+// it first grows.
+func NewVector[T any](a *Arena, capHint int) *Vector[T] {
+	checkPointers[T]()
+	v := &Vector[T]{a: a, guard: a.Guard()}
+	if capHint > 0 {
+		v.s = AllocSlice[T](a, capHint)[:0]
+	}
+	return v
+}
+
+// Push appends x to the end of the vector.
+func (v *Vector[T]) Push(x T) {
+	v.guard.Check()
+	if len(v.s) < cap(v.s) {
+		v.s = append(v.s, x)
+		return
+	}
+	v.s = Append(v.a, v.s, x)
+}
+
+// Append appends xs to the end of the vector.
+func (v *Vector[T]) Append(xs ...T) {
+	v.guard.Check()
+	v.s = Append(v.a, v.s, xs...)
+}
+
+// Pop removes and returns the last element. It returns false if the
+// vector is empty.
+func (v *Vector[T]) Pop() (T, bool) {
+	v.guard.Check()
+	var x T
+	if len(v.s) == 0 {
+		return x, false
+	}
+	x = v.s[len(v.s)-1]
+	v.s = v.s[:len(v.s)-1]
+	return x, true
+}
+
+// Len returns the number of elements.
+func (v *Vector[T]) Len() int {
+	v.guard.Check()
+	return len(v.s)
+}
+
+// At returns element i. Panics if i is out of range.
+func (v *Vector[T]) At(i int) T {
+	v.guard.Check()
+	return v.s[i]
+}
+
+// Set sets element i to x. Panics if i is out of range.
+func (v *Vector[T]) Set(i int, x T) {
+	v.guard.Check()
+	v.s[i] = x
+}
+
+// Slice returns the elements as a slice aliasing the vector's storage. It
+// is valid until the vector next grows or the arena is reset.
+func (v *Vector[T]) Slice() []T {
+	v.guard.Check()
+	return v.s
+}
+
+// Clear removes every element, keeping the storage for reuse. After the
+// arena is reset it drops the storage instead, making the vector usable
+// again.
+func (v *Vector[T]) Clear() {
+	if !v.guard.Valid() {
+		v.s = nil
+		v.guard = v.a.Guard()
+	}
+	v.s = v.s[:0]
+}
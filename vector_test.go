@@ -0,0 +1,85 @@
+package arena
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestVector(t *testing.T) {
+	a := NewArena(4096)
+	v := NewVector[int](a, 2)
+	for i := range 100 {
+		v.Push(i)
+	}
+	v.Append(100, 101)
+	if v.Len() != 102 || v.At(50) != 50 || v.At(101) != 101 {
+		t.Fatalf("Len = %d, At(50) = %d, At(101) = %d", v.Len(), v.At(50), v.At(101))
+	}
+	v.Set(0, -1)
+	if v.Slice()[0] != -1 {
+		t.Errorf("Slice()[0] = %d after Set, want -1", v.Slice()[0])
+	}
+	if x, ok := v.Pop(); !ok || x != 101 || v.Len() != 101 {
+		t.Errorf("Pop = %d, %v with %d left, want 101, true with 101 left", x, ok, v.Len())
+	}
+	v.Clear()
+	if _, ok := v.Pop(); ok || v.Len() != 0 {
+		t.Error("Pop on a cleared vector succeeded")
+	}
+	if a.NumChunks() != 1 {
+		t.Errorf("NumChunks = %d, want growth to stay in the first chunk", a.NumChunks())
+	}
+
+	v.Push(7)
+	a.Reset()
+	for name, f := range map[string]func(){
+		"Len":  func() { v.Len() },
+		"Push": func() { v.Push(1) },
+		"Pop":  func() { v.Pop() },
+		"At":   func() { v.At(0) },
+	} {
+		if msg := panicMessage(f); !strings.Contains(msg, "used after Reset") {
+			t.Errorf("%s after Reset panicked with %q, want use after Reset", name, msg)
+		}
+	}
+	v.Clear()
+	if v.Len() != 0 {
+		t.Errorf("Len after Reset and Clear = %d, want 0", v.Len())
+	}
+	v.Append(1, 2, 3)
+	if !slices.Equal(v.Slice(), []int{1, 2, 3}) {
+		t.Errorf("Slice after Reset = %v", v.Slice())
+	}
+}
+
+func TestVectorRecycledStorage(t *testing.T) {
+	a := NewArena(4096)
+	v := NewVector[int64](a, 4)
+	v.Append(1, 2, 3)
+	a.Reset()
+	v.Clear()
+	// Takes over the memory the vector had before the reset.
+	other := AllocSlice[int64](a, 4)
+	for i := range other {
+		other[i] = -1
+	}
+	v.Push(7)
+	if v.Len() != 1 || v.At(0) != 7 {
+		t.Errorf("vector after Reset and Clear = %v, want [7]", v.Slice())
+	}
+	if !slices.Equal(other, []int64{-1, -1, -1, -1}) {
+		t.Errorf("vector wrote to recycled memory: %v", other)
+	}
+}
+
+func BenchmarkVectorPush(b *testing.B) {
+	a := NewArena(1 << 20)
+	for i := 0; i < b.N; i++ {
+		v := NewVector[int](a, 0)
+		for j := range 1000 {
+			v.Push(j)
+		}
+		a.Reset()
+	}
+}
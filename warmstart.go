@@ -0,0 +1,60 @@
+package arena
+
+// WithWarmStart makes the arena remember the bytes used by each of its
+// last k allocation cycles, a cycle being the allocations between two
+// resets, so SuggestedEnsure can pre-size the next one. Handlers that
+// reuse an arena across requests call EnsureCapacity once at the top of
+// each request so typical requests run in a single chunk and growth
+// mid-request becomes rare:
+//
+//	a.EnsureCapacity(a.SuggestedEnsure())
+//
+// k < 1 is treated as 1. The history survives resets and the trip through
+// an ArenaPool.
+func WithWarmStart(k int) Option {
+	return func(a *Arena) {
+		a.warm = &cycleHistory{used: make([]int, max(k, 1))}
+	}
+}
+
+// cycleHistory is a ring of the bytes used by recent cycles.
+type cycleHistory struct {
+	used []int
+	next int
+	n    int // cycles recorded, up to len(used)
+}
+
+// record adds a cycle that used n bytes, evicting the oldest if full.
+func (h *cycleHistory) record(n int) {
+	h.used[h.next] = n
+	h.next = (h.next + 1) % len(h.used)
+	h.n = min(h.n+1, len(h.used))
+}
+
+// peak returns the most bytes used by a recorded cycle.
+func (h *cycleHistory) peak() int {
+	m := 0
+	for _, n := range h.used[:h.n] {
+		m = max(m, n)
+	}
+	return m
+}
+
+// recordCycle adds the cycle ending now to the warm-start history.
+func (a *Arena) recordCycle() {
+	if a.warm != nil {
+		a.warm.record(max(a.usedBytes(a.chunks)-len(a.template), 0))
+	}
+}
+
+// SuggestedEnsure returns how many bytes to pass to EnsureCapacity at the
+// start of a cycle so that a cycle as large as any of the last k recorded
+// by WithWarmStart fits in one chunk. Alignment padding is included; the
+// Preload template, which is restored by the reset, is not. Returns 0
+// without WithWarmStart or before the first reset.
+func (a *Arena) SuggestedEnsure() int {
+	if a.warm == nil {
+		return 0
+	}
+	return a.warm.peak()
+}
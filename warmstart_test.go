@@ -0,0 +1,63 @@
+package arena
+
+import "testing"
+
+func TestWarmStart(t *testing.T) {
+	a := NewArena(256, WithWarmStart(3))
+	defer a.Release()
+	if n := a.SuggestedEnsure(); n != 0 {
+		t.Errorf("SuggestedEnsure before any cycle = %d, want 0", n)
+	}
+
+	cycle := func(sizes ...int) {
+		a.EnsureCapacity(a.SuggestedEnsure())
+		chunks := a.NumChunks()
+		for _, n := range sizes {
+			a.AllocBytes(n)
+		}
+		if a.SuggestedEnsure() > 0 && a.NumChunks() != chunks {
+			t.Errorf("cycle %v added chunks after EnsureCapacity(%d)", sizes, a.SuggestedEnsure())
+		}
+		a.Reset()
+	}
+
+	cycle(200, 200, 200) // 600 bytes over several chunks
+	if n := a.SuggestedEnsure(); n != 600 {
+		t.Errorf("SuggestedEnsure = %d, want 600", n)
+	}
+	cycle(100, 100, 100)
+	cycle(8)
+	cycle(8)
+	if n := a.SuggestedEnsure(); n != 308 { // two 100-byte allocations padded to 104
+		t.Errorf("SuggestedEnsure after the 600-byte cycle aged out = %d, want 308", n)
+	}
+	cycle(8)
+	if n := a.SuggestedEnsure(); n != 8 {
+		t.Errorf("SuggestedEnsure = %d, want 8", n)
+	}
+
+	// Repeated warm starts reuse the chunk added by the first one.
+	cycle(500)
+	cycle(500)
+	chunks := a.NumChunks()
+	for i := 0; i < 5; i++ {
+		cycle(500)
+	}
+	if n := a.NumChunks(); n != chunks {
+		t.Errorf("NumChunks after repeated warm starts = %d, want %d", n, chunks)
+	}
+}
+
+func TestWarmStartResetPrepare(t *testing.T) {
+	a := NewArena(1024, WithWarmStart(2))
+	defer a.Release()
+	a.AllocBytes(100)
+	a.ResetPrepare()
+	a.ResetCommit()
+	if n := a.SuggestedEnsure(); n != 100 {
+		t.Errorf("SuggestedEnsure after ResetPrepare = %d, want 100", n)
+	}
+	if n := NewArena(1024).SuggestedEnsure(); n != 0 {
+		t.Errorf("SuggestedEnsure without WithWarmStart = %d, want 0", n)
+	}
+}
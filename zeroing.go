@@ -0,0 +1,109 @@
+package arena
+
+import "sync"
+
+// WithBackgroundZeroing makes Reset hand the chunks used in the finished
+// cycle to a background goroutine that clears them, instead of rewinding
+// them in place. Allocations in the next cycle are served from chunks that
+// were never used or have already been cleared; an allocation that needs a
+// new chunk while clearing is in flight waits only until the next chunk is
+// cleared, not for the whole set. Alloc and AllocSliceZeroed skip their
+// synchronous clear on such memory.
+//
+// Clearing is moved off the allocating goroutine, not avoided: the gain is
+// largest when cycles leave idle time (e.g. between requests) for the
+// background goroutine to catch up.
+func WithBackgroundZeroing() Option {
+	return func(a *Arena) {
+		a.zeroer = &zeroer{}
+		a.zeroer.cond.L = &a.zeroer.mu
+	}
+}
+
+// zeroer clears retired chunks off the allocating goroutine and hands them
+// back for reuse as spare chunks.
+type zeroer struct {
+	mu       sync.Mutex
+	cond     sync.Cond
+	ready    []chunk // cleared chunks not yet collected by the arena
+	pending  int     // chunks still being cleared
+	released bool    // the arena was released; drop cleared chunks
+	wg       sync.WaitGroup
+}
+
+// retireForZeroing is the Reset path under WithBackgroundZeroing: chunks
+// holding data are passed to the zeroer and the arena continues with the
+// untouched ones, growing a fresh chunk if all were retired.
+func (a *Arena) retireForZeroing() {
+	var dirty []chunk
+	kept := a.chunks[:0]
+	for _, c := range a.chunks {
+		if c.offset > a.chunkBase {
+			c.lastUsed = a.generation
+			dirty = append(dirty, c)
+		} else {
+			kept = append(kept, c)
+		}
+	}
+	clear(a.chunks[len(kept):])
+	a.chunks = kept
+	if len(dirty) > 0 {
+		a.zeroer.clear(dirty, a.chunkBase)
+	}
+	if len(a.chunks) == 0 && len(dirty) > 0 {
+		a.grow(max(a.chunkSize, len(a.template)))
+	}
+}
+
+// clear zeroes the used part of each chunk on a new goroutine, publishing
+// every chunk as soon as it is done so the arena can reuse it early.
+func (z *zeroer) clear(chunks []chunk, base uintptr) {
+	z.mu.Lock()
+	z.pending += len(chunks)
+	z.mu.Unlock()
+	z.wg.Add(1)
+	go func() {
+		defer z.wg.Done()
+		for _, c := range chunks {
+			clear(c.buf[base:c.offset])
+			c.offset = base
+			c.zeroed = true
+			z.mu.Lock()
+			z.pending--
+			if !z.released {
+				z.ready = append(z.ready, c)
+			}
+			z.cond.Broadcast()
+			z.mu.Unlock()
+		}
+	}()
+}
+
+// collect appends the chunks cleared so far to spare. If none are ready
+// and wait is set, it first blocks until the next chunk is cleared; it
+// reports false once nothing is left in flight.
+func (z *zeroer) collect(spare []chunk, wait bool) ([]chunk, bool) {
+	z.mu.Lock()
+	for wait && len(z.ready) == 0 && z.pending > 0 {
+		z.cond.Wait()
+	}
+	more := z.pending > 0
+	spare = append(spare, z.ready...)
+	clear(z.ready)
+	z.ready = z.ready[:0]
+	z.mu.Unlock()
+	return spare, more
+}
+
+// release drops cleared chunks, including those still being cleared.
+func (z *zeroer) release() {
+	z.mu.Lock()
+	z.ready = nil
+	z.released = true
+	z.mu.Unlock()
+}
+
+// wait blocks until all in-flight clearing has finished.
+func (z *zeroer) wait() {
+	z.wg.Wait()
+}
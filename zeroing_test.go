@@ -0,0 +1,112 @@
+package arena
+
+import "testing"
+
+func TestBackgroundZeroing(t *testing.T) {
+	a := NewArena(1024, WithBackgroundZeroing())
+
+	for cycle := 0; cycle < 5; cycle++ {
+		s := AllocSliceZeroed[byte](a, 900)
+		for i, v := range s {
+			if v != 0 {
+				t.Fatalf("cycle %d: s[%d] = %d, want 0", cycle, i, v)
+			}
+		}
+		for i := range s {
+			s[i] = 0xFF
+		}
+		p := Alloc[[64]byte](a) // forces a second chunk
+		if *p != ([64]byte{}) {
+			t.Fatalf("cycle %d: Alloc returned dirty memory", cycle)
+		}
+		for i := range p {
+			p[i] = 0xFF
+		}
+		a.Reset()
+		if cycle%2 == 0 {
+			a.zeroer.wait()
+		}
+	}
+
+	a.zeroer.wait()
+	a.Reset()
+	a.zeroer.wait()
+	// Cleared chunks are recycled instead of piling up.
+	if n := len(a.chunks) + len(a.spare) + len(a.zeroer.ready); n > 6 {
+		t.Errorf("chunks held = %d, want <= 6", n)
+	}
+}
+
+func TestBackgroundZeroingKeepsUnusedChunks(t *testing.T) {
+	a := NewArena(1024, WithBackgroundZeroing())
+	first := &a.chunks[0].buf[0]
+	a.Reset() // nothing allocated: the chunk stays in place
+	if &a.chunks[0].buf[0] != first {
+		t.Error("Reset() retired an unused chunk")
+	}
+
+	b := a.AllocBytes(10)
+	b[0] = 0xFF
+	a.Reset()
+	// The only chunk was retired, so the arena waited for it to be cleared.
+	if !a.currentChunk.zeroed {
+		t.Error("current chunk not zeroed after Reset()")
+	}
+	if a.SizeInUse() != 0 {
+		t.Errorf("SizeInUse after Reset() = %d, want 0", a.SizeInUse())
+	}
+	if b := a.AllocBytes(10); b[0] != 0 {
+		t.Errorf("AllocBytes after Reset() = %d, want 0", b[0])
+	}
+	a.Release()
+	a.zeroer.wait()
+	if a.zeroer.ready != nil {
+		t.Error("cleared chunks kept after Release()")
+	}
+}
+
+func TestZeroedChunkTracking(t *testing.T) {
+	a := NewArena(1024)
+	if !a.currentChunk.zeroed {
+		t.Fatal("fresh chunk not marked zeroed")
+	}
+	b := a.AllocBytes(16)
+	b[0] = 1
+	a.Reset()
+	if a.currentChunk.zeroed {
+		t.Fatal("reused chunk still marked zeroed")
+	}
+	if p := Alloc[int64](a); *p != 0 {
+		t.Errorf("Alloc after Reset() = %d, want 0", *p)
+	}
+
+	c := NewArena(1024)
+	buf, done := c.Scratch(16)
+	buf[0] = 1
+	done()
+	if p := Alloc[int64](c); *p != 0 {
+		t.Errorf("Alloc after Scratch release = %d, want 0", *p)
+	}
+}
+
+func BenchmarkAllocSliceZeroedBackground(b *testing.B) {
+	for _, bg := range []bool{false, true} {
+		name := "Sync"
+		var opts []Option
+		if bg {
+			name = "Background"
+			opts = append(opts, WithBackgroundZeroing())
+		}
+		b.Run(name, func(b *testing.B) {
+			a := NewArena(1<<20, opts...)
+			b.SetBytes(64 << 10)
+			for i := 0; i < b.N; i++ {
+				s := AllocSliceZeroed[byte](a, 64<<10)
+				s[0] = 1
+				if i%8 == 7 {
+					a.Reset()
+				}
+			}
+		})
+	}
+}